@@ -0,0 +1,50 @@
+// Package gormcnm provides typed aggregate constructors returning typed columns instead of strings
+// Auto spells SUM, AVG, COUNT, MAX and MIN around the column, giving SUM/AVG as float64 and COUNT as int64
+// Supports aliasing later with AsAlias, comparing in HAVING, ordering with Ob and wrapping with COALESCE
+//
+// gormcnm 提供类型化的聚合构造函数，返回类型化的列而不是字符串
+// 自动在列外生成 SUM、AVG、COUNT、MAX 和 MIN，SUM/AVG 的类型为 float64，COUNT 的类型为 int64
+// 支持之后使用 AsAlias 设置别名、在 HAVING 中比较、使用 Ob 排序以及使用 COALESCE 包装
+package gormcnm
+
+// Sum returns SUM(column) as a typed column, e.g. db.Having(Sum(columnAmount).Gt(100)).
+// Sum 返回类型化的列 SUM(column)，例如 db.Having(Sum(columnAmount).Gt(100))。
+func Sum[TYPE any](column ColumnName[TYPE]) ColumnName[float64] {
+	return scalarFunction[float64]("SUM", column.Name())
+}
+
+// Avg returns AVG(column) as a typed column.
+// Avg 返回类型化的列 AVG(column)。
+func Avg[TYPE any](column ColumnName[TYPE]) ColumnName[float64] {
+	return scalarFunction[float64]("AVG", column.Name())
+}
+
+// Count returns COUNT(column) as a typed column, NULL values are not counted.
+// Count 返回类型化的列 COUNT(column)，NULL 值不计数。
+func Count[TYPE any](column ColumnName[TYPE]) ColumnName[int64] {
+	return scalarFunction[int64]("COUNT", column.Name())
+}
+
+// CountDistinct returns COUNT(DISTINCT(column)) as a typed column.
+// CountDistinct 返回类型化的列 COUNT(DISTINCT(column))。
+func CountDistinct[TYPE any](column ColumnName[TYPE]) ColumnName[int64] {
+	return scalarFunction[int64]("COUNT", "DISTINCT("+column.Name()+")")
+}
+
+// CountAll returns COUNT(*) as a typed column.
+// CountAll 返回类型化的列 COUNT(*)。
+func CountAll() ColumnName[int64] {
+	return scalarFunction[int64]("COUNT", "*")
+}
+
+// Max returns MAX(column) as a typed column, keeping the column type.
+// Max 返回类型化的列 MAX(column)，保持列的类型。
+func Max[TYPE any](column ColumnName[TYPE]) ColumnName[TYPE] {
+	return scalarFunction[TYPE]("MAX", column.Name())
+}
+
+// Min returns MIN(column) as a typed column, keeping the column type.
+// Min 返回类型化的列 MIN(column)，保持列的类型。
+func Min[TYPE any](column ColumnName[TYPE]) ColumnName[TYPE] {
+	return scalarFunction[TYPE]("MIN", column.Name())
+}
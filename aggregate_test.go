@@ -0,0 +1,71 @@
+// Package gormcnm tests validate typed aggregate constructors
+// Auto verifies the aggregates in SELECT with aliases, HAVING comparisons and ORDER BY
+// Tests examine SQLite execution with an orders table
+//
+// gormcnm 测试包验证类型化的聚合构造函数
+// 自动验证聚合在带别名的 SELECT、HAVING 比较和 ORDER BY 中的使用
+// 测试涵盖使用 orders 表的 SQLite 执行
+package gormcnm
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/yyle88/gormcnm/internal/tests"
+	"gorm.io/gorm"
+)
+
+func TestSum(t *testing.T) {
+	type Order struct {
+		ID     uint    `gorm:"primaryKey"`
+		UserID uint    `gorm:"column:user_id;"`
+		Amount float64 `gorm:"column:amount;"`
+	}
+
+	const (
+		columnUserID = ColumnName[uint]("user_id")
+		columnAmount = ColumnName[float64]("amount")
+	)
+
+	require.Equal(t, "SUM(amount) as total", Sum(columnAmount).AsAlias("total"))
+	require.Equal(t, columnAmount.Sum("total"), Sum(columnAmount).AsAlias("total"))
+	require.Equal(t, "COUNT(DISTINCT(user_id))", columnUserID.CountDistinct(""))
+	require.Equal(t, "COUNT(*)", CountAll().Name())
+
+	tests.NewDBRun(t, func(db *gorm.DB) {
+		require.NoError(t, db.AutoMigrate(&Order{}))
+		require.NoError(t, db.Create(&[]*Order{
+			{ID: 1, UserID: 1, Amount: 60},
+			{ID: 2, UserID: 1, Amount: 70},
+			{ID: 3, UserID: 2, Amount: 30},
+			{ID: 4, UserID: 3, Amount: 200},
+		}).Error)
+
+		type Result struct {
+			UserID uint
+			Total  float64
+			Cnt    int64
+			Top    float64
+		}
+		var results []*Result
+		require.NoError(t, db.Model(&Order{}).
+			Select(columnUserID.Name(), Sum(columnAmount).AsAlias("total"), CountAll().AsAlias("cnt"), Max(columnAmount).AsAlias("top")).
+			Group(columnUserID.Name()).
+			Having(Sum(columnAmount).Gt(50)).
+			Order(Sum(columnAmount).Ob("DESC").Ox()).
+			Find(&results).Error)
+		require.Len(t, results, 2)
+		require.Equal(t, uint(3), results[0].UserID)
+		require.Equal(t, 130.0, results[1].Total)
+		require.Equal(t, int64(2), results[1].Cnt)
+		require.Equal(t, 70.0, results[1].Top)
+
+		var minAmount float64
+		require.NoError(t, db.Model(&Order{}).Select(Min(columnAmount).Name()).Scan(&minAmount).Error)
+		require.Equal(t, 30.0, minAmount)
+
+		var avgAmount float64
+		require.NoError(t, db.Model(&Order{}).Select(Avg(columnAmount).AsAlias("avg")).Where(columnUserID.Eq(1)).Scan(&avgAmount).Error)
+		require.Equal(t, 65.0, avgAmount)
+	})
+}
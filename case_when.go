@@ -0,0 +1,141 @@
+// Package gormcnm provides a typed CASE WHEN expression builder with merged arguments
+// Auto spells "CASE WHEN (cond) THEN ? ... ELSE ? END" binding the conditions and result values in order
+// Supports SELECT, ORDER BY, UPDATE assignments and wrapping with aggregates like SUM
+//
+// gormcnm 提供类型化的 CASE WHEN 表达式构建器，自动合并参数
+// 自动生成 "CASE WHEN (cond) THEN ? ... ELSE ? END"，并按顺序绑定条件和结果值
+// 支持 SELECT、ORDER BY、UPDATE 赋值以及使用 SUM 等聚合函数包装
+package gormcnm
+
+import (
+	"strings"
+
+	"github.com/yyle88/gormcnm/internal/utils"
+	"github.com/yyle88/must"
+	"gorm.io/gorm/clause"
+)
+
+// CaseWhen represents a CASE WHEN expression whose result values are of TYPE
+// It is immutable, When and Else return new instances, thus a shared prefix can be reused safely
+//
+// CaseWhen 表示结果值为 TYPE 类型的 CASE WHEN 表达式
+// 它是不可变的，When 和 Else 返回新实例，因此可以安全地复用公共前缀
+type CaseWhen[TYPE any] struct {
+	conditions []*QxConjunction // WHEN conditions // WHEN 条件
+	results    []TYPE           // THEN values matching the conditions // 与条件对应的 THEN 值
+	elseValue  *TYPE            // ELSE value, nil means no ELSE (NULL) // ELSE 值，nil 表示没有 ELSE（即 NULL）
+}
+
+// Case creates an empty CaseWhen, add branches with When
+// Case 创建一个空的 CaseWhen，使用 When 添加分支
+func Case[TYPE any]() *CaseWhen[TYPE] {
+	return &CaseWhen[TYPE]{}
+}
+
+// When returns a new CaseWhen with the branch "WHEN (qx) THEN value" appended
+// A nil or empty qx matches all the rows, written as "WHEN (1=1)" the same as QxConjunction.Build
+//
+// When 返回追加了 "WHEN (qx) THEN value" 分支的新 CaseWhen
+// nil 或空的 qx 匹配所有行，与 QxConjunction.Build 相同写为 "WHEN (1=1)"
+func (cw *CaseWhen[TYPE]) When(qx *QxConjunction, value TYPE) *CaseWhen[TYPE] {
+	return &CaseWhen[TYPE]{
+		conditions: append(cw.conditions[:len(cw.conditions):len(cw.conditions)], qx),
+		results:    append(cw.results[:len(cw.results):len(cw.results)], value),
+		elseValue:  cw.elseValue,
+	}
+}
+
+// Else returns a new CaseWhen with the ELSE value
+// Else 返回设置了 ELSE 值的新 CaseWhen
+func (cw *CaseWhen[TYPE]) Else(value TYPE) *CaseWhen[TYPE] {
+	return &CaseWhen[TYPE]{
+		conditions: cw.conditions,
+		results:    cw.results,
+		elseValue:  &value,
+	}
+}
+
+// Stmt returns the CASE WHEN statement and the merged arguments, with the columns left unquoted
+// Named arguments of the conditions are kept as sql.NamedArg after the positional ones, colliding names are renamed
+//
+// Stmt 返回 CASE WHEN 语句和合并后的参数，列名不加引号
+// 条件中的命名参数以 sql.NamedArg 形式排在位置参数之后，冲突的名称会被重命名
+func (cw *CaseWhen[TYPE]) Stmt() (string, []interface{}) {
+	return cw.render("")
+}
+
+// render writes the CASE WHEN statement in the dialect, merging the arguments of all the branches in order
+// render 按方言写入 CASE WHEN 语句，并按顺序合并所有分支的参数
+func (cw *CaseWhen[TYPE]) render(dialect Dialect) (string, []interface{}) {
+	must.Have(cw.conditions)
+	var sb strings.Builder
+	var merger = newArgumentsMerger()
+	sb.WriteString("CASE")
+	for idx, qx := range cw.conditions {
+		if qx.IsEmpty() {
+			sb.WriteString(" WHEN (1=1)")
+		} else {
+			sb.WriteString(" WHEN (")
+			qx.Node().render(&sb, merger, dialect)
+			sb.WriteString(")")
+		}
+		sb.WriteString(" THEN " + merger.merge("?", []interface{}{cw.results[idx]}))
+	}
+	if cw.elseValue != nil {
+		sb.WriteString(" ELSE " + merger.merge("?", []interface{}{*cw.elseValue}))
+	}
+	sb.WriteString(" END")
+	return sb.String(), merger.args()
+}
+
+// expression returns the CASE WHEN expression rendered in the dialect of the DB when GORM builds it
+// expression 返回在 GORM 构建时按数据库方言渲染的 CASE WHEN 表达式
+func (cw *CaseWhen[TYPE]) expression() *DialectExpression {
+	return NewDialectExpression(cw.render)
+}
+
+// Expr returns the CASE WHEN expression as clause.Expr, usable in UPDATE assignments, e.g. KeExp(cw.Expr())
+// Expr 返回 clause.Expr 形式的 CASE WHEN 表达式，可用于 UPDATE 赋值，例如 KeExp(cw.Expr())
+func (cw *CaseWhen[TYPE]) Expr() clause.Expr {
+	return clause.Expr{SQL: "?", Vars: []interface{}{cw.expression()}}
+}
+
+// Build implements clause.Expression, writing the CASE WHEN expression with its arguments bound
+// Build 实现 clause.Expression 接口，写入 CASE WHEN 表达式并绑定其参数
+func (cw *CaseWhen[TYPE]) Build(builder clause.Builder) {
+	cw.expression().Build(builder)
+}
+
+// Sx returns a SelectStatement selecting the CASE WHEN expression with the alias
+// Sx 返回以别名选择 CASE WHEN 表达式的 SelectStatement
+func (cw *CaseWhen[TYPE]) Sx(alias string) *SelectStatement {
+	return NewSelectStatement(utils.ApplyAliasToColumn("?", alias), cw.expression())
+}
+
+// Sum returns a SelectStatement selecting SUM of the CASE WHEN expression with the alias
+// Sum 返回以别名选择 CASE WHEN 表达式 SUM 值的 SelectStatement
+func (cw *CaseWhen[TYPE]) Sum(alias string) *SelectStatement {
+	return cw.Aggregate("SUM", alias)
+}
+
+// Count returns a SelectStatement selecting COUNT of the CASE WHEN expression with the alias, NULL results are not counted
+// Count 返回以别名选择 CASE WHEN 表达式 COUNT 值的 SelectStatement，NULL 结果不计数
+func (cw *CaseWhen[TYPE]) Count(alias string) *SelectStatement {
+	return cw.Aggregate("COUNT", alias)
+}
+
+// Aggregate returns a SelectStatement selecting the aggregate function (e.g., "MAX") of the CASE WHEN expression with the alias
+// Aggregate 返回以别名选择 CASE WHEN 表达式聚合函数（例如 "MAX"）值的 SelectStatement
+func (cw *CaseWhen[TYPE]) Aggregate(function string, alias string) *SelectStatement {
+	return NewSelectStatement(utils.ApplyAliasToColumn(function+"(?)", alias), cw.expression())
+}
+
+// OrderBy returns the clause.OrderBy sorting by the CASE WHEN expression, usable with db.Order
+// OrderBy 返回按 CASE WHEN 表达式排序的 clause.OrderBy，可用于 db.Order
+func (cw *CaseWhen[TYPE]) OrderBy(desc bool) clause.OrderBy {
+	var stmt = "?"
+	if desc {
+		stmt += " DESC"
+	}
+	return clause.OrderBy{Expression: clause.Expr{SQL: stmt, Vars: []interface{}{cw.expression()}, WithoutParentheses: true}}
+}
@@ -0,0 +1,132 @@
+// Package gormcnm tests validate the typed CASE WHEN expression builder
+// Auto verifies the expression in SELECT, ORDER BY, UPDATE assignments and SUM aggregates
+// Tests examine SQLite execution with an orders table
+//
+// gormcnm 测试包验证类型化的 CASE WHEN 表达式构建器
+// 自动验证表达式在 SELECT、ORDER BY、UPDATE 赋值和 SUM 聚合中的使用
+// 测试涵盖使用 orders 表的 SQLite 执行
+package gormcnm
+
+import (
+	"database/sql"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/yyle88/gormcnm/internal/tests"
+	"gorm.io/gorm"
+)
+
+func TestCase(t *testing.T) {
+	type Order struct {
+		ID     uint    `gorm:"primaryKey"`
+		Status string  `gorm:"column:status;"`
+		Amount float64 `gorm:"column:amount;"`
+		Level  string  `gorm:"column:level;"`
+	}
+
+	const (
+		columnID     = ColumnName[uint]("id")
+		columnStatus = ColumnName[string]("status")
+		columnAmount = ColumnName[float64]("amount")
+		columnLevel  = ColumnName[string]("level")
+	)
+
+	levelCase := Case[string]().
+		When(Qx(columnAmount.Gte(100)), "high").
+		When(Qx(columnAmount.Gte(10)), "mid").
+		Else("low")
+
+	stmt, args := levelCase.Stmt()
+	require.Equal(t, "CASE WHEN (amount>=?) THEN ? WHEN (amount>=?) THEN ? ELSE ? END", stmt)
+	require.Equal(t, []interface{}{100.0, "high", 10.0, "mid", "low"}, args)
+
+	// The empty condition matches all the rows, e.g. an optional filter left unset
+	// 空条件匹配所有行，例如未设置的可选过滤条件
+	stmt, args = Case[string]().When(columnStatus.EqIfSet(""), "any").When(nil, "all").Stmt()
+	require.Equal(t, "CASE WHEN (1=1) THEN ? WHEN (1=1) THEN ? END", stmt)
+	require.Equal(t, []interface{}{"any", "all"}, args)
+
+	// Named and positional conditions bind their own values, the named ones go through clause.NamedExpr
+	// 命名条件和位置条件各自绑定自己的值，命名参数通过 clause.NamedExpr 绑定
+	mixedCase := Case[int]().
+		When(Qx("status = @s", sql.Named("s", "refund")), 1).
+		When(Qx(columnAmount.Gt(10)), 2).
+		Else(0)
+	stmt, args = mixedCase.Stmt()
+	require.Equal(t, "CASE WHEN (status = @s) THEN ? WHEN (amount>?) THEN ? ELSE ? END", stmt)
+	require.Equal(t, []interface{}{1, 10.0, 2, 0, sql.Named("s", "refund")}, args)
+
+	t.Run("dialect", func(t *testing.T) {
+		db := tests.NewDryRunDB(t, "mysql")
+		stmt := db.Model(&Order{}).Clauses(NewSx(columnID.Name()).Combine(mixedCase.Sx("rank"))).Find(&[]*Order{}).Statement
+		require.Equal(t, "SELECT id, CASE WHEN (status = ?) THEN ? WHEN (`amount`>?) THEN ? ELSE ? END as rank FROM `orders`", stmt.SQL.String())
+		require.Equal(t, []interface{}{"refund", 1, 10.0, 2, 0}, stmt.Vars)
+	})
+
+	tests.NewDBRun(t, func(db *gorm.DB) {
+		require.NoError(t, db.AutoMigrate(&Order{}))
+		require.NoError(t, db.Create(&[]*Order{
+			{ID: 1, Status: "paid", Amount: 5},
+			{ID: 2, Status: "paid", Amount: 50},
+			{ID: 3, Status: "refund", Amount: 500},
+		}).Error)
+
+		t.Run("select", func(t *testing.T) {
+			type Result struct {
+				ID    uint
+				Level string
+			}
+			var results []*Result
+			require.NoError(t, db.Model(&Order{}).Clauses(NewSx(columnID.Name()).Combine(levelCase.Sx("level"))).Order(columnID.Name()).Find(&results).Error)
+			require.Len(t, results, 3)
+			require.Equal(t, "low", results[0].Level)
+			require.Equal(t, "mid", results[1].Level)
+			require.Equal(t, "high", results[2].Level)
+		})
+
+		t.Run("order", func(t *testing.T) {
+			priority := Case[int]().When(Qx(columnStatus.Eq("refund")), 0).Else(1)
+			var ids []uint
+			require.NoError(t, db.Model(&Order{}).Order(priority.OrderBy(false)).Order(columnID.Ob("desc").Ox()).Pluck(columnID.Name(), &ids).Error)
+			require.Equal(t, []uint{3, 2, 1}, ids)
+		})
+
+		t.Run("update", func(t *testing.T) {
+			require.NoError(t, db.Model(&Order{}).Where("1 = 1").UpdateColumns(Kw(columnLevel.KeExp(levelCase.Expr())).Map()).Error)
+			var levels []string
+			require.NoError(t, db.Model(&Order{}).Order(columnID.Name()).Pluck(columnLevel.Name(), &levels).Error)
+			require.Equal(t, []string{"low", "mid", "high"}, levels)
+		})
+
+		t.Run("named", func(t *testing.T) {
+			type Result struct {
+				ID   uint
+				Rank int
+			}
+			var results []*Result
+			require.NoError(t, db.Model(&Order{}).Clauses(NewSx(columnID.Name()).Combine(mixedCase.Sx("rank"))).Order(columnID.Name()).Find(&results).Error)
+			require.Len(t, results, 3)
+			require.Equal(t, 0, results[0].Rank)
+			require.Equal(t, 2, results[1].Rank)
+			require.Equal(t, 1, results[2].Rank)
+		})
+
+		t.Run("sum", func(t *testing.T) {
+			paid := Case[float64]().When(Qx(columnStatus.Eq("paid")), 1).Else(0)
+			type Result struct {
+				PaidCnt  float64
+				FewCnt   int64
+				MaxLevel string
+			}
+			var result Result
+			sx := paid.Sum("paid_cnt").Combine(
+				Case[int]().When(Qx(columnAmount.Lt(100)), 1).Count("few_cnt"),
+				levelCase.Aggregate("MAX", "max_level"),
+			)
+			require.NoError(t, db.Model(&Order{}).Clauses(sx).Take(&result).Error)
+			require.Equal(t, 2.0, result.PaidCnt)
+			require.Equal(t, int64(2), result.FewCnt)
+			require.Equal(t, "mid", result.MaxLevel)
+		})
+	})
+}
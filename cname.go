@@ -7,7 +7,11 @@
 // 支持列别名、原始列名和动态 SQL 生成，具有编译时类型安全特性
 package gormcnm
 
-import "github.com/yyle88/gormcnm/internal/utils"
+import (
+	"reflect"
+
+	"github.com/yyle88/gormcnm/internal/utils"
+)
 
 /*
 Defines a reusable `ColumnName` type designed to streamline and optimize SQL statement construction.
@@ -32,6 +36,7 @@ type ColumnName[TYPE any] string
 // Qs creates a SQL statement with a given op.
 // Returns the SQL fragment without param placeholders, used in raw SQL construction
 // Most often used when building complex WHERE clauses with multiple conditions
+// Wrapped in Qx, e.g. Qx(columnType.Qs("= ?"), "xyz"), the column is quoted in the dialect of the DB lazily
 //
 // With GORM:
 //
@@ -50,6 +55,7 @@ type ColumnName[TYPE any] string
 // Qs 创建一个带有指定操作符的 SQL 语句。
 // 返回不带参数占位符的 SQL 片段，用于原始 SQL 构建
 // 最常用于构建带有多个条件的复杂 WHERE 子句
+// 包装在 Qx 中时，例如 Qx(columnType.Qs("= ?"), "xyz")，列名会按数据库方言延迟加引号
 //
 // 传统写法：
 //
@@ -113,6 +119,8 @@ func (columnName ColumnName[TYPE]) Op(op string, x TYPE) (string, TYPE) {
 // Most often used method when doing equivalence comparisons with type-safe operations and clean syntax
 // Auto generates "column=?" pattern with param binding when using GORM WHERE operations
 // Core building block when constructing database queries and the foundation of type-safe SQL
+// On pointer-typed columns (ColumnName[*T]) a nil value renders "column IS NULL", the same as IsNULL()
+// Wrapped in Qx, e.g. Qx(columnType.Eq("xyz")), the column is quoted in the dialect of the DB lazily
 //
 // With GORM:
 //
@@ -133,6 +141,8 @@ func (columnName ColumnName[TYPE]) Op(op string, x TYPE) (string, TYPE) {
 // 最常用的相等比较方法，具有类型安全和简洁语法
 // 自动生成 "column=?" 模式并为 GORM WHERE 操作绑定参数
 // 所有数据库查询的基础构建块，类型安全 SQL 的基石
+// 在指针类型的列（ColumnName[*T]）上传入 nil 时生成 "column IS NULL"，与 IsNULL() 相同
+// 包装在 Qx 中时，例如 Qx(columnType.Eq("xyz"))，列名会按数据库方言延迟加引号
 //
 // 传统写法：
 //
@@ -149,6 +159,11 @@ func (columnName ColumnName[TYPE]) Op(op string, x TYPE) (string, TYPE) {
 // 都生成："WHERE name = ? AND rank = ?"
 // 优势：类型安全、无拼写错误、IDE 自动补全、重构支持
 func (columnName ColumnName[TYPE]) Eq(x TYPE) (string, TYPE) {
+	if isNilPointer(x) {
+		// The nil argument has no placeholder, GORM leaves it unbound and NewQxConjunction drops it
+		// nil 参数没有对应的占位符，GORM 不会绑定它，NewQxConjunction 会将其丢弃
+		return columnName.IsNULL(), x
+	}
 	return string(columnName) + "=?", x
 }
 
@@ -238,24 +253,32 @@ func (columnName ColumnName[TYPE]) IsNotNull() string {
 
 // IsTRUE creates a SQL statement to check if the column's value is TRUE.
 // IsTRUE 创建一个 SQL 语句来判断列的值是否为 TRUE。
+// Wrapped in Qx it is spelled in the dialect of the DB lazily, e.g. "[column] = 1" on SQL Server.
+// 包装在 Qx 中时会按数据库方言延迟生成，例如 SQL Server 中为 "[column] = 1"。
 func (columnName ColumnName[TYPE]) IsTRUE() string {
 	return string(columnName) + " IS TRUE"
 }
 
 // IsTrue creates a SQL statement to check if the column's value is TRUE.
 // IsTrue 创建一个 SQL 语句来判断列的值是否为 TRUE。
+// Wrapped in Qx it is spelled in the dialect of the DB lazily, e.g. "[column] = 1" on SQL Server.
+// 包装在 Qx 中时会按数据库方言延迟生成，例如 SQL Server 中为 "[column] = 1"。
 func (columnName ColumnName[TYPE]) IsTrue() string {
 	return string(columnName) + " IS TRUE"
 }
 
 // IsFALSE creates a SQL statement to check if the column's value is FALSE.
 // IsFALSE 创建一个 SQL 语句来判断列的值是否为 FALSE。
+// Wrapped in Qx it is spelled in the dialect of the DB lazily, e.g. "[column] = 0" on SQL Server.
+// 包装在 Qx 中时会按数据库方言延迟生成，例如 SQL Server 中为 "[column] = 0"。
 func (columnName ColumnName[TYPE]) IsFALSE() string {
 	return string(columnName) + " IS FALSE"
 }
 
 // IsFalse creates a SQL statement to check if the column's value is FALSE.
 // IsFalse 创建一个 SQL 语句来判断列的值是否为 FALSE。
+// Wrapped in Qx it is spelled in the dialect of the DB lazily, e.g. "[column] = 0" on SQL Server.
+// 包装在 Qx 中时会按数据库方言延迟生成，例如 SQL Server 中为 "[column] = 0"。
 func (columnName ColumnName[TYPE]) IsFalse() string {
 	return string(columnName) + " IS FALSE"
 }
@@ -329,3 +352,10 @@ func (columnName ColumnName[TYPE]) AsAlias(alias string) string {
 func (columnName ColumnName[TYPE]) AsName(alias ColumnName[TYPE]) string {
 	return utils.ApplyAliasToColumn(columnName.Name(), string(alias))
 }
+
+// isNilPointer checks whether the value is a nil pointer, the nil interface is not a pointer thus gives false
+// isNilPointer 判断值是否为 nil 指针，nil 接口不是指针因此返回 false
+func isNilPointer(x interface{}) bool {
+	value := reflect.ValueOf(x)
+	return value.Kind() == reflect.Ptr && value.IsNil()
+}
@@ -0,0 +1,236 @@
+// Package gormcnm provides constraint-restricted column wrappers offering operations only where they make sense
+// Auto limits arithmetic to numeric columns, ordering comparisons to ordered columns and pattern matching to string columns
+// Supports compile-time errors on nonsense combinations, such as ExprMul on a string column or Like on a number column
+//
+// gormcnm 提供受约束的列包装类型，仅在有意义时提供对应操作
+// 自动将算术运算限制在数值列，将大小比较限制在有序列，将模式匹配限制在字符串列
+// 支持在编译期报告无意义的组合，例如在字符串列上使用 ExprMul 或在数值列上使用 Like
+package gormcnm
+
+import (
+	"cmp"
+
+	"gorm.io/gorm/clause"
+)
+
+// Numeric is the constraint of the integer and float types
+// Numeric 是整数和浮点数类型的约束
+type Numeric interface {
+	~int | ~int8 | ~int16 | ~int32 | ~int64 |
+		~uint | ~uint8 | ~uint16 | ~uint32 | ~uint64 | ~uintptr |
+		~float32 | ~float64
+}
+
+// Textual is the constraint of the string types
+// Textual 是字符串类型的约束
+type Textual interface {
+	~string
+}
+
+// OrderedColumn wraps a column of cmp.Ordered TYPE, offering ordering comparisons, MAX/MIN and ORDER BY
+// Use Column() to reach the complete ColumnName operations
+// The wrappers are opt-in: ColumnName keeps its unconstrained arithmetic (e.g. ExprMul on a string column compiles)
+// for compatibility, only the columns wrapped with NewOrderedColumn, NewNumericColumn and NewStringColumn are checked
+//
+// OrderedColumn 包装 cmp.Ordered 类型的列，提供大小比较、MAX/MIN 和 ORDER BY
+// 使用 Column() 获取完整的 ColumnName 操作
+// 包装类型需要主动使用：为了兼容性 ColumnName 保留不受约束的算术运算（例如在字符串列上使用 ExprMul 也能编译），
+// 只有通过 NewOrderedColumn、NewNumericColumn 和 NewStringColumn 包装的列才会被检查
+type OrderedColumn[TYPE cmp.Ordered] struct {
+	column ColumnName[TYPE] // The wrapped column // 被包装的列
+}
+
+// NewOrderedColumn wraps the column as an OrderedColumn
+// NewOrderedColumn 将列包装为 OrderedColumn
+func NewOrderedColumn[TYPE cmp.Ordered](column ColumnName[TYPE]) OrderedColumn[TYPE] {
+	return OrderedColumn[TYPE]{column: column}
+}
+
+// Column returns the wrapped ColumnName
+// Column 返回被包装的 ColumnName
+func (oc OrderedColumn[TYPE]) Column() ColumnName[TYPE] {
+	return oc.column
+}
+
+// Name returns the column name
+// Name 返回列名
+func (oc OrderedColumn[TYPE]) Name() string {
+	return oc.column.Name()
+}
+
+// Gt creates a SQL statement to check if the column is more than a given value.
+// Gt 创建一个 SQL 语句来判断列是否大于给定的值。
+func (oc OrderedColumn[TYPE]) Gt(x TYPE) (string, TYPE) {
+	return oc.column.Gt(x)
+}
+
+// Gte creates a SQL statement to check if the column is at least a given value.
+// Gte 创建一个 SQL 语句来判断列是否大于等于给定的值。
+func (oc OrderedColumn[TYPE]) Gte(x TYPE) (string, TYPE) {
+	return oc.column.Gte(x)
+}
+
+// Lt creates a SQL statement to check if the column is less than a given value.
+// Lt 创建一个 SQL 语句来判断列是否小于给定的值。
+func (oc OrderedColumn[TYPE]) Lt(x TYPE) (string, TYPE) {
+	return oc.column.Lt(x)
+}
+
+// Lte creates a SQL statement to check if the column is at most a given value.
+// Lte 创建一个 SQL 语句来判断列是否小于等于给定的值。
+func (oc OrderedColumn[TYPE]) Lte(x TYPE) (string, TYPE) {
+	return oc.column.Lte(x)
+}
+
+// Between creates a SQL statement to check if the column is between two values.
+// Between 创建一个 SQL 语句来判断列是否在两个值之间。
+func (oc OrderedColumn[TYPE]) Between(arg1, arg2 TYPE) (string, TYPE, TYPE) {
+	return oc.column.Between(arg1, arg2)
+}
+
+// NotBetween creates a SQL statement to check if the column is not between two values.
+// NotBetween 创建一个 SQL 语句来判断列是否不在两个值之间。
+func (oc OrderedColumn[TYPE]) NotBetween(arg1, arg2 TYPE) (string, TYPE, TYPE) {
+	return oc.column.NotBetween(arg1, arg2)
+}
+
+// Max creates a MAX aggregate statement on the column.
+// Max 创建列的 MAX 聚合语句。
+func (oc OrderedColumn[TYPE]) Max(alias string) string {
+	return oc.column.Max(alias)
+}
+
+// Min creates a MIN aggregate statement on the column.
+// Min 创建列的 MIN 聚合语句。
+func (oc OrderedColumn[TYPE]) Min(alias string) string {
+	return oc.column.Min(alias)
+}
+
+// Ob creates an OrderByBottle with the direction (e.g., "ASC", "DESC").
+// Ob 使用排序方向（例如 "ASC"、"DESC"）创建 OrderByBottle。
+func (oc OrderedColumn[TYPE]) Ob(direction string) OrderByBottle {
+	return oc.column.Ob(direction)
+}
+
+// NumericColumn wraps a column of Numeric TYPE, offering arithmetic, SUM/AVG and the ordered operations
+// NumericColumn 包装 Numeric 类型的列，提供算术运算、SUM/AVG 以及有序列的操作
+type NumericColumn[TYPE Numeric] struct {
+	OrderedColumn[TYPE]
+}
+
+// NewNumericColumn wraps the column as a NumericColumn
+// NewNumericColumn 将列包装为 NumericColumn
+func NewNumericColumn[TYPE Numeric](column ColumnName[TYPE]) NumericColumn[TYPE] {
+	return NumericColumn[TYPE]{OrderedColumn: NewOrderedColumn(column)}
+}
+
+// ExprAdd creates a GORM expression to add a value to the column.
+// ExprAdd 创建一个 GORM 表达式，将一个值加到列中。
+func (nc NumericColumn[TYPE]) ExprAdd(v TYPE) clause.Expr {
+	return nc.column.ExprAdd(v)
+}
+
+// ExprSub creates a GORM expression to subtract a value from the column.
+// ExprSub 创建一个 GORM 表达式，从列中减去一个值。
+func (nc NumericColumn[TYPE]) ExprSub(v TYPE) clause.Expr {
+	return nc.column.ExprSub(v)
+}
+
+// ExprMul creates a GORM expression to multiply the column by a value.
+// ExprMul 创建一个 GORM 表达式，将列乘以一个值。
+func (nc NumericColumn[TYPE]) ExprMul(v TYPE) clause.Expr {
+	return nc.column.ExprMul(v)
+}
+
+// ExprDiv creates a GORM expression to divide the column by a value.
+// ExprDiv 创建一个 GORM 表达式，将列除以一个值。
+func (nc NumericColumn[TYPE]) ExprDiv(v TYPE) clause.Expr {
+	return nc.column.ExprDiv(v)
+}
+
+// KeAdd is used in updates where a value is added to the column.
+// KeAdd 用于更新时将一个值加到列中。
+func (nc NumericColumn[TYPE]) KeAdd(x TYPE) (string, clause.Expr) {
+	return nc.column.KeAdd(x)
+}
+
+// KeSub is used in updates where a value is subtracted from the column.
+// KeSub 用于更新时从列中减去一个值。
+func (nc NumericColumn[TYPE]) KeSub(x TYPE) (string, clause.Expr) {
+	return nc.column.KeSub(x)
+}
+
+// KeMul is used in updates where the column is multiplied by a value.
+// KeMul 用于更新时将列乘以一个值。
+func (nc NumericColumn[TYPE]) KeMul(x TYPE) (string, clause.Expr) {
+	return nc.column.KeMul(x)
+}
+
+// KeDiv is used in updates where the column is divided by a value.
+// KeDiv 用于更新时将列除以一个值。
+func (nc NumericColumn[TYPE]) KeDiv(x TYPE) (string, clause.Expr) {
+	return nc.column.KeDiv(x)
+}
+
+// Sum creates a SUM aggregate statement on the column.
+// Sum 创建列的 SUM 聚合语句。
+func (nc NumericColumn[TYPE]) Sum(alias string) string {
+	return nc.column.Sum(alias)
+}
+
+// Avg creates an AVG aggregate statement on the column.
+// Avg 创建列的 AVG 聚合语句。
+func (nc NumericColumn[TYPE]) Avg(alias string) string {
+	return nc.column.Avg(alias)
+}
+
+// StringColumn wraps a column of Textual TYPE, offering pattern matching, text updates and the ordered operations
+// The escaped pattern conditions (Contains, HasPrefix, HasSuffix and the others) are declared in cname_pattern.go
+//
+// StringColumn 包装 Textual 类型的列，提供模式匹配、文本更新以及有序列的操作
+// 转义的模式条件（Contains、HasPrefix、HasSuffix 等）声明在 cname_pattern.go 中
+type StringColumn[TYPE Textual] struct {
+	OrderedColumn[TYPE]
+}
+
+// NewStringColumn wraps the column as a StringColumn
+// NewStringColumn 将列包装为 StringColumn
+func NewStringColumn[TYPE Textual](column ColumnName[TYPE]) StringColumn[TYPE] {
+	return StringColumn[TYPE]{OrderedColumn: NewOrderedColumn(column)}
+}
+
+// Like creates a SQL statement to check if the column matches a given pattern.
+// Like 创建一个 SQL 语句来判断列是否匹配给定的模式。
+func (sc StringColumn[TYPE]) Like(x TYPE) (string, TYPE) {
+	return sc.column.Like(x)
+}
+
+// NotLike creates a SQL statement to check if the column does not match a given pattern.
+// NotLike 创建一个 SQL 语句来判断列是否不匹配给定的模式。
+func (sc StringColumn[TYPE]) NotLike(x TYPE) (string, TYPE) {
+	return sc.column.NotLike(x)
+}
+
+// ExprConcat creates a GORM expression to concatenate a string to the column.
+// ExprConcat 创建一个 GORM 表达式，将字符串连接到列。
+func (sc StringColumn[TYPE]) ExprConcat(v TYPE) clause.Expr {
+	return sc.column.ExprConcat(v)
+}
+
+// ExprReplace creates a GORM expression to replace a substring in the column.
+// ExprReplace 创建一个 GORM 表达式，替换列中的子字符串。
+func (sc StringColumn[TYPE]) ExprReplace(oldValue, newValue TYPE) clause.Expr {
+	return sc.column.ExprReplace(oldValue, newValue)
+}
+
+// KeConcat is used in updates where a string is concatenated to the column.
+// KeConcat 用于更新时将字符串连接到列。
+func (sc StringColumn[TYPE]) KeConcat(x TYPE) (string, clause.Expr) {
+	return sc.column.KeConcat(x)
+}
+
+// KeReplace is used in updates where a substring in the column is replaced.
+// KeReplace 用于更新时替换列中的子字符串。
+func (sc StringColumn[TYPE]) KeReplace(oldValue, newValue TYPE) (string, clause.Expr) {
+	return sc.column.KeReplace(oldValue, newValue)
+}
@@ -0,0 +1,136 @@
+// Package gormcnm tests validate constraint-restricted column wrappers
+// Auto verifies numeric arithmetic, ordered comparisons and string pattern matching through the wrappers
+// Tests examine SQLite execution with a products table
+//
+// gormcnm 测试包验证受约束的列包装类型
+// 自动验证通过包装类型进行的数值运算、大小比较和字符串模式匹配
+// 测试涵盖使用 products 表的 SQLite 执行
+package gormcnm
+
+import (
+	"go/ast"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/yyle88/gormcnm/internal/tests"
+	"gorm.io/gorm"
+)
+
+func TestNumericColumn(t *testing.T) {
+	type Product struct {
+		Name  string  `gorm:"primary_key;type:varchar(100);"`
+		Price float64 `gorm:"column:price;"`
+		Stock int     `gorm:"column:stock;"`
+	}
+
+	var (
+		columnName  = NewStringColumn(ColumnName[string]("name"))
+		columnPrice = NewNumericColumn(ColumnName[float64]("price"))
+		columnStock = NewNumericColumn(ColumnName[int]("stock"))
+	)
+
+	tests.NewDBRun(t, func(db *gorm.DB) {
+		require.NoError(t, db.AutoMigrate(&Product{}))
+		require.NoError(t, db.Save(&Product{Name: "apple", Price: 1.5, Stock: 10}).Error)
+		require.NoError(t, db.Save(&Product{Name: "banana", Price: 2.5, Stock: 20}).Error)
+
+		require.NoError(t, db.Model(&Product{}).Where(columnName.Column().Eq("apple")).UpdateColumns(Kw(columnStock.KeAdd(5)).Kw(columnPrice.KeMul(2)).Map()).Error)
+
+		var one Product
+		require.NoError(t, db.Where(columnStock.Gt(12)).Where(columnPrice.Between(2, 4)).Order(columnStock.Ob("asc").Ox()).First(&one).Error)
+		require.Equal(t, "apple", one.Name)
+		require.Equal(t, 15, one.Stock)
+		require.Equal(t, 3.0, one.Price)
+
+		var names []string
+		require.NoError(t, db.Model(&Product{}).Where(columnName.HasPrefix("ban")).Pluck(columnName.Name(), &names).Error)
+		require.Equal(t, []string{"banana"}, names)
+
+		var total float64
+		require.NoError(t, db.Model(&Product{}).Select(columnPrice.Sum("total")).Scan(&total).Error)
+		require.Equal(t, 5.5, total)
+	})
+}
+
+func TestOrderedColumn(t *testing.T) {
+	type Event struct {
+		Code string `gorm:"primary_key;type:varchar(100);"`
+		Rank int    `gorm:"column:rank;"`
+	}
+
+	var columnCode = NewOrderedColumn(ColumnName[string]("code"))
+
+	tests.NewDBRun(t, func(db *gorm.DB) {
+		require.NoError(t, db.AutoMigrate(&Event{}))
+		require.NoError(t, db.Save(&Event{Code: "a1", Rank: 1}).Error)
+		require.NoError(t, db.Save(&Event{Code: "b2", Rank: 2}).Error)
+
+		var codes []string
+		require.NoError(t, db.Model(&Event{}).Where(columnCode.Gte("b")).Pluck(columnCode.Name(), &codes).Error)
+		require.Equal(t, []string{"b2"}, codes)
+
+		var maxCode string
+		require.NoError(t, db.Model(&Event{}).Select(columnCode.Max("")).Scan(&maxCode).Error)
+		require.Equal(t, "b2", maxCode)
+	})
+}
+
+func TestConstrainedColumn_TypeCheck(t *testing.T) {
+	if testing.Short() {
+		t.Skip("type-checks the package from source")
+	}
+	// The wrappers reject nonsense combinations at compile time, the plain ColumnName accepts them for compatibility
+	// 包装类型在编译期拒绝无意义的组合，普通的 ColumnName 为了兼容性仍然接受
+	for code, expected := range map[string]string{
+		`NewNumericColumn(ColumnName[int]("stock")).ExprMul(2)`:     "",
+		`NewStringColumn(ColumnName[string]("name")).Contains("a")`: "",
+		`NewOrderedColumn(ColumnName[float64]("price")).Gt(1)`:      "",
+		`ColumnName[string]("name").ExprMul("a")`:                   "",
+		`NewNumericColumn(ColumnName[string]("name")).ExprMul("a")`: "does not satisfy",
+		`NewStringColumn(ColumnName[int]("stock")).Contains(1)`:     "does not satisfy",
+		`NewOrderedColumn(ColumnName[bool]("active")).Gt(true)`:     "does not satisfy",
+		`NewOrderedColumn(ColumnName[[]byte]("data")).Max("m")`:     "does not satisfy",
+		`NewStringColumn(ColumnName[string]("name")).ExprMul("a")`:  "undefined",
+		`NewNumericColumn(ColumnName[int]("stock")).Contains("1")`:  "undefined",
+		`ColumnName[string]("name").Contains("a")`:                  "undefined",
+	} {
+		err := typeCheckSnippet(t, code)
+		if expected == "" {
+			require.NoError(t, err, code)
+		} else {
+			require.ErrorContains(t, err, expected, code)
+		}
+	}
+}
+
+// typeCheckSnippet type-checks the statement in a file importing this package with a dot import
+// The source importer is shared, thus the package and its dependencies are type-checked once
+//
+// typeCheckSnippet 在以点导入本包的文件中对语句进行类型检查
+// 源码导入器是共享的，因此本包及其依赖只进行一次类型检查
+func typeCheckSnippet(t *testing.T, code string) error {
+	typeCheckOnce.Do(func() {
+		typeCheckFileSet = token.NewFileSet()
+		typeCheckImporter = importer.ForCompiler(typeCheckFileSet, "source", nil)
+	})
+	path, err := filepath.Abs("snippet.go")
+	require.NoError(t, err)
+	source := "package snippet\n\nimport . \"github.com/yyle88/gormcnm\"\n\nfunc _() {\n\t" + code + "\n}\n"
+	file, err := parser.ParseFile(typeCheckFileSet, path, source, 0)
+	require.NoError(t, err)
+	config := types.Config{Importer: typeCheckImporter}
+	_, err = config.Check("snippet", typeCheckFileSet, []*ast.File{file}, nil)
+	return err
+}
+
+var (
+	typeCheckOnce     sync.Once      // Creates the shared importer // 创建共享的导入器
+	typeCheckFileSet  *token.FileSet // File set of the importer // 导入器的文件集
+	typeCheckImporter types.Importer // Source importer of the snippets // 代码片段的源码导入器
+)
@@ -0,0 +1,17 @@
+// Package gormcnm provides dialect-aware spelling of the column names
+// Auto quotes column names in the given dialect, the typed conditions wrapped in Qx are quoted lazily when applied
+// Supports one set of generated columns working across SQLite, MySQL, PostgreSQL and SQL Server
+//
+// gormcnm 提供方言感知的列名写法
+// 自动按给定方言给列名加引号，包装在 Qx 中的类型化条件会在应用时延迟加引号
+// 支持同一套生成的列在 SQLite、MySQL、PostgreSQL 和 SQL Server 间通用
+package gormcnm
+
+// Quoted returns the column name quoted in the given dialect, e.g. `type` in MySQL and "type" in PostgreSQL.
+// Conditions need no Quoted: Qx(columnType.Eq("xyz")) quotes the column in the dialect of the DB it is applied to.
+//
+// Quoted 返回按给定方言加引号的列名，例如 MySQL 中为 `type`，PostgreSQL 中为 "type"。
+// 条件无需使用 Quoted：Qx(columnType.Eq("xyz")) 会按所应用的数据库方言给列名加引号。
+func (columnName ColumnName[TYPE]) Quoted(dialect Dialect) ColumnName[TYPE] {
+	return ColumnName[TYPE](dialect.Quote(string(columnName)))
+}
@@ -0,0 +1,84 @@
+// Package gormcnm tests validate dialect-aware column operations
+// Auto verifies lazily quoted conditions and boolean checks rendered per dialect
+// Tests examine SQLite execution and DryRun rendering of MySQL, PostgreSQL and SQL Server
+//
+// gormcnm 测试包验证方言感知的列操作
+// 自动验证按方言延迟渲染的加引号条件和布尔判断
+// 测试涵盖 SQLite 执行以及 MySQL、PostgreSQL 和 SQL Server 的 DryRun 渲染
+package gormcnm
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/yyle88/gormcnm/internal/tests"
+	"github.com/yyle88/neatjson/neatjsons"
+	"gorm.io/gorm"
+)
+
+func TestColumnName_Quoted(t *testing.T) {
+	const columnType = ColumnName[string]("type")
+
+	require.Equal(t, "`type`", columnType.Quoted(DialectMySQL).Name())
+	require.Equal(t, `"type"`, columnType.Quoted(DialectPostgres).Name())
+	require.Equal(t, "[type]", columnType.Quoted(DialectSQLServer).Name())
+}
+
+func TestQxConjunction_DialectQuoted(t *testing.T) {
+	type Example struct {
+		Name   string `gorm:"primary_key;type:varchar(100);"`
+		Type   string `gorm:"column:type;"`
+		Active bool   `gorm:"column:active;"`
+	}
+
+	const (
+		columnName   = ColumnName[string]("name")
+		columnType   = ColumnName[string]("type")
+		columnActive = ColumnName[bool]("active")
+	)
+
+	tests.NewDBRun(t, func(db *gorm.DB) {
+		require.NoError(t, db.AutoMigrate(&Example{}))
+		require.NoError(t, db.Save(&Example{Name: "abc", Type: "xyz", Active: true}).Error)
+		require.NoError(t, db.Save(&Example{Name: "aaa", Type: "xxx", Active: false}).Error)
+
+		var one Example
+		require.NoError(t, db.Where(Qx(columnType.Eq("xyz")).AND(Qx(columnActive.IsTRUE()))).First(&one).Error)
+		require.Equal(t, "abc", one.Name)
+
+		var res []*Example
+		require.NoError(t, db.Scopes(Qx(columnActive.IsFALSE()).Scope()).Find(&res).Error)
+		require.Len(t, res, 1)
+		require.Equal(t, "aaa", res[0].Name)
+
+		type Result struct {
+			Name string
+			Kind string
+		}
+		var results []*Result
+		require.NoError(t, db.Model(&Example{}).Clauses(NewSx(columnName.Name()).Combine(NewDialectSx(func(dialect Dialect) (string, []interface{}) {
+			return columnType.Quoted(dialect).AsAlias("kind"), nil
+		}))).Order(columnName.Ob("asc").Ox()).Find(&results).Error)
+		t.Log(neatjsons.S(results))
+		require.Len(t, results, 2)
+		require.Equal(t, "xxx", results[0].Kind)
+	})
+
+	t.Run("sqlserver", func(t *testing.T) {
+		db := tests.NewDryRunDB(t, "sqlserver")
+		stmt := db.Where(columnType.Qx("<> ?", "xyz").AND(Qx(columnActive.IsTrue()))).Find(&[]*Example{}).Statement
+		require.Equal(t, "SELECT * FROM [examples] WHERE ([type] <> ? AND [active] = 1)", stmt.SQL.String())
+	})
+	t.Run("postgres", func(t *testing.T) {
+		db := tests.NewDryRunDB(t, "postgres")
+		stmt := db.Scopes(Qx(columnType.Eq("xyz")).Scope()).Find(&[]*Example{}).Statement
+		require.Equal(t, `SELECT * FROM "examples" WHERE "type"=$1`, stmt.SQL.String())
+	})
+	t.Run("raw", func(t *testing.T) {
+		// Raw statements and already quoted columns are kept as they are
+		// 原始语句和已加引号的列名保持不变
+		db := tests.NewDryRunDB(t, "mysql")
+		stmt := db.Where(Qx("LOWER(name) = ?", "abc").AND(Qx(columnType.Quoted(DialectMySQL).Eq("xyz")))).Find(&[]*Example{}).Statement
+		require.Equal(t, "SELECT * FROM `examples` WHERE (LOWER(name) = ? AND `type`=?)", stmt.SQL.String())
+	})
+}
@@ -0,0 +1,64 @@
+// Package gormcnm provides typed scalar SQL functions wrapping a column into a new typed column
+// Auto spells LOWER, UPPER, TRIM, LENGTH, SUBSTR, ABS and ROUND around the column name
+// Supports plugging the result into every existing operator, Ob, AsAlias and the aggregate methods
+//
+// gormcnm 提供类型化的 SQL 标量函数，将列包装为新的类型化列
+// 自动在列名外生成 LOWER、UPPER、TRIM、LENGTH、SUBSTR、ABS 和 ROUND
+// 支持将结果用于所有已有的运算符、Ob、AsAlias 和聚合方法
+package gormcnm
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Lower wraps the column with LOWER(), e.g. db.Where(Lower(columnEmail).Eq("a@x.com")).
+// Qualify the column with the table (e.g. using TC/WithTable) before wrapping it, not after.
+//
+// Lower 使用 LOWER() 包装列，例如 db.Where(Lower(columnEmail).Eq("a@x.com"))。
+// 需要带表名时，先对列使用 TC/WithTable 等方法，再进行包装。
+func Lower[TYPE any](column ColumnName[TYPE]) ColumnName[string] {
+	return scalarFunction[string]("LOWER", column.Name())
+}
+
+// Upper wraps the column with UPPER().
+// Upper 使用 UPPER() 包装列。
+func Upper[TYPE any](column ColumnName[TYPE]) ColumnName[string] {
+	return scalarFunction[string]("UPPER", column.Name())
+}
+
+// Trim wraps the column with TRIM(), removing the leading and trailing spaces.
+// Trim 使用 TRIM() 包装列，去除首尾空格。
+func Trim[TYPE any](column ColumnName[TYPE]) ColumnName[string] {
+	return scalarFunction[string]("TRIM", column.Name())
+}
+
+// Length wraps the column with LENGTH(), e.g. db.Order(Length(columnName).Ob("asc").Ox()).
+// Length 使用 LENGTH() 包装列，例如 db.Order(Length(columnName).Ob("asc").Ox())。
+func Length[TYPE any](column ColumnName[TYPE]) ColumnName[int] {
+	return scalarFunction[int]("LENGTH", column.Name())
+}
+
+// Substr wraps the column with SUBSTR(column, start, length), where start counts from 1.
+// Substr 使用 SUBSTR(column, start, length) 包装列，其中 start 从 1 开始计数。
+func Substr[TYPE any](column ColumnName[TYPE], start int, length int) ColumnName[string] {
+	return scalarFunction[string]("SUBSTR", column.Name(), strconv.Itoa(start), strconv.Itoa(length))
+}
+
+// Abs wraps the numeric column with ABS(), keeping the column type.
+// Abs 使用 ABS() 包装数值列，保持列的类型。
+func Abs[TYPE Numeric](column ColumnName[TYPE]) ColumnName[TYPE] {
+	return scalarFunction[TYPE]("ABS", column.Name())
+}
+
+// Round wraps the numeric column with ROUND(column, decimals).
+// Round 使用 ROUND(column, decimals) 包装数值列。
+func Round[TYPE Numeric](column ColumnName[TYPE], decimals int) ColumnName[float64] {
+	return scalarFunction[float64]("ROUND", column.Name(), strconv.Itoa(decimals))
+}
+
+// scalarFunction spells the function call "NAME(arg1, arg2)" as a typed column
+// scalarFunction 将函数调用 "NAME(arg1, arg2)" 生成为类型化的列
+func scalarFunction[RES any](name string, arguments ...string) ColumnName[RES] {
+	return ColumnName[RES](name + "(" + strings.Join(arguments, ", ") + ")")
+}
@@ -0,0 +1,64 @@
+// Package gormcnm tests validate typed scalar SQL functions
+// Auto verifies the wrapped columns work with operators, ordering, aliases and aggregates
+// Tests examine SQLite execution with a users table
+//
+// gormcnm 测试包验证类型化的 SQL 标量函数
+// 自动验证包装后的列可用于运算符、排序、别名和聚合
+// 测试涵盖使用 users 表的 SQLite 执行
+package gormcnm
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/yyle88/gormcnm/internal/tests"
+	"gorm.io/gorm"
+)
+
+func TestLower(t *testing.T) {
+	type User struct {
+		Name    string  `gorm:"primary_key;type:varchar(100);"`
+		Email   string  `gorm:"column:email;"`
+		Balance float64 `gorm:"column:balance;"`
+	}
+
+	const (
+		columnName    = ColumnName[string]("name")
+		columnEmail   = ColumnName[string]("email")
+		columnBalance = ColumnName[float64]("balance")
+	)
+
+	stmt, _ := Lower(columnEmail).Eq("")
+	require.Equal(t, "LOWER(email)=?", stmt)
+	require.Equal(t, "SUBSTR(name, 1, 2)", Substr(columnName, 1, 2).Name())
+	require.Equal(t, "MAX(LENGTH(name)) as n", Length(columnName).Max("n"))
+
+	tests.NewDBRun(t, func(db *gorm.DB) {
+		require.NoError(t, db.AutoMigrate(&User{}))
+		require.NoError(t, db.Save(&User{Name: "Alice", Email: "Alice@X.com", Balance: -1.256}).Error)
+		require.NoError(t, db.Save(&User{Name: "Bob", Email: " bob@y.com ", Balance: 2.5}).Error)
+
+		selectName := func(stmt string, arg interface{}) string {
+			var one User
+			require.NoError(t, db.Where(stmt, arg).First(&one).Error)
+			return one.Name
+		}
+		require.Equal(t, "Alice", selectName(Lower(columnEmail).Eq("alice@x.com")))
+		require.Equal(t, "Bob", selectName(Trim(columnEmail).Eq("bob@y.com")))
+		require.Equal(t, "Alice", selectName(Upper(Substr(columnName, 1, 2)).Eq("AL")))
+		require.Equal(t, "Bob", selectName(Abs(columnBalance).Gt(2)))
+
+		var names []string
+		require.NoError(t, db.Model(&User{}).Order(Length(columnName).Ob("asc").Ox()).Pluck(columnName.Name(), &names).Error)
+		require.Equal(t, []string{"Bob", "Alice"}, names)
+
+		type Result struct {
+			Name    string
+			Rounded float64
+		}
+		var results []*Result
+		require.NoError(t, db.Model(&User{}).Select(columnName.Name(), Round(columnBalance, 2).AsAlias("rounded")).Order(columnName.Name()).Find(&results).Error)
+		require.Len(t, results, 2)
+		require.Equal(t, -1.26, results[0].Rounded)
+	})
+}
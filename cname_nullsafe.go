@@ -0,0 +1,72 @@
+// Package gormcnm provides null-safe equality operations treating NULL as a comparable value
+// Auto renders IS NOT DISTINCT FROM on PostgreSQL, IS on SQLite and <=> on MySQL lazily
+// Supports an expanded form with IS NULL checks on the dialects lacking a null-safe operator, such as SQL Server
+//
+// gormcnm 提供空值安全的相等操作，将 NULL 视为可比较的值
+// 自动延迟渲染，PostgreSQL 使用 IS NOT DISTINCT FROM，SQLite 使用 IS，MySQL 使用 <=>
+// 支持在缺少空值安全运算符的方言（例如 SQL Server）上展开为带 IS NULL 判断的形式
+package gormcnm
+
+// EqNullSafe creates a condition checking the column equals the value, where NULL equals NULL.
+// Unlike Eq, rows where both sides are NULL are matched.
+//
+// EqNullSafe 创建判断列等于给定值的条件，其中 NULL 等于 NULL。
+// 与 Eq 不同，两边都为 NULL 的行也会被匹配。
+func (columnName ColumnName[TYPE]) EqNullSafe(x TYPE) *QxConjunction {
+	return columnName.nullSafeQx(x, false)
+}
+
+// NotDistinctFrom is the standard SQL name of EqNullSafe, rendering "IS NOT DISTINCT FROM".
+// NotDistinctFrom 是 EqNullSafe 的标准 SQL 名称，渲染为 "IS NOT DISTINCT FROM"。
+func (columnName ColumnName[TYPE]) NotDistinctFrom(x TYPE) *QxConjunction {
+	return columnName.nullSafeQx(x, false)
+}
+
+// DistinctFrom creates a condition checking the column differs from the value, where NULL differs from any non-NULL value.
+// Unlike Ne, rows where the column is NULL match a non-NULL value.
+//
+// DistinctFrom 创建判断列不同于给定值的条件，其中 NULL 不同于任何非 NULL 值。
+// 与 Ne 不同，列为 NULL 的行会匹配非 NULL 的值。
+func (columnName ColumnName[TYPE]) DistinctFrom(x TYPE) *QxConjunction {
+	return columnName.nullSafeQx(x, true)
+}
+
+// nullSafeQx renders the null-safe comparison lazily in the dialect of the DB
+// nullSafeQx 按数据库方言延迟渲染空值安全的比较
+func (columnName ColumnName[TYPE]) nullSafeQx(x TYPE, distinct bool) *QxConjunction {
+	var predicate = &QxPredicate{Column: columnName.Name(), Op: "IS NOT DISTINCT FROM", Values: []interface{}{x}}
+	if distinct {
+		predicate.Op = "IS DISTINCT FROM"
+	}
+	return NewDialectQx(func(dialect Dialect) (string, []interface{}) {
+		var column = dialect.quoteColumn(columnName.Name())
+		switch dialect {
+		case DialectPostgres:
+			if distinct {
+				return column + " IS DISTINCT FROM ?", []interface{}{x}
+			}
+			return column + " IS NOT DISTINCT FROM ?", []interface{}{x}
+		case DialectSQLite:
+			if distinct {
+				return column + " IS NOT ?", []interface{}{x}
+			}
+			return column + " IS ?", []interface{}{x}
+		case DialectMySQL:
+			if distinct {
+				return "NOT (" + column + " <=> ?)", []interface{}{x}
+			}
+			return column + " <=> ?", []interface{}{x}
+		default:
+			if isNilPointer(x) {
+				if distinct {
+					return column + " IS NOT NULL", nil
+				}
+				return column + " IS NULL", nil
+			}
+			if distinct {
+				return "(" + column + " <> ? OR " + column + " IS NULL)", []interface{}{x}
+			}
+			return column + " = ?", []interface{}{x}
+		}
+	}).withPredicate(predicate)
+}
@@ -0,0 +1,104 @@
+// Package gormcnm tests validate null-safe equality operations
+// Auto verifies NULL equals NULL with EqNullSafe, and Eq with nil renders IS NULL on pointer columns
+// Tests examine SQLite execution and DryRun rendering of MySQL, PostgreSQL and SQL Server
+//
+// gormcnm 测试包验证空值安全的相等操作
+// 自动验证 EqNullSafe 中 NULL 等于 NULL，以及指针列上 Eq 传入 nil 时生成 IS NULL
+// 测试涵盖 SQLite 执行以及 MySQL、PostgreSQL 和 SQL Server 的 DryRun 渲染
+package gormcnm
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/yyle88/gormcnm/internal/tests"
+	"gorm.io/gorm"
+)
+
+func TestColumnName_EqNullSafe(t *testing.T) {
+	type Example struct {
+		Name  string  `gorm:"primary_key;type:varchar(100);"`
+		Email *string `gorm:"column:email;"`
+	}
+
+	const (
+		columnName  = ColumnName[string]("name")
+		columnEmail = ColumnName[*string]("email")
+	)
+
+	var email = "a@x.com"
+
+	tests.NewDBRun(t, func(db *gorm.DB) {
+		require.NoError(t, db.AutoMigrate(&Example{}))
+		require.NoError(t, db.Save(&Example{Name: "a", Email: &email}).Error)
+		require.NoError(t, db.Save(&Example{Name: "b", Email: nil}).Error)
+		require.NoError(t, db.Save(&Example{Name: "c", Email: new(string)}).Error)
+
+		selectNames := func(query interface{}, args ...interface{}) []string {
+			var names []string
+			require.NoError(t, db.Model(&Example{}).Where(query, args...).Order(columnName.Name()).Pluck(columnName.Name(), &names).Error)
+			return names
+		}
+
+		require.Equal(t, []string{"b"}, selectNames(columnEmail.EqNullSafe(nil)))
+		require.Equal(t, []string{"a"}, selectNames(columnEmail.NotDistinctFrom(&email)))
+		require.Equal(t, []string{"b", "c"}, selectNames(columnEmail.DistinctFrom(&email)))
+		require.Equal(t, []string{"a", "c"}, selectNames(columnEmail.DistinctFrom(nil)))
+
+		require.Equal(t, []string{"b"}, selectNames(columnEmail.Eq(nil)))
+		require.Equal(t, []string{"a"}, selectNames(columnEmail.Eq(&email)))
+		require.Equal(t, []string{"a", "c"}, selectNames(Qx(columnEmail.Eq(nil)).NOT()))
+		require.Equal(t, []string{"b"}, selectNames(Qx(columnEmail.Eq(nil)).AND(Qx(columnName.Eq("b")))))
+	})
+
+	t.Run("nil-leaf", func(t *testing.T) {
+		qx := Qx(columnEmail.Eq(nil))
+		require.Equal(t, "email IS NULL", qx.Qs())
+		require.Equal(t, &QxPredicate{Column: "email", Op: "IS NULL"}, qx.Node().Predicate)
+		require.Empty(t, qx.Node().Args)
+		require.NoError(t, qx.Validate())
+
+		// The arity stays the same, thus the existing Qx1 calls keep working
+		// 参数个数保持不变，因此已有的 Qx1 调用仍然可用
+		require.Len(t, qx.Args(), 1)
+		stmt, arg := qx.Qx1()
+		require.Equal(t, "email IS NULL", stmt)
+		require.Nil(t, arg)
+
+		tests.NewDBRun(t, func(db *gorm.DB) {
+			require.NoError(t, db.AutoMigrate(&Example{}))
+			require.NoError(t, db.Create(&Example{Name: "abc"}).Error)
+			var count int64
+			require.NoError(t, db.Model(&Example{}).Where(qx.Qx1()).Count(&count).Error)
+			require.Equal(t, int64(1), count)
+			require.NoError(t, db.Model(&Example{}).Scopes(qx.Scope()).Count(&count).Error)
+			require.Equal(t, int64(1), count)
+			require.NoError(t, db.Model(&Example{}).Where(qx.AND(Qx(columnEmail.IsNULL()))).Count(&count).Error)
+			require.Equal(t, int64(1), count)
+		})
+
+		// The nil interface is no pointer, it is compared as a value
+		// nil 接口不是指针，按值进行比较
+		stmt, value := ColumnName[interface{}]("email").Eq(nil)
+		require.Equal(t, "email=?", stmt)
+		require.Nil(t, value)
+		require.False(t, isNilPointer(nil))
+	})
+
+	t.Run("mysql", func(t *testing.T) {
+		db := tests.NewDryRunDB(t, "mysql")
+		stmt := db.Where(columnEmail.DistinctFrom(&email)).Find(&[]*Example{}).Statement
+		require.Equal(t, "SELECT * FROM `examples` WHERE (NOT (`email` <=> ?))", stmt.SQL.String())
+	})
+	t.Run("postgres", func(t *testing.T) {
+		db := tests.NewDryRunDB(t, "postgres")
+		stmt := db.Where(columnEmail.EqNullSafe(nil)).Find(&[]*Example{}).Statement
+		require.Equal(t, `SELECT * FROM "examples" WHERE ("email" IS NOT DISTINCT FROM $1)`, stmt.SQL.String())
+	})
+	t.Run("sqlserver", func(t *testing.T) {
+		db := tests.NewDryRunDB(t, "sqlserver")
+		stmt := db.Where(columnEmail.EqNullSafe(nil).OR(columnEmail.DistinctFrom(&email))).Find(&[]*Example{}).Statement
+		require.Equal(t, "SELECT * FROM [examples] WHERE (([email] IS NULL) OR (([email] <> ? OR [email] IS NULL)))", stmt.SQL.String())
+		require.Equal(t, []interface{}{&email}, stmt.Vars)
+	})
+}
@@ -0,0 +1,120 @@
+// Package gormcnm provides optional filters building conditions from optional request fields
+// Auto skips the zero values (e.g. "", 0, nil pointer, empty slice), giving the empty QxConjunction
+// Supports combining with AND/OR where the skipped filters are ignored, and Scope() adds no WHERE when nothing is set
+//
+// gormcnm 提供可选过滤条件，根据可选的请求字段构建查询条件
+// 自动跳过零值（例如 ""、0、nil 指针、空切片），返回空的 QxConjunction
+// 支持与 AND/OR 组合并忽略被跳过的条件，没有设置任何条件时 Scope() 不添加 WHERE
+package gormcnm
+
+import "reflect"
+
+// EqIfSet creates an equivalence condition, or the empty condition when the value is zero.
+// EqIfSet 创建相等条件，当值为零值时返回空条件。
+func (columnName ColumnName[TYPE]) EqIfSet(x TYPE) *QxConjunction {
+	return columnName.qxIfSet(x, columnName.Eq)
+}
+
+// NeIfSet creates a not-equal condition, or the empty condition when the value is zero.
+// NeIfSet 创建不等条件，当值为零值时返回空条件。
+func (columnName ColumnName[TYPE]) NeIfSet(x TYPE) *QxConjunction {
+	return columnName.qxIfSet(x, columnName.Ne)
+}
+
+// GtIfSet creates a greater-than condition, or the empty condition when the value is zero.
+// GtIfSet 创建大于条件，当值为零值时返回空条件。
+func (columnName ColumnName[TYPE]) GtIfSet(x TYPE) *QxConjunction {
+	return columnName.qxIfSet(x, columnName.Gt)
+}
+
+// GteIfSet creates a greater-than-or-equal condition, or the empty condition when the value is zero.
+// GteIfSet 创建大于等于条件，当值为零值时返回空条件。
+func (columnName ColumnName[TYPE]) GteIfSet(x TYPE) *QxConjunction {
+	return columnName.qxIfSet(x, columnName.Gte)
+}
+
+// LtIfSet creates a less-than condition, or the empty condition when the value is zero.
+// LtIfSet 创建小于条件，当值为零值时返回空条件。
+func (columnName ColumnName[TYPE]) LtIfSet(x TYPE) *QxConjunction {
+	return columnName.qxIfSet(x, columnName.Lt)
+}
+
+// LteIfSet creates a less-than-or-equal condition, or the empty condition when the value is zero.
+// LteIfSet 创建小于等于条件，当值为零值时返回空条件。
+func (columnName ColumnName[TYPE]) LteIfSet(x TYPE) *QxConjunction {
+	return columnName.qxIfSet(x, columnName.Lte)
+}
+
+// LikeIfSet creates a LIKE condition with the pattern, or the empty condition when the pattern is zero.
+// LikeIfSet 使用模式创建 LIKE 条件，当模式为零值时返回空条件。
+func (columnName ColumnName[TYPE]) LikeIfSet(x TYPE) *QxConjunction {
+	return columnName.qxIfSet(x, columnName.Like)
+}
+
+// ContainsIfSet creates a condition checking the column contains the value literally, or the empty condition when the value is "".
+// ContainsIfSet 创建判断列按字面包含给定值的条件，当值为 "" 时返回空条件。
+func (sc StringColumn[TYPE]) ContainsIfSet(value TYPE) *QxConjunction {
+	if value == "" {
+		return NewEmptyQx()
+	}
+	return sc.Contains(value)
+}
+
+// HasPrefixIfSet creates a condition checking the column starts with the value literally, or the empty condition when the value is "".
+// HasPrefixIfSet 创建判断列按字面以给定值开头的条件，当值为 "" 时返回空条件。
+func (sc StringColumn[TYPE]) HasPrefixIfSet(value TYPE) *QxConjunction {
+	if value == "" {
+		return NewEmptyQx()
+	}
+	return sc.HasPrefix(value)
+}
+
+// InIfNotEmpty creates an IN condition, or the empty condition when the slice is empty.
+// InIfNotEmpty 创建 IN 条件，当切片为空时返回空条件。
+func (columnName ColumnName[TYPE]) InIfNotEmpty(x []TYPE) *QxConjunction {
+	if len(x) == 0 {
+		return NewEmptyQx()
+	}
+	return Qx(columnName.In(x))
+}
+
+// NotInIfNotEmpty creates a NOT IN condition, or the empty condition when the slice is empty.
+// NotInIfNotEmpty 创建 NOT IN 条件，当切片为空时返回空条件。
+func (columnName ColumnName[TYPE]) NotInIfNotEmpty(x []TYPE) *QxConjunction {
+	if len(x) == 0 {
+		return NewEmptyQx()
+	}
+	return Qx(columnName.NotIn(x))
+}
+
+// BetweenIfSet creates a range condition from the optional bounds, the zero bounds are left open:
+// BETWEEN when both are set, ">=" when only arg1 is set, "<=" when only arg2 is set, and the empty condition when none is set.
+//
+// BetweenIfSet 根据可选的上下界创建范围条件，零值的边界视为不限：
+// 两者都设置时使用 BETWEEN，只设置 arg1 时使用 ">="，只设置 arg2 时使用 "<="，都未设置时返回空条件。
+func (columnName ColumnName[TYPE]) BetweenIfSet(arg1, arg2 TYPE) *QxConjunction {
+	switch {
+	case isZeroValue(arg1):
+		return columnName.LteIfSet(arg2)
+	case isZeroValue(arg2):
+		return columnName.GteIfSet(arg1)
+	default:
+		return Qx(columnName.Between(arg1, arg2))
+	}
+}
+
+// qxIfSet creates the condition with the operation, or the empty condition when the value is zero
+// qxIfSet 使用给定操作创建条件，当值为零值时返回空条件
+func (columnName ColumnName[TYPE]) qxIfSet(x TYPE, operation func(x TYPE) (string, TYPE)) *QxConjunction {
+	if isZeroValue(x) {
+		return NewEmptyQx()
+	}
+	return Qx(operation(x))
+}
+
+// isZeroValue tells whether x is the zero value of its type, e.g. "", 0, false, nil pointer
+// isZeroValue 判断 x 是否为其类型的零值，例如 ""、0、false、nil 指针
+func isZeroValue(x interface{}) bool {
+	value := reflect.ValueOf(x)
+	return !value.IsValid() || value.IsZero()
+}
@@ -0,0 +1,123 @@
+// Package gormcnm tests validate the optional filters skipping zero values
+// Auto verifies the empty condition as the neutral element of AND/OR and the scope without WHERE
+// Tests examine SQLite execution and dry-run SQL with a search request
+//
+// gormcnm 测试包验证跳过零值的可选过滤条件
+// 自动验证空条件作为 AND/OR 的中性元素，以及不带 WHERE 的作用域
+// 测试涵盖使用搜索请求的 SQLite 执行和 dry-run SQL
+package gormcnm
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/yyle88/gormcnm/internal/tests"
+	"gorm.io/gorm"
+)
+
+func TestColumnName_EqIfSet(t *testing.T) {
+	type Example struct {
+		Name string `gorm:"primary_key;type:varchar(100);"`
+		Type string `gorm:"column:type;"`
+		Rank int    `gorm:"column:rank;"`
+	}
+
+	const (
+		columnName = ColumnName[string]("name")
+		columnType = ColumnName[string]("type")
+		columnRank = ColumnName[int]("rank")
+	)
+
+	type Request struct {
+		Name    string
+		Types   []string
+		RankMin int
+		RankMax int
+	}
+
+	search := func(req *Request) *QxConjunction {
+		return QxAND(
+			NewStringColumn(columnName).ContainsIfSet(req.Name),
+			columnType.InIfNotEmpty(req.Types),
+			columnRank.BetweenIfSet(req.RankMin, req.RankMax),
+		)
+	}
+
+	tests.NewDBRun(t, func(db *gorm.DB) {
+		require.NoError(t, db.AutoMigrate(&Example{}))
+		require.NoError(t, db.Create(&[]*Example{
+			{Name: "abc", Type: "xyz", Rank: 1},
+			{Name: "aaa", Type: "xxx", Rank: 2},
+			{Name: "bbb", Type: "xyz", Rank: 3},
+		}).Error)
+
+		selectNames := func(qx *QxConjunction) []string {
+			var names []string
+			require.NoError(t, db.Model(&Example{}).Scopes(qx.Scope()).Order(columnName.Name()).Pluck(columnName.Name(), &names).Error)
+			return names
+		}
+
+		require.Equal(t, []string{"aaa", "abc", "bbb"}, selectNames(search(&Request{})))
+		require.Equal(t, []string{"aaa", "abc"}, selectNames(search(&Request{Name: "a"})))
+		require.Equal(t, []string{"abc", "bbb"}, selectNames(search(&Request{Types: []string{"xyz"}})))
+		require.Equal(t, []string{"aaa", "bbb"}, selectNames(search(&Request{RankMin: 2})))
+		require.Equal(t, []string{"aaa", "abc"}, selectNames(search(&Request{RankMax: 2})))
+		require.Equal(t, []string{"abc"}, selectNames(search(&Request{Name: "a", Types: []string{"xyz"}, RankMin: 1, RankMax: 2})))
+	})
+
+	t.Run("dry-run", func(t *testing.T) {
+		db := tests.NewDryRunDB(t, "sqlite")
+		stmt := db.Scopes(search(&Request{}).Scope()).Find(&[]*Example{}).Statement
+		require.Equal(t, "SELECT * FROM `examples`", stmt.SQL.String())
+
+		stmt = db.Scopes(search(&Request{RankMin: 2}).Scope()).Find(&[]*Example{}).Statement
+		require.Equal(t, "SELECT * FROM `examples` WHERE `rank`>=?", stmt.SQL.String())
+		require.Equal(t, []interface{}{2}, stmt.Vars)
+	})
+}
+
+func TestColumnName_IfSet_ZeroValues(t *testing.T) {
+	const (
+		columnName = ColumnName[string]("name")
+		columnRank = ColumnName[*int]("rank")
+	)
+
+	var zero = 0
+	require.True(t, columnName.EqIfSet("").IsEmpty())
+	require.True(t, columnName.NeIfSet("").IsEmpty())
+	require.True(t, columnName.LikeIfSet("").IsEmpty())
+	require.True(t, columnName.NotInIfNotEmpty(nil).IsEmpty())
+	require.True(t, NewStringColumn(columnName).HasPrefixIfSet("").IsEmpty())
+	require.True(t, columnRank.GtIfSet(nil).IsEmpty())
+	require.False(t, columnRank.GtIfSet(&zero).IsEmpty()) // A non-nil pointer is set, even to the zero value
+	require.Equal(t, "name=?", columnName.EqIfSet("abc").Qs())
+	require.Equal(t, "name BETWEEN ? AND ?", columnName.BetweenIfSet("a", "b").Qs())
+}
+
+func TestNewEmptyQx(t *testing.T) {
+	const (
+		columnName = ColumnName[string]("name")
+		columnType = ColumnName[string]("type")
+	)
+
+	require.True(t, NewEmptyQx().IsEmpty())
+	require.True(t, NewEmptyQx().NOT().IsEmpty())
+	require.True(t, QxAND().IsEmpty())
+	require.True(t, QxOR(nil, NewEmptyQx()).IsEmpty())
+	require.True(t, NewQxConjunction(" ").IsEmpty())
+
+	var qx *QxConjunction
+	require.True(t, qx.IsEmpty())
+	qx = qx.AND(Qx(columnName.Eq("abc")))
+	require.Equal(t, "name=?", qx.Qs())
+
+	qx = QxOR(Qx(columnName.Eq("abc")), nil, NewEmptyQx(), Qx(columnType.Eq("xyz")))
+	require.Equal(t, "name=? OR type=?", qx.Qs())
+	require.Equal(t, []interface{}{"abc", "xyz"}, qx.Args())
+
+	t.Run("build", func(t *testing.T) {
+		db := tests.NewDryRunDB(t, "mysql")
+		stmt := db.Where(NewEmptyQx()).Find(&[]*struct{ Name string }{}).Statement
+		require.Contains(t, stmt.SQL.String(), "WHERE (1=1)")
+	})
+}
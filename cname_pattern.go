@@ -0,0 +1,117 @@
+// Package gormcnm provides escaped pattern matching to search text columns with user input safely
+// Auto escapes the LIKE wildcards "%" and "_" in the value and adds the ESCAPE clause of the dialect
+// Supports case-insensitive matching with ILIKE on PostgreSQL and LOWER() on the other dialects
+// Offered on StringColumn only, e.g. NewStringColumn(columnName).Contains("50%")
+//
+// gormcnm 提供转义的模式匹配，可以安全地使用用户输入搜索文本列
+// 自动转义值中的 LIKE 通配符 "%" 和 "_"，并添加方言对应的 ESCAPE 子句
+// 支持大小写不敏感的匹配，PostgreSQL 使用 ILIKE，其他方言使用 LOWER()
+// 仅在 StringColumn 上提供，例如 NewStringColumn(columnName).Contains("50%")
+package gormcnm
+
+// Contains creates a condition checking the column contains the value literally, e.g. "50%" matches "50%" only.
+// Contains 创建判断列按字面包含给定值的条件，例如 "50%" 只匹配 "50%"。
+func (sc StringColumn[TYPE]) Contains(value TYPE) *QxConjunction {
+	return sc.column.escapedLike("%", string(value), "%", false, false)
+}
+
+// NotContains creates a condition checking the column does not contain the value literally.
+// NotContains 创建判断列不按字面包含给定值的条件。
+func (sc StringColumn[TYPE]) NotContains(value TYPE) *QxConjunction {
+	return sc.column.escapedLike("%", string(value), "%", true, false)
+}
+
+// HasPrefix creates a condition checking the column starts with the value literally.
+// HasPrefix 创建判断列按字面以给定值开头的条件。
+func (sc StringColumn[TYPE]) HasPrefix(value TYPE) *QxConjunction {
+	return sc.column.escapedLike("", string(value), "%", false, false)
+}
+
+// NotHasPrefix creates a condition checking the column does not start with the value literally.
+// NotHasPrefix 创建判断列不按字面以给定值开头的条件。
+func (sc StringColumn[TYPE]) NotHasPrefix(value TYPE) *QxConjunction {
+	return sc.column.escapedLike("", string(value), "%", true, false)
+}
+
+// HasSuffix creates a condition checking the column ends with the value literally.
+// HasSuffix 创建判断列按字面以给定值结尾的条件。
+func (sc StringColumn[TYPE]) HasSuffix(value TYPE) *QxConjunction {
+	return sc.column.escapedLike("%", string(value), "", false, false)
+}
+
+// NotHasSuffix creates a condition checking the column does not end with the value literally.
+// NotHasSuffix 创建判断列不按字面以给定值结尾的条件。
+func (sc StringColumn[TYPE]) NotHasSuffix(value TYPE) *QxConjunction {
+	return sc.column.escapedLike("%", string(value), "", true, false)
+}
+
+// ContainsFold works like Contains but ignores letter case.
+// ContainsFold 与 Contains 相同，但忽略大小写。
+func (sc StringColumn[TYPE]) ContainsFold(value TYPE) *QxConjunction {
+	return sc.column.escapedLike("%", string(value), "%", false, true)
+}
+
+// NotContainsFold works like NotContains but ignores letter case.
+// NotContainsFold 与 NotContains 相同，但忽略大小写。
+func (sc StringColumn[TYPE]) NotContainsFold(value TYPE) *QxConjunction {
+	return sc.column.escapedLike("%", string(value), "%", true, true)
+}
+
+// HasPrefixFold works like HasPrefix but ignores letter case.
+// HasPrefixFold 与 HasPrefix 相同，但忽略大小写。
+func (sc StringColumn[TYPE]) HasPrefixFold(value TYPE) *QxConjunction {
+	return sc.column.escapedLike("", string(value), "%", false, true)
+}
+
+// NotHasPrefixFold works like NotHasPrefix but ignores letter case.
+// NotHasPrefixFold 与 NotHasPrefix 相同，但忽略大小写。
+func (sc StringColumn[TYPE]) NotHasPrefixFold(value TYPE) *QxConjunction {
+	return sc.column.escapedLike("", string(value), "%", true, true)
+}
+
+// HasSuffixFold works like HasSuffix but ignores letter case.
+// HasSuffixFold 与 HasSuffix 相同，但忽略大小写。
+func (sc StringColumn[TYPE]) HasSuffixFold(value TYPE) *QxConjunction {
+	return sc.column.escapedLike("%", string(value), "", false, true)
+}
+
+// NotHasSuffixFold works like NotHasSuffix but ignores letter case.
+// NotHasSuffixFold 与 NotHasSuffix 相同，但忽略大小写。
+func (sc StringColumn[TYPE]) NotHasSuffixFold(value TYPE) *QxConjunction {
+	return sc.column.escapedLike("%", string(value), "", true, true)
+}
+
+// escapedLike renders the LIKE condition lazily, escaping the value and wrapping it with the prefix and suffix wildcards
+// The predicate of the condition keeps the literal value, with the op telling the wildcards, e.g. "CONTAINS", "NOT HAS PREFIX FOLD"
+//
+// escapedLike 延迟渲染 LIKE 条件，转义值并在前后拼接通配符
+// 条件的谓词保存字面值，通配符由运算符表示，例如 "CONTAINS"、"NOT HAS PREFIX FOLD"
+func (columnName ColumnName[TYPE]) escapedLike(prefix string, value string, suffix string, not bool, fold bool) *QxConjunction {
+	var predicate = &QxPredicate{Column: columnName.Name(), Op: "CONTAINS", Values: []interface{}{value}}
+	if prefix == "" {
+		predicate.Op = "HAS PREFIX"
+	} else if suffix == "" {
+		predicate.Op = "HAS SUFFIX"
+	}
+	if not {
+		predicate.Op = "NOT " + predicate.Op
+	}
+	if fold {
+		predicate.Op += " FOLD"
+	}
+	return NewDialectQx(func(dialect Dialect) (string, []interface{}) {
+		var column, op, placeholder = dialect.quoteColumn(columnName.Name()), "LIKE", "?"
+		if fold {
+			if dialect == DialectPostgres {
+				op = "ILIKE"
+			} else {
+				column, placeholder = "LOWER("+column+")", "LOWER(?)"
+			}
+		}
+		if not {
+			op = "NOT " + op
+		}
+		pattern := prefix + dialect.EscapeLike(value) + suffix
+		return column + " " + op + " " + placeholder + dialect.LikeEscape(), []interface{}{pattern}
+	}).withPredicate(predicate)
+}
@@ -0,0 +1,71 @@
+// Package gormcnm tests validate escaped pattern matching on text columns
+// Auto verifies wildcards in the value match literally and case-insensitive matching works
+// Tests examine SQLite execution and DryRun rendering of MySQL, PostgreSQL and SQL Server
+//
+// gormcnm 测试包验证文本列上的转义模式匹配
+// 自动验证值中的通配符按字面匹配，以及大小写不敏感的匹配
+// 测试涵盖 SQLite 执行以及 MySQL、PostgreSQL 和 SQL Server 的 DryRun 渲染
+package gormcnm
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/yyle88/gormcnm/internal/tests"
+	"gorm.io/gorm"
+)
+
+func TestStringColumn_Contains(t *testing.T) {
+	type Example struct {
+		Name string `gorm:"primary_key;type:varchar(100);"`
+	}
+
+	var columnName = NewStringColumn(ColumnName[string]("name"))
+
+	tests.NewDBRun(t, func(db *gorm.DB) {
+		require.NoError(t, db.AutoMigrate(&Example{}))
+		for _, name := range []string{"50% off", "500 off", "a_b", "axb", `c\d`, "Hello World"} {
+			require.NoError(t, db.Save(&Example{Name: name}).Error)
+		}
+
+		selectNames := func(qx *QxConjunction) []string {
+			var names []string
+			require.NoError(t, db.Model(&Example{}).Where(qx).Order(columnName.Name()).Pluck(columnName.Name(), &names).Error)
+			return names
+		}
+
+		require.Equal(t, []string{"50% off"}, selectNames(columnName.Contains("0%")))
+		require.Equal(t, []string{"a_b"}, selectNames(columnName.HasPrefix("a_")))
+		require.Equal(t, []string{"a_b"}, selectNames(columnName.HasSuffix("_b")))
+		require.Equal(t, []string{`c\d`}, selectNames(columnName.Contains(`\`)))
+		require.Equal(t, []string{"500 off", "Hello World", "a_b", "axb", `c\d`}, selectNames(columnName.NotContains("%")))
+		require.Equal(t, []string{"50% off", "500 off", "a_b", "axb", `c\d`}, selectNames(columnName.NotHasPrefix("Hello")))
+		require.Equal(t, []string{"50% off", "500 off", "a_b", "axb", `c\d`}, selectNames(columnName.NotHasSuffix("World")))
+
+		require.Equal(t, []string{"Hello World"}, selectNames(columnName.ContainsFold("o wOR")))
+		require.Equal(t, []string{"Hello World"}, selectNames(columnName.HasPrefixFold("HELLO")))
+		require.Equal(t, []string{"Hello World"}, selectNames(columnName.HasSuffixFold("world")))
+		require.Len(t, selectNames(columnName.NotContainsFold("HELLO")), 5)
+		require.Len(t, selectNames(columnName.NotHasPrefixFold("A_")), 5)
+		require.Len(t, selectNames(columnName.NotHasSuffixFold("OFF")), 4)
+	})
+
+	t.Run("mysql", func(t *testing.T) {
+		db := tests.NewDryRunDB(t, "mysql")
+		stmt := db.Where(columnName.Contains("50%")).Find(&[]*Example{}).Statement
+		require.Equal(t, "SELECT * FROM `examples` WHERE (`name` LIKE ?)", stmt.SQL.String())
+		require.Equal(t, []interface{}{`%50\%%`}, stmt.Vars)
+	})
+	t.Run("postgres", func(t *testing.T) {
+		db := tests.NewDryRunDB(t, "postgres")
+		stmt := db.Where(columnName.NotHasPrefixFold("a_")).Find(&[]*Example{}).Statement
+		require.Equal(t, `SELECT * FROM "examples" WHERE ("name" NOT ILIKE $1 ESCAPE '\')`, stmt.SQL.String())
+		require.Equal(t, []interface{}{`a\_%`}, stmt.Vars)
+	})
+	t.Run("sqlserver", func(t *testing.T) {
+		db := tests.NewDryRunDB(t, "sqlserver")
+		stmt := db.Where(columnName.HasSuffixFold("[x]")).Find(&[]*Example{}).Statement
+		require.Equal(t, `SELECT * FROM [examples] WHERE (LOWER([name]) LIKE LOWER(?) ESCAPE '\')`, stmt.SQL.String())
+		require.Equal(t, []interface{}{`%\[x]`}, stmt.Vars)
+	})
+}
@@ -0,0 +1,114 @@
+// Package gormcnm provides subquery operations to build IN, EXISTS and scalar comparison conditions
+// Auto renders *gorm.DB subqueries inline, merging their arguments into the outer statement
+// Supports typed subqueries selecting one column, keeping the column types matched at compile time
+//
+// gormcnm 提供子查询操作，用于构建 IN、EXISTS 和标量比较条件
+// 自动内联渲染 *gorm.DB 子查询，并将其参数合并到外层语句中
+// 支持选择单列的类型化子查询，在编译期确保列类型一致
+package gormcnm
+
+import (
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// SubQuery represents a subquery selecting a single column of TYPE, rendered as "(SELECT ...)"
+// It implements clause.Expression, thus the subquery arguments are merged when GORM builds the outer statement
+//
+// SubQuery 表示选择单个 TYPE 类型列的子查询，渲染为 "(SELECT ...)"
+// 它实现了 clause.Expression 接口，因此在 GORM 构建外层语句时会合并子查询的参数
+type SubQuery[TYPE any] struct {
+	db *gorm.DB // The subquery, selecting a single column of TYPE // 子查询，选择单个 TYPE 类型的列
+}
+
+// NewSubQuery creates a SubQuery with the *gorm.DB, which must select a single column of TYPE
+// NewSubQuery 使用 *gorm.DB 创建 SubQuery，该查询必须选择单个 TYPE 类型的列
+func NewSubQuery[TYPE any](db *gorm.DB) *SubQuery[TYPE] {
+	return &SubQuery[TYPE]{db: db}
+}
+
+// SubQuery creates a SubQuery selecting this column from the *gorm.DB.
+// SubQuery 从 *gorm.DB 中选择该列，创建一个 SubQuery。
+func (columnName ColumnName[TYPE]) SubQuery(db *gorm.DB) *SubQuery[TYPE] {
+	return NewSubQuery[TYPE](db.Select(columnName.Name()))
+}
+
+// DB returns the underlying *gorm.DB of the subquery
+// DB 返回子查询底层的 *gorm.DB
+func (sub *SubQuery[TYPE]) DB() *gorm.DB {
+	return sub.db
+}
+
+// Build implements clause.Expression, writing the subquery in parentheses with its arguments bound
+// Build 实现 clause.Expression 接口，将子查询写在括号中并绑定其参数
+func (sub *SubQuery[TYPE]) Build(builder clause.Builder) {
+	builder.WriteByte('(')
+	builder.AddVar(builder, sub.db)
+	builder.WriteByte(')')
+}
+
+// InSub creates a condition checking the column value is in the subquery result.
+// InSub 创建判断列值在子查询结果中的条件。
+func (columnName ColumnName[TYPE]) InSub(sub *SubQuery[TYPE]) *QxConjunction {
+	return columnName.QxSub("IN", sub)
+}
+
+// NotInSub creates a condition checking the column value is not in the subquery result.
+// NotInSub 创建判断列值不在子查询结果中的条件。
+func (columnName ColumnName[TYPE]) NotInSub(sub *SubQuery[TYPE]) *QxConjunction {
+	return columnName.QxSub("NOT IN", sub)
+}
+
+// EqSub creates a condition checking the column equals the scalar subquery result, e.g. "= (SELECT MAX(...))".
+// EqSub 创建判断列等于标量子查询结果的条件，例如 "= (SELECT MAX(...))"。
+func (columnName ColumnName[TYPE]) EqSub(sub *SubQuery[TYPE]) *QxConjunction {
+	return columnName.QxSub("=", sub)
+}
+
+// NeSub creates a condition checking the column differs from the scalar subquery result.
+// NeSub 创建判断列不等于标量子查询结果的条件。
+func (columnName ColumnName[TYPE]) NeSub(sub *SubQuery[TYPE]) *QxConjunction {
+	return columnName.QxSub("!=", sub)
+}
+
+// GtSub creates a condition checking the column is more than the scalar subquery result.
+// GtSub 创建判断列大于标量子查询结果的条件。
+func (columnName ColumnName[TYPE]) GtSub(sub *SubQuery[TYPE]) *QxConjunction {
+	return columnName.QxSub(">", sub)
+}
+
+// GteSub creates a condition checking the column is at least the scalar subquery result.
+// GteSub 创建判断列大于等于标量子查询结果的条件。
+func (columnName ColumnName[TYPE]) GteSub(sub *SubQuery[TYPE]) *QxConjunction {
+	return columnName.QxSub(">=", sub)
+}
+
+// LtSub creates a condition checking the column is less than the scalar subquery result.
+// LtSub 创建判断列小于标量子查询结果的条件。
+func (columnName ColumnName[TYPE]) LtSub(sub *SubQuery[TYPE]) *QxConjunction {
+	return columnName.QxSub("<", sub)
+}
+
+// LteSub creates a condition checking the column is at most the scalar subquery result.
+// LteSub 创建判断列小于等于标量子查询结果的条件。
+func (columnName ColumnName[TYPE]) LteSub(sub *SubQuery[TYPE]) *QxConjunction {
+	return columnName.QxSub("<=", sub)
+}
+
+// QxSub creates a condition comparing the column with the subquery using the op (e.g., "IN", ">=").
+// QxSub 使用运算符（例如 "IN"、">="）创建列与子查询比较的条件。
+func (columnName ColumnName[TYPE]) QxSub(op string, sub *SubQuery[TYPE]) *QxConjunction {
+	return NewQxConjunction(columnName.Qs(op+" ?"), sub)
+}
+
+// Exists creates a condition checking the subquery returns any rows.
+// Exists 创建判断子查询返回结果行的条件。
+func Exists(sub *gorm.DB) *QxConjunction {
+	return NewQxConjunction("EXISTS ?", NewSubQuery[any](sub))
+}
+
+// NotExists creates a condition checking the subquery returns no rows.
+// NotExists 创建判断子查询不返回任何行的条件。
+func NotExists(sub *gorm.DB) *QxConjunction {
+	return NewQxConjunction("NOT EXISTS ?", NewSubQuery[any](sub))
+}
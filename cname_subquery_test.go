@@ -0,0 +1,119 @@
+// Package gormcnm tests validate subquery conditions with IN, EXISTS and scalar comparisons
+// Auto verifies typed subqueries and argument merging into the outer statement
+// Tests examine SQLite execution with users and orders tables
+//
+// gormcnm 测试包验证 IN、EXISTS 和标量比较的子查询条件
+// 自动验证类型化子查询以及参数合并到外层语句
+// 测试涵盖使用 users 和 orders 表的 SQLite 执行
+package gormcnm
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/yyle88/gormcnm/internal/tests"
+	"github.com/yyle88/neatjson/neatjsons"
+	"gorm.io/gorm"
+)
+
+type subqueryUser struct {
+	ID   uint   `gorm:"primaryKey"`
+	Name string `gorm:"column:name;"`
+}
+
+func (*subqueryUser) TableName() string {
+	return "users"
+}
+
+type subqueryOrder struct {
+	ID     uint    `gorm:"primaryKey"`
+	UserID uint    `gorm:"column:user_id;"`
+	Amount float64 `gorm:"column:amount;"`
+}
+
+func (*subqueryOrder) TableName() string {
+	return "orders"
+}
+
+const (
+	subqueryUserID      = ColumnName[uint]("id")
+	subqueryUserName    = ColumnName[string]("name")
+	subqueryOrderUserID = ColumnName[uint]("user_id")
+	subqueryOrderAmount = ColumnName[float64]("amount")
+)
+
+func newSubqueryDB(t *testing.T) *gorm.DB {
+	db := tests.NewMemDB(t)
+	require.NoError(t, db.AutoMigrate(&subqueryUser{}, &subqueryOrder{}))
+	require.NoError(t, db.Create(&[]*subqueryUser{{ID: 1, Name: "Alice"}, {ID: 2, Name: "Bob"}, {ID: 3, Name: "Carl"}}).Error)
+	require.NoError(t, db.Create(&[]*subqueryOrder{
+		{ID: 1, UserID: 1, Amount: 100},
+		{ID: 2, UserID: 1, Amount: 200},
+		{ID: 3, UserID: 2, Amount: 300},
+	}).Error)
+	return db
+}
+
+func TestColumnName_InSub(t *testing.T) {
+	db := newSubqueryDB(t)
+
+	t.Run("in", func(t *testing.T) {
+		sub := subqueryOrderUserID.SubQuery(db.Model(&subqueryOrder{}).Where(subqueryOrderAmount.Gte(200.0)))
+
+		var users []*subqueryUser
+		require.NoError(t, db.Where(subqueryUserName.Qx("!=?", "Carl").AND(subqueryUserID.InSub(sub))).Order(subqueryUserID.Ob("asc").Ox()).Find(&users).Error)
+		t.Log(neatjsons.S(users))
+		require.Len(t, users, 2)
+		require.Equal(t, "Alice", users[0].Name)
+		require.Equal(t, "Bob", users[1].Name)
+	})
+	t.Run("not-in", func(t *testing.T) {
+		sub := subqueryOrderUserID.SubQuery(db.Model(&subqueryOrder{}))
+
+		var users []*subqueryUser
+		require.NoError(t, db.Where(subqueryUserID.NotInSub(sub)).Find(&users).Error)
+		require.Len(t, users, 1)
+		require.Equal(t, "Carl", users[0].Name)
+	})
+	t.Run("qx-args", func(t *testing.T) {
+		sub := subqueryOrderUserID.SubQuery(db.Model(&subqueryOrder{}).Where(subqueryOrderAmount.Lt(150.0)))
+		qx := subqueryUserID.InSub(sub).OR(Qx(subqueryUserName.Eq("Carl")))
+
+		var users []*subqueryUser
+		require.NoError(t, db.Where(qx.Qx2()).Order(subqueryUserID.Ob("asc").Ox()).Find(&users).Error)
+		require.Len(t, users, 2)
+		require.Equal(t, "Alice", users[0].Name)
+		require.Equal(t, "Carl", users[1].Name)
+	})
+}
+
+func TestColumnName_EqSub(t *testing.T) {
+	db := newSubqueryDB(t)
+
+	var orders []*subqueryOrder
+	sub := NewSubQuery[float64](db.Model(&subqueryOrder{}).Select(subqueryOrderAmount.Max("")).Where(subqueryOrderUserID.Eq(1)))
+	require.NoError(t, db.Where(subqueryOrderAmount.EqSub(sub)).Find(&orders).Error)
+	require.Len(t, orders, 1)
+	require.Equal(t, uint(2), orders[0].ID)
+
+	var count int64
+	require.NoError(t, db.Model(&subqueryOrder{}).Where(subqueryOrderAmount.GtSub(NewSubQuery[float64](db.Model(&subqueryOrder{}).Select(subqueryOrderAmount.Avg(""))))).Count(&count).Error)
+	require.Equal(t, int64(1), count)
+}
+
+func TestExists(t *testing.T) {
+	db := newSubqueryDB(t)
+
+	common := &ColumnOperationClass{}
+	sub := db.Model(&subqueryOrder{}).Where("orders.user_id = users.id").Where(subqueryOrderAmount.Gt(250.0))
+
+	var users []*subqueryUser
+	require.NoError(t, db.Where(common.Exists(sub)).Find(&users).Error)
+	require.Len(t, users, 1)
+	require.Equal(t, "Bob", users[0].Name)
+
+	users = nil
+	require.NoError(t, db.Where(NotExists(sub).AND(Qx(subqueryUserID.Lt(3)))).Find(&users).Error)
+	require.Len(t, users, 1)
+	require.Equal(t, "Alice", users[0].Name)
+}
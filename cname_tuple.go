@@ -0,0 +1,347 @@
+// Package gormcnm provides row-value (tuple) comparisons across several columns
+// Auto renders (a, b) IN ((?, ?), ...) and (a, b) > (?, ?) in the dialect of the DB lazily
+// Supports expanding into OR/AND chains when the dialect lacks row-value syntax, such as SQL Server
+//
+// gormcnm 提供跨多列的行值（元组）比较
+// 自动按数据库方言延迟渲染 (a, b) IN ((?, ?), ...) 和 (a, b) > (?, ?)
+// 支持在方言不支持行值语法时（例如 SQL Server）展开为 OR/AND 链
+package gormcnm
+
+import (
+	"strings"
+
+	"github.com/yyle88/gormcnm/internal/utils"
+	"github.com/yyle88/must"
+)
+
+// ColumnTuple represents a row value made of several columns, e.g. (tenant_id, order_no)
+// Use NewTuple2, NewTuple3, NewTuple4 to get typed values, or NewColumnTuple with any number of columns
+//
+// ColumnTuple 表示由多列组成的行值，例如 (tenant_id, order_no)
+// 使用 NewTuple2、NewTuple3、NewTuple4 获得类型化的值，或使用 NewColumnTuple 组合任意数量的列
+type ColumnTuple struct {
+	names    []string // Column names in the tuple // 元组中的列名
+	expanded bool     // Always expand into OR/AND chains // 总是展开为 OR/AND 链
+}
+
+// NewColumnTuple creates a ColumnTuple with two or more columns
+// NewColumnTuple 使用两个或更多列创建 ColumnTuple
+func NewColumnTuple(columns ...utils.ColumnNameInterface) *ColumnTuple {
+	must.True(len(columns) >= 2)
+	names := make([]string, 0, len(columns))
+	for _, column := range columns {
+		names = append(names, column.Name())
+	}
+	return &ColumnTuple{names: names}
+}
+
+// Names returns the column names in the tuple
+// Names 返回元组中的列名
+func (ct *ColumnTuple) Names() []string {
+	return ct.names
+}
+
+// Expanded returns a tuple which always renders as OR/AND chains, whatever the dialect supports
+// Expanded 返回一个总是渲染为 OR/AND 链的元组，无论方言是否支持行值语法
+func (ct *ColumnTuple) Expanded() *ColumnTuple {
+	return &ColumnTuple{names: ct.names, expanded: true}
+}
+
+// rowValueSupported tells whether to use the row-value syntax in the dialect
+// rowValueSupported 判断在该方言中是否使用行值语法
+func (ct *ColumnTuple) rowValueSupported(dialect Dialect) bool {
+	return !ct.expanded && dialect != DialectSQLServer
+}
+
+// In creates a condition checking the tuple is in the given rows
+// SQLite renders the rows with VALUES, as it only accepts a subquery at the right side of a row-value IN
+//
+// In 创建判断元组在给定行中的条件
+// SQLite 使用 VALUES 渲染行，因为行值 IN 的右侧只接受子查询
+func (ct *ColumnTuple) In(rows ...[]interface{}) *QxConjunction {
+	for _, row := range rows {
+		must.Len(row, len(ct.names))
+	}
+	return NewDialectQx(func(dialect Dialect) (string, []interface{}) {
+		return ct.inStatement(dialect, rows)
+	})
+}
+
+// NotIn creates a condition checking the tuple is not in the given rows
+// NotIn 创建判断元组不在给定行中的条件
+func (ct *ColumnTuple) NotIn(rows ...[]interface{}) *QxConjunction {
+	for _, row := range rows {
+		must.Len(row, len(ct.names))
+	}
+	return NewDialectQx(func(dialect Dialect) (string, []interface{}) {
+		if len(rows) == 0 {
+			return "1 = 1", nil
+		}
+		stmt, args := ct.inStatement(dialect, rows)
+		if ct.rowValueSupported(dialect) {
+			return strings.Replace(stmt, " IN ", " NOT IN ", 1), args
+		}
+		return "NOT " + stmt, args
+	})
+}
+
+// inStatement renders the IN statement of the tuple in the dialect
+// inStatement 按方言渲染元组的 IN 语句
+func (ct *ColumnTuple) inStatement(dialect Dialect, rows [][]interface{}) (string, []interface{}) {
+	if len(rows) == 0 {
+		return "1 = 0", nil
+	}
+	var args = make([]interface{}, 0, len(rows)*len(ct.names))
+	for _, row := range rows {
+		args = append(args, row...)
+	}
+	var stmts = make([]string, 0, len(rows))
+	if ct.rowValueSupported(dialect) {
+		for range rows {
+			stmts = append(stmts, ct.placeholders())
+		}
+		if dialect == DialectSQLite {
+			return ct.tupleName(dialect) + " IN (VALUES " + strings.Join(stmts, ", ") + ")", args
+		}
+		return ct.tupleName(dialect) + " IN (" + strings.Join(stmts, ", ") + ")", args
+	}
+	for range rows {
+		stmts = append(stmts, "("+ct.equalsStatement(dialect)+")")
+	}
+	return "(" + strings.Join(stmts, " OR ") + ")", args
+}
+
+// Eq creates a condition checking the tuple equals the values
+// Eq 创建判断元组等于给定值的条件
+func (ct *ColumnTuple) Eq(values ...interface{}) *QxConjunction {
+	return ct.Qx("=", values...)
+}
+
+// Ne creates a condition checking the tuple differs from the values
+// Ne 创建判断元组不等于给定值的条件
+func (ct *ColumnTuple) Ne(values ...interface{}) *QxConjunction {
+	return ct.Qx("!=", values...)
+}
+
+// Gt creates a condition checking the tuple is more than the values, in lexicographic order
+// Gt 创建判断元组按字典序大于给定值的条件
+func (ct *ColumnTuple) Gt(values ...interface{}) *QxConjunction {
+	return ct.Qx(">", values...)
+}
+
+// Gte creates a condition checking the tuple is at least the values, in lexicographic order
+// Gte 创建判断元组按字典序大于等于给定值的条件
+func (ct *ColumnTuple) Gte(values ...interface{}) *QxConjunction {
+	return ct.Qx(">=", values...)
+}
+
+// Lt creates a condition checking the tuple is less than the values, in lexicographic order
+// Lt 创建判断元组按字典序小于给定值的条件
+func (ct *ColumnTuple) Lt(values ...interface{}) *QxConjunction {
+	return ct.Qx("<", values...)
+}
+
+// Lte creates a condition checking the tuple is at most the values, in lexicographic order
+// Lte 创建判断元组按字典序小于等于给定值的条件
+func (ct *ColumnTuple) Lte(values ...interface{}) *QxConjunction {
+	return ct.Qx("<=", values...)
+}
+
+// Qx creates a condition comparing the tuple with the values using the op (=, !=, <>, >, >=, <, <=)
+// Qx 使用运算符（=、!=、<>、>、>=、<、<=）创建元组与给定值比较的条件
+func (ct *ColumnTuple) Qx(op string, values ...interface{}) *QxConjunction {
+	must.Len(values, len(ct.names))
+	must.In(op, []string{"=", "!=", "<>", ">", ">=", "<", "<="})
+	return NewDialectQx(func(dialect Dialect) (string, []interface{}) {
+		if ct.rowValueSupported(dialect) {
+			return ct.tupleName(dialect) + " " + op + " " + ct.placeholders(), values
+		}
+		switch op {
+		case "=":
+			return "(" + ct.equalsStatement(dialect) + ")", values
+		case "!=", "<>":
+			return "NOT (" + ct.equalsStatement(dialect) + ")", values
+		default:
+			var strictOp = strings.TrimSuffix(op, "=")
+			var ops = make([]string, len(ct.names))
+			for idx := range ops {
+				ops[idx] = strictOp
+			}
+			ops[len(ops)-1] = op
+			return expandCompare(dialect, ct.names, ops, values)
+		}
+	})
+}
+
+// tupleName returns the tuple spelled as "(a, b)", the columns quoted in the dialect
+// tupleName 返回 "(a, b)" 形式的元组，列名按方言加引号
+func (ct *ColumnTuple) tupleName(dialect Dialect) string {
+	var names = make([]string, 0, len(ct.names))
+	for _, name := range ct.names {
+		names = append(names, dialect.quoteColumn(name))
+	}
+	return "(" + strings.Join(names, ", ") + ")"
+}
+
+// placeholders returns the row placeholders spelled as "(?, ?)"
+// placeholders 返回 "(?, ?)" 形式的行占位符
+func (ct *ColumnTuple) placeholders() string {
+	return "(" + strings.TrimSuffix(strings.Repeat("?, ", len(ct.names)), ", ") + ")"
+}
+
+// equalsStatement returns the statement "a = ? AND b = ?", the columns quoted in the dialect
+// equalsStatement 返回 "a = ? AND b = ?" 语句，列名按方言加引号
+func (ct *ColumnTuple) equalsStatement(dialect Dialect) string {
+	var stmts = make([]string, 0, len(ct.names))
+	for _, name := range ct.names {
+		stmts = append(stmts, dialect.quoteColumn(name)+" = ?")
+	}
+	return strings.Join(stmts, " AND ")
+}
+
+// expandCompare expands a lexicographic comparison into "(a > ?) OR (a = ? AND b > ?) ...", the columns quoted in the dialect
+// The ops[i] is used when the i-th column decides the order, the leading columns are compared as equal
+//
+// expandCompare 将字典序比较展开为 "(a > ?) OR (a = ? AND b > ?) ..."，列名按方言加引号
+// 当第 i 列决定顺序时使用 ops[i]，前面的列按相等比较
+func expandCompare(dialect Dialect, names []string, ops []string, values []interface{}) (string, []interface{}) {
+	var stmts = make([]string, 0, len(names))
+	var args = make([]interface{}, 0, len(names)*(len(names)+1)/2)
+	for idx := range names {
+		var parts = make([]string, 0, idx+1)
+		for pre := 0; pre < idx; pre++ {
+			parts = append(parts, dialect.quoteColumn(names[pre])+" = ?")
+			args = append(args, values[pre])
+		}
+		parts = append(parts, dialect.quoteColumn(names[idx])+" "+ops[idx]+" ?")
+		args = append(args, values[idx])
+		stmts = append(stmts, "("+strings.Join(parts, " AND ")+")")
+	}
+	return "(" + strings.Join(stmts, " OR ") + ")", args
+}
+
+// Tuple2 represents a typed row value of two columns
+// Tuple2 表示两列组成的类型化行值
+type Tuple2[A, B any] struct {
+	*ColumnTuple
+}
+
+// NewTuple2 creates a typed tuple of two columns
+// NewTuple2 创建两列组成的类型化元组
+func NewTuple2[A, B any](a ColumnName[A], b ColumnName[B]) *Tuple2[A, B] {
+	return &Tuple2[A, B]{ColumnTuple: NewColumnTuple(a, b)}
+}
+
+// Row creates a typed row of values matching the tuple columns
+// Row 创建与元组列类型匹配的值行
+func (tp *Tuple2[A, B]) Row(a A, b B) []interface{} {
+	return []interface{}{a, b}
+}
+
+// Expanded returns a tuple which always renders as OR/AND chains
+// Expanded 返回一个总是渲染为 OR/AND 链的元组
+func (tp *Tuple2[A, B]) Expanded() *Tuple2[A, B] {
+	return &Tuple2[A, B]{ColumnTuple: tp.ColumnTuple.Expanded()}
+}
+
+// EqValues creates a condition checking the tuple equals the typed values
+// EqValues 创建判断元组等于类型化值的条件
+func (tp *Tuple2[A, B]) EqValues(a A, b B) *QxConjunction {
+	return tp.Eq(tp.Row(a, b)...)
+}
+
+// GtValues creates a condition checking the tuple is more than the typed values
+// GtValues 创建判断元组大于类型化值的条件
+func (tp *Tuple2[A, B]) GtValues(a A, b B) *QxConjunction {
+	return tp.Gt(tp.Row(a, b)...)
+}
+
+// LtValues creates a condition checking the tuple is less than the typed values
+// LtValues 创建判断元组小于类型化值的条件
+func (tp *Tuple2[A, B]) LtValues(a A, b B) *QxConjunction {
+	return tp.Lt(tp.Row(a, b)...)
+}
+
+// Tuple3 represents a typed row value of three columns
+// Tuple3 表示三列组成的类型化行值
+type Tuple3[A, B, C any] struct {
+	*ColumnTuple
+}
+
+// NewTuple3 creates a typed tuple of three columns
+// NewTuple3 创建三列组成的类型化元组
+func NewTuple3[A, B, C any](a ColumnName[A], b ColumnName[B], c ColumnName[C]) *Tuple3[A, B, C] {
+	return &Tuple3[A, B, C]{ColumnTuple: NewColumnTuple(a, b, c)}
+}
+
+// Row creates a typed row of values matching the tuple columns
+// Row 创建与元组列类型匹配的值行
+func (tp *Tuple3[A, B, C]) Row(a A, b B, c C) []interface{} {
+	return []interface{}{a, b, c}
+}
+
+// Expanded returns a tuple which always renders as OR/AND chains
+// Expanded 返回一个总是渲染为 OR/AND 链的元组
+func (tp *Tuple3[A, B, C]) Expanded() *Tuple3[A, B, C] {
+	return &Tuple3[A, B, C]{ColumnTuple: tp.ColumnTuple.Expanded()}
+}
+
+// EqValues creates a condition checking the tuple equals the typed values
+// EqValues 创建判断元组等于类型化值的条件
+func (tp *Tuple3[A, B, C]) EqValues(a A, b B, c C) *QxConjunction {
+	return tp.Eq(tp.Row(a, b, c)...)
+}
+
+// GtValues creates a condition checking the tuple is more than the typed values
+// GtValues 创建判断元组大于类型化值的条件
+func (tp *Tuple3[A, B, C]) GtValues(a A, b B, c C) *QxConjunction {
+	return tp.Gt(tp.Row(a, b, c)...)
+}
+
+// LtValues creates a condition checking the tuple is less than the typed values
+// LtValues 创建判断元组小于类型化值的条件
+func (tp *Tuple3[A, B, C]) LtValues(a A, b B, c C) *QxConjunction {
+	return tp.Lt(tp.Row(a, b, c)...)
+}
+
+// Tuple4 represents a typed row value of four columns
+// Tuple4 表示四列组成的类型化行值
+type Tuple4[A, B, C, D any] struct {
+	*ColumnTuple
+}
+
+// NewTuple4 creates a typed tuple of four columns
+// NewTuple4 创建四列组成的类型化元组
+func NewTuple4[A, B, C, D any](a ColumnName[A], b ColumnName[B], c ColumnName[C], d ColumnName[D]) *Tuple4[A, B, C, D] {
+	return &Tuple4[A, B, C, D]{ColumnTuple: NewColumnTuple(a, b, c, d)}
+}
+
+// Row creates a typed row of values matching the tuple columns
+// Row 创建与元组列类型匹配的值行
+func (tp *Tuple4[A, B, C, D]) Row(a A, b B, c C, d D) []interface{} {
+	return []interface{}{a, b, c, d}
+}
+
+// Expanded returns a tuple which always renders as OR/AND chains
+// Expanded 返回一个总是渲染为 OR/AND 链的元组
+func (tp *Tuple4[A, B, C, D]) Expanded() *Tuple4[A, B, C, D] {
+	return &Tuple4[A, B, C, D]{ColumnTuple: tp.ColumnTuple.Expanded()}
+}
+
+// EqValues creates a condition checking the tuple equals the typed values
+// EqValues 创建判断元组等于类型化值的条件
+func (tp *Tuple4[A, B, C, D]) EqValues(a A, b B, c C, d D) *QxConjunction {
+	return tp.Eq(tp.Row(a, b, c, d)...)
+}
+
+// GtValues creates a condition checking the tuple is more than the typed values
+// GtValues 创建判断元组大于类型化值的条件
+func (tp *Tuple4[A, B, C, D]) GtValues(a A, b B, c C, d D) *QxConjunction {
+	return tp.Gt(tp.Row(a, b, c, d)...)
+}
+
+// LtValues creates a condition checking the tuple is less than the typed values
+// LtValues 创建判断元组小于类型化值的条件
+func (tp *Tuple4[A, B, C, D]) LtValues(a A, b B, c C, d D) *QxConjunction {
+	return tp.Lt(tp.Row(a, b, c, d)...)
+}
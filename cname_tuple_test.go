@@ -0,0 +1,102 @@
+// Package gormcnm tests validate row-value (tuple) comparisons across several columns
+// Auto verifies IN, NOT IN and lexicographic comparisons with typed value rows
+// Tests examine SQLite execution, expanded OR/AND chains and DryRun rendering of MySQL and SQL Server
+//
+// gormcnm 测试包验证跨多列的行值（元组）比较
+// 自动验证使用类型化值行的 IN、NOT IN 和字典序比较
+// 测试涵盖 SQLite 执行、展开的 OR/AND 链以及 MySQL 和 SQL Server 的 DryRun 渲染
+package gormcnm
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/yyle88/gormcnm/internal/tests"
+	"gorm.io/gorm"
+)
+
+type tupleOrder struct {
+	TenantID int    `gorm:"primaryKey;column:tenant_id;"`
+	OrderNo  string `gorm:"primaryKey;column:order_no;"`
+	Seq      int    `gorm:"column:seq;"`
+}
+
+func (*tupleOrder) TableName() string {
+	return "orders"
+}
+
+const (
+	tupleTenantID = ColumnName[int]("tenant_id")
+	tupleOrderNo  = ColumnName[string]("order_no")
+	tupleSeq      = ColumnName[int]("seq")
+)
+
+func newTupleDB(t *testing.T) *gorm.DB {
+	db := tests.NewMemDB(t)
+	require.NoError(t, db.AutoMigrate(&tupleOrder{}))
+	require.NoError(t, db.Create(&[]*tupleOrder{
+		{TenantID: 1, OrderNo: "a", Seq: 1},
+		{TenantID: 1, OrderNo: "b", Seq: 2},
+		{TenantID: 2, OrderNo: "a", Seq: 3},
+		{TenantID: 2, OrderNo: "b", Seq: 4},
+	}).Error)
+	return db
+}
+
+func selectTupleSeqs(t *testing.T, db *gorm.DB, qx *QxConjunction) []int {
+	var seqs []int
+	require.NoError(t, db.Model(&tupleOrder{}).Where(qx).Order(tupleSeq.Name()).Pluck(tupleSeq.Name(), &seqs).Error)
+	return seqs
+}
+
+func TestColumnTuple_In(t *testing.T) {
+	db := newTupleDB(t)
+	tuple := NewTuple2(tupleTenantID, tupleOrderNo)
+
+	for _, tp := range []*Tuple2[int, string]{tuple, tuple.Expanded()} {
+		require.Equal(t, []int{2, 3}, selectTupleSeqs(t, db, tp.In(tp.Row(1, "b"), tp.Row(2, "a"))))
+		require.Equal(t, []int{1, 4}, selectTupleSeqs(t, db, tp.NotIn(tp.Row(1, "b"), tp.Row(2, "a"))))
+		require.Empty(t, selectTupleSeqs(t, db, tp.In()))
+		require.Equal(t, []int{1, 2, 3, 4}, selectTupleSeqs(t, db, tp.NotIn()))
+		require.Equal(t, []int{2}, selectTupleSeqs(t, db, tp.In(tp.Row(1, "b")).AND(tupleSeq.Qx("> ?", 0))))
+	}
+}
+
+func TestColumnTuple_Gt(t *testing.T) {
+	db := newTupleDB(t)
+	tuple := NewTuple2(tupleTenantID, tupleOrderNo)
+
+	for _, tp := range []*Tuple2[int, string]{tuple, tuple.Expanded()} {
+		require.Equal(t, []int{3, 4}, selectTupleSeqs(t, db, tp.GtValues(1, "b")))
+		require.Equal(t, []int{2, 3, 4}, selectTupleSeqs(t, db, tp.Gte(1, "b")))
+		require.Equal(t, []int{1, 2}, selectTupleSeqs(t, db, tp.LtValues(2, "a")))
+		require.Equal(t, []int{1, 2, 3}, selectTupleSeqs(t, db, tp.Lte(2, "a")))
+		require.Equal(t, []int{3}, selectTupleSeqs(t, db, tp.EqValues(2, "a")))
+		require.Equal(t, []int{1, 2, 4}, selectTupleSeqs(t, db, tp.Ne(2, "a")))
+	}
+
+	tuple3 := NewTuple3(tupleTenantID, tupleOrderNo, tupleSeq).Expanded()
+	require.Equal(t, []int{2, 3, 4}, selectTupleSeqs(t, db, tuple3.GtValues(1, "b", 1)))
+}
+
+func TestColumnTuple_Dialects(t *testing.T) {
+	tuple := NewTuple2(tupleTenantID, tupleOrderNo)
+
+	t.Run("mysql", func(t *testing.T) {
+		db := tests.NewDryRunDB(t, "mysql")
+		stmt := db.Where(tuple.In(tuple.Row(1, "a"), tuple.Row(2, "b"))).Find(&[]*tupleOrder{}).Statement
+		require.Equal(t, "SELECT * FROM `orders` WHERE ((`tenant_id`, `order_no`) IN ((?, ?), (?, ?)))", stmt.SQL.String())
+		require.Equal(t, []interface{}{1, "a", 2, "b"}, stmt.Vars)
+	})
+	t.Run("sqlite", func(t *testing.T) {
+		db := tests.NewDryRunDB(t, "sqlite")
+		stmt := db.Where(tuple.GtValues(1, "a")).Find(&[]*tupleOrder{}).Statement
+		require.Equal(t, "SELECT * FROM `orders` WHERE ((`tenant_id`, `order_no`) > (?, ?))", stmt.SQL.String())
+	})
+	t.Run("sqlserver", func(t *testing.T) {
+		db := tests.NewDryRunDB(t, "sqlserver")
+		stmt := db.Where(tuple.Gte(1, "a")).Find(&[]*tupleOrder{}).Statement
+		require.Equal(t, "SELECT * FROM [orders] WHERE ((([tenant_id] > ?) OR ([tenant_id] = ? AND [order_no] >= ?)))", stmt.SQL.String())
+		require.Equal(t, []interface{}{1, 1, "a"}, stmt.Vars)
+	})
+}
@@ -8,7 +8,7 @@
 package gormcnm
 
 import (
-	"github.com/yyle88/gormcnm/internal/utils"
+	"github.com/yyle88/must"
 	"gorm.io/gorm"
 	"gorm.io/gorm/clause"
 )
@@ -19,6 +19,11 @@ import (
 // 如果列名与 SQL 关键字（例如 "create"）冲突，使用反引号将其括起来，确保正确执行。
 // Usage example: db.Select("`type`").Find(&one) demonstrates the standard pattern.
 // 使用示例：db.Select("`type`").Find(&one) 展示了标准使用模式。
+//
+// Deprecated: the quote is chosen by hand and breaks on the other databases. Wrap the condition in Qx instead,
+// e.g. db.Where(Qx(columnType.Eq("xyz"))), quoting the column in the dialect of the DB lazily, or use Quoted(dialect).
+// 已弃用：引号需要手动选择，在其他数据库中会出错。请改为将条件包装在 Qx 中，
+// 例如 db.Where(Qx(columnType.Eq("xyz")))，按数据库方言延迟给列名加引号，或使用 Quoted(dialect)。
 func (columnName ColumnName[TYPE]) SafeCnm(quote string) ColumnName[TYPE] {
 	switch len(quote) {
 	case 0: // If no quote is provided, we just add spaces around the column name.
@@ -73,14 +78,20 @@ func (columnName ColumnName[TYPE]) ExprReplace(oldValue, newValue TYPE) clause.E
 }
 
 // Ob creates an ordering clause with the specified direction (ASC or DESC) when using this column.
+// The direction is checked case-insensitively and panics on others, see Asc and Desc for the typed ordering.
 // Ob: 创建一个带有指定方向（ASC 或 DESC）的 ORDER BY 子句。
+// 方向不区分大小写地检查，其他值会 panic，类型化的排序见 Asc 和 Desc。
 func (columnName ColumnName[TYPE]) Ob(direction string) OrderByBottle {
+	must.Done(checkOrderDirection(direction))
 	return OrderByBottle(string(columnName) + " " + direction)
 }
 
 // OrderByBottle creates an ordering clause with the given direction (ASC or DESC) when using this column.
+// The direction is checked like Ob.
 // OrderByBottle: 创建一个带有给定方向（ASC 或 DESC）的 ORDER BY 子句。
+// 方向的检查与 Ob 相同。
 func (columnName ColumnName[TYPE]) OrderByBottle(direction string) OrderByBottle {
+	must.Done(checkOrderDirection(direction))
 	return OrderByBottle(string(columnName) + " " + direction)
 }
 
@@ -225,37 +236,49 @@ func (columnName ColumnName[TYPE]) KeReplace(oldValue, newValue TYPE) (string, c
 }
 
 // Count creates a COUNT queries statement on the column, excluding NULL values.
+// Shorthand of Count(columnName).AsAlias(alias), use the typed Count when comparing or ordering.
 // Count: 创建一个 COUNT 查询，只统计非 NULL 值的列。
+// 是 Count(columnName).AsAlias(alias) 的简写，需要比较或排序时使用类型化的 Count。
 func (columnName ColumnName[TYPE]) Count(alias string) string {
-	return utils.ApplyAliasToColumn("COUNT("+string(columnName)+")", alias)
+	return Count(columnName).AsAlias(alias)
 }
 
 // CountDistinct creates a COUNT DISTINCT queries statement on the given column, skipping NULL values in the count.
+// Shorthand of CountDistinct(columnName).AsAlias(alias).
 // CountDistinct: 创建一个 COUNT DISTINCT 查询，用于给定列，跳过 NULL 值。
+// 是 CountDistinct(columnName).AsAlias(alias) 的简写。
 func (columnName ColumnName[TYPE]) CountDistinct(alias string) string {
-	return utils.ApplyAliasToColumn("COUNT(DISTINCT("+string(columnName)+"))", alias)
+	return CountDistinct(columnName).AsAlias(alias)
 }
 
 // Sum creates a SUM aggregate statement on the column.
+// Shorthand of Sum(columnName).AsAlias(alias), use the typed Sum when comparing or ordering.
 // Sum: 创建一个 SUM 聚合查询，计算列值的总和。
+// 是 Sum(columnName).AsAlias(alias) 的简写，需要比较或排序时使用类型化的 Sum。
 func (columnName ColumnName[TYPE]) Sum(alias string) string {
-	return utils.ApplyAliasToColumn("SUM("+string(columnName)+")", alias)
+	return Sum(columnName).AsAlias(alias)
 }
 
 // Avg creates an AVG aggregate statement on the column.
+// Shorthand of Avg(columnName).AsAlias(alias).
 // Avg: 创建一个 AVG 聚合查询，计算列值的平均值。
+// 是 Avg(columnName).AsAlias(alias) 的简写。
 func (columnName ColumnName[TYPE]) Avg(alias string) string {
-	return utils.ApplyAliasToColumn("AVG("+string(columnName)+")", alias)
+	return Avg(columnName).AsAlias(alias)
 }
 
 // Max creates a MAX aggregate statement on the column.
+// Shorthand of Max(columnName).AsAlias(alias).
 // Max: 创建一个 MAX 聚合查询，获取列的最大值。
+// 是 Max(columnName).AsAlias(alias) 的简写。
 func (columnName ColumnName[TYPE]) Max(alias string) string {
-	return utils.ApplyAliasToColumn("MAX("+string(columnName)+")", alias)
+	return Max(columnName).AsAlias(alias)
 }
 
 // Min creates a MIN aggregate statement on the column.
+// Shorthand of Min(columnName).AsAlias(alias).
 // Min: 创建一个 MIN 聚合查询，获取列的最小值。
+// 是 Min(columnName).AsAlias(alias) 的简写。
 func (columnName ColumnName[TYPE]) Min(alias string) string {
-	return utils.ApplyAliasToColumn("MIN("+string(columnName)+")", alias)
+	return Min(columnName).AsAlias(alias)
 }
@@ -0,0 +1,325 @@
+// Package gormcnm provides SQL dialect abstraction so one set of columns works across databases
+// Auto detects the dialect from the GORM Dialector and renders quoting, booleans, NULL functions and JSON operators
+// Supports lazy rendering where the statement is spelled at build time against the DB it is applied to
+//
+// gormcnm 提供 SQL 方言抽象，使同一套列定义能在不同数据库间通用
+// 自动从 GORM Dialector 识别方言，渲染标识符引号、布尔字面量、NULL 函数和 JSON 操作符
+// 支持延迟渲染，在构建语句时根据所应用的数据库方言生成 SQL
+package gormcnm
+
+import (
+	"strings"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Dialect represents a SQL dialect, named the same as GORM Dialector.Name()
+// The zero value means an unknown dialect, which keeps the classic spelling of this package
+//
+// Dialect 表示 SQL 方言，名称与 GORM 的 Dialector.Name() 一致
+// 零值表示未知方言，会保持本包经典的 SQL 写法
+type Dialect string
+
+const (
+	DialectSQLite    Dialect = "sqlite"    // SQLite dialect // SQLite 方言
+	DialectMySQL     Dialect = "mysql"     // MySQL and MariaDB dialect // MySQL 和 MariaDB 方言
+	DialectPostgres  Dialect = "postgres"  // PostgreSQL dialect // PostgreSQL 方言
+	DialectSQLServer Dialect = "sqlserver" // SQL Server dialect // SQL Server 方言
+)
+
+// NewDialect creates a Dialect from a dialect name, accepting common aliases like "sqlite3" and "postgresql"
+// NewDialect 根据方言名称创建 Dialect，接受 "sqlite3" 和 "postgresql" 等常见别名
+func NewDialect(name string) Dialect {
+	switch strings.ToLower(strings.TrimSpace(name)) {
+	case "sqlite", "sqlite3":
+		return DialectSQLite
+	case "mysql", "mariadb", "tidb":
+		return DialectMySQL
+	case "postgres", "postgresql", "pgx", "pg":
+		return DialectPostgres
+	case "sqlserver", "mssql":
+		return DialectSQLServer
+	default:
+		return Dialect(strings.ToLower(strings.TrimSpace(name)))
+	}
+}
+
+// DialectOf returns the Dialect of the given GORM DB, derived from its Dialector
+// DialectOf 返回给定 GORM DB 的方言，根据其 Dialector 推导
+func DialectOf(db *gorm.DB) Dialect {
+	if db == nil || db.Dialector == nil {
+		return ""
+	}
+	return NewDialect(db.Dialector.Name())
+}
+
+// dialectOfBuilder returns the Dialect of the GORM statement being built, or the zero Dialect when unknown
+// dialectOfBuilder 返回正在构建的 GORM 语句的方言，无法识别时返回零值
+func dialectOfBuilder(builder clause.Builder) Dialect {
+	if stmt, ok := builder.(*gorm.Statement); ok && stmt.DB != nil {
+		return DialectOf(stmt.DB)
+	}
+	return ""
+}
+
+// Quote quotes the identifier, quoting each part of "table.column" on its own
+// The zero Dialect keeps the identifier unquoted, the classic spelling of this package
+//
+// Quote 给标识符加引号，"table.column" 形式时分别给每个部分加引号
+// 零值方言不给标识符加引号，保持本包经典的写法
+func (dialect Dialect) Quote(name string) string {
+	if dialect == "" {
+		return name
+	}
+	parts := strings.Split(name, ".")
+	for idx, part := range parts {
+		if part == "*" {
+			continue
+		}
+		parts[idx] = dialect.quotePart(part)
+	}
+	return strings.Join(parts, ".")
+}
+
+// quoteColumn quotes the plain (optionally table qualified) column name, leaving the expressions such as "MAX(rank)" as they are
+// quoteColumn 给普通列名（可带表名前缀）加引号，表达式（例如 "MAX(rank)"）保持不变
+func (dialect Dialect) quoteColumn(name string) string {
+	if !regexpOrderColumn.MatchString(name) {
+		return name
+	}
+	return dialect.Quote(name)
+}
+
+// quotePart quotes one part of the identifier, escaping the closing quote character inside the name
+// quotePart 给标识符的单个部分加引号，并转义名称中的结束引号
+func (dialect Dialect) quotePart(part string) string {
+	switch dialect {
+	case DialectSQLite, DialectMySQL:
+		return "`" + strings.ReplaceAll(part, "`", "``") + "`"
+	case DialectSQLServer:
+		return "[" + strings.ReplaceAll(part, "]", "]]") + "]"
+	default:
+		return `"` + strings.ReplaceAll(part, `"`, `""`) + `"`
+	}
+}
+
+// BoolLiteral returns the boolean literal, SQL Server has no TRUE/FALSE keywords and uses 1/0
+// BoolLiteral 返回布尔字面量，SQL Server 没有 TRUE/FALSE 关键字，使用 1/0
+func (dialect Dialect) BoolLiteral(value bool) string {
+	if dialect == DialectSQLServer {
+		if value {
+			return "1"
+		}
+		return "0"
+	}
+	if value {
+		return "TRUE"
+	}
+	return "FALSE"
+}
+
+// IsTrue returns the statement checking the column is TRUE
+// IsTrue 返回判断列为 TRUE 的语句
+func (dialect Dialect) IsTrue(column string) string {
+	if dialect == DialectSQLServer {
+		return column + " = " + dialect.BoolLiteral(true)
+	}
+	return column + " IS TRUE"
+}
+
+// IsFalse returns the statement checking the column is FALSE
+// IsFalse 返回判断列为 FALSE 的语句
+func (dialect Dialect) IsFalse(column string) string {
+	if dialect == DialectSQLServer {
+		return column + " = " + dialect.BoolLiteral(false)
+	}
+	return column + " IS FALSE"
+}
+
+// IfNullFunc returns the two-argument NULL replacing function name
+// MySQL and SQLite use IFNULL, SQL Server uses ISNULL, the others use the standard COALESCE
+//
+// IfNullFunc 返回两个参数的 NULL 替换函数名
+// MySQL 和 SQLite 使用 IFNULL，SQL Server 使用 ISNULL，其他使用标准的 COALESCE
+func (dialect Dialect) IfNullFunc() string {
+	switch dialect {
+	case DialectMySQL, DialectSQLite:
+		return "IFNULL"
+	case DialectSQLServer:
+		return "ISNULL"
+	default:
+		return "COALESCE"
+	}
+}
+
+// NullFunc maps the NULL function name to one the dialect supports
+// IFNULL is translated with IfNullFunc, other names such as COALESCE are kept
+//
+// NullFunc 将 NULL 函数名映射为方言支持的函数名
+// IFNULL 会通过 IfNullFunc 转换，COALESCE 等其他名称保持不变
+func (dialect Dialect) NullFunc(method string) string {
+	if strings.EqualFold(method, "IFNULL") {
+		return dialect.IfNullFunc()
+	}
+	return method
+}
+
+// JSONGet returns the expression extracting the JSON value at the path as text
+// JSONGet 返回按路径将 JSON 值提取为文本的表达式
+func (dialect Dialect) JSONGet(column string, path string) string {
+	switch dialect {
+	case DialectPostgres:
+		return column + "::jsonb #>> " + dialect.JSONPathLiteral(path)
+	case DialectSQLServer:
+		return "JSON_VALUE(" + column + ", " + dialect.JSONPathLiteral(path) + ")"
+	default:
+		return column + " ->> " + dialect.JSONPathLiteral(path)
+	}
+}
+
+// JSONExtract returns the expression extracting the JSON sub-document at the path
+// JSONExtract 返回按路径提取 JSON 子文档的表达式
+func (dialect Dialect) JSONExtract(column string, path string) string {
+	switch dialect {
+	case DialectPostgres:
+		return column + "::jsonb #> " + dialect.JSONPathLiteral(path)
+	case DialectSQLServer:
+		return "JSON_QUERY(" + column + ", " + dialect.JSONPathLiteral(path) + ")"
+	default:
+		return column + " -> " + dialect.JSONPathLiteral(path)
+	}
+}
+
+// JSONPathLiteral returns the path like "a.b[0]" as the quoted JSON path literal, '{a,b,0}' in PostgreSQL and '$.a.b[0]' in the others
+// The path is escaped with StringLiteral, thus a quote inside the path cannot break out of the literal
+//
+// JSONPathLiteral 将 "a.b[0]" 形式的路径转换为加引号的 JSON 路径字面量，PostgreSQL 中为 '{a,b,0}'，其他为 '$.a.b[0]'
+// 路径会通过 StringLiteral 转义，因此路径中的引号无法跳出字面量
+func (dialect Dialect) JSONPathLiteral(path string) string {
+	if dialect == DialectPostgres {
+		return dialect.StringLiteral(jsonPathKeys(path))
+	}
+	return dialect.StringLiteral(jsonPath(path))
+}
+
+// StringLiteral returns the text as a quoted SQL string literal, doubling the single quotes inside
+// MySQL treats backslash as an escape character in the literals, thus the backslashes are doubled too
+//
+// StringLiteral 将文本转换为加引号的 SQL 字符串字面量，并双写其中的单引号
+// MySQL 在字面量中把反斜杠当作转义字符，因此反斜杠也会被双写
+func (dialect Dialect) StringLiteral(text string) string {
+	if dialect == DialectMySQL {
+		text = strings.ReplaceAll(text, `\`, `\\`)
+	}
+	return "'" + strings.ReplaceAll(text, "'", "''") + "'"
+}
+
+// CastInteger returns the expression casting the value to an integer
+// CastInteger 返回将值转换为整数的表达式
+func (dialect Dialect) CastInteger(expr string) string {
+	switch dialect {
+	case DialectMySQL:
+		return "CAST(" + expr + " AS SIGNED)"
+	case DialectSQLServer:
+		return "CAST(" + expr + " AS INT)"
+	default:
+		return "CAST(" + expr + " AS INTEGER)"
+	}
+}
+
+// EscapeLike escapes the LIKE wildcards in the value with backslash, thus the value matches literally
+// SQL Server treats "[" as a wildcard too, so it is escaped in that dialect
+//
+// EscapeLike 使用反斜杠转义值中的 LIKE 通配符，使值按字面匹配
+// SQL Server 还会把 "[" 当作通配符，因此在该方言中也会转义
+func (dialect Dialect) EscapeLike(value string) string {
+	var specials = `\%_`
+	if dialect == DialectSQLServer {
+		specials += "["
+	}
+	var sb strings.Builder
+	for _, c := range value {
+		if strings.ContainsRune(specials, c) {
+			sb.WriteByte('\\')
+		}
+		sb.WriteRune(c)
+	}
+	return sb.String()
+}
+
+// LikeEscape returns the ESCAPE clause matching EscapeLike, MySQL uses backslash by default thus needs none
+// LikeEscape 返回与 EscapeLike 配套的 ESCAPE 子句，MySQL 默认使用反斜杠转义因此不需要
+func (dialect Dialect) LikeEscape() string {
+	if dialect == DialectMySQL {
+		return ""
+	}
+	return ` ESCAPE '\'`
+}
+
+// jsonPath converts a dotted path like "a.b" into the JSON path "$.a.b"
+// jsonPath 将 "a.b" 形式的路径转换为 JSON 路径 "$.a.b"
+func jsonPath(path string) string {
+	if path == "" {
+		return "$"
+	}
+	if strings.HasPrefix(path, "[") {
+		return "$" + path
+	}
+	return "$." + path
+}
+
+// jsonPathKeys converts a dotted path like "a.b[0]" into the PostgreSQL path array "{a,b,0}"
+// jsonPathKeys 将 "a.b[0]" 形式的路径转换为 PostgreSQL 的路径数组 "{a,b,0}"
+func jsonPathKeys(path string) string {
+	keys := strings.FieldsFunc(path, func(c rune) bool {
+		return c == '.' || c == '[' || c == ']'
+	})
+	return "{" + strings.Join(keys, ",") + "}"
+}
+
+// DialectExpression renders SQL lazily, against the dialect of the DB it is applied to
+// It implements clause.Expression, thus the SQL is spelled when GORM builds the statement
+//
+// DialectExpression 延迟渲染 SQL，根据所应用的数据库方言生成语句
+// 它实现了 clause.Expression 接口，因此在 GORM 构建语句时才生成 SQL
+type DialectExpression struct {
+	render func(dialect Dialect) (string, []interface{}) // Renders statement and arguments in the dialect // 按方言渲染语句和参数
+}
+
+// NewDialectExpression creates a new DialectExpression with the render function
+// NewDialectExpression 使用渲染函数创建一个新的 DialectExpression
+func NewDialectExpression(render func(dialect Dialect) (string, []interface{})) *DialectExpression {
+	return &DialectExpression{render: render}
+}
+
+// Render returns the statement and arguments spelled in the given dialect
+// Render 返回按给定方言生成的语句和参数
+func (dx *DialectExpression) Render(dialect Dialect) (string, []interface{}) {
+	return dx.render(dialect)
+}
+
+// Build implements clause.Expression, rendering in the dialect of the GORM statement
+// Named arguments in the rendered statement are bound through clause.NamedExpr
+//
+// Build 实现 clause.Expression 接口，按 GORM 语句的方言渲染
+// 渲染后语句中的命名参数通过 clause.NamedExpr 绑定
+func (dx *DialectExpression) Build(builder clause.Builder) {
+	stmt, args := dx.render(dialectOfBuilder(builder))
+	newStatementArgumentsTuple(stmt, args).expression().Build(builder)
+}
+
+// NewDialectQx creates a QxConjunction whose statement is rendered lazily in the dialect of the DB
+// The statement shows as a "?" placeholder in Qs(), and GORM renders the real SQL when building
+//
+// NewDialectQx 创建一个 QxConjunction，其语句根据数据库方言延迟渲染
+// 在 Qs() 中语句显示为 "?" 占位符，由 GORM 在构建时渲染真正的 SQL
+func NewDialectQx(render func(dialect Dialect) (string, []interface{})) *QxConjunction {
+	return NewQxConjunction("?", NewDialectExpression(render))
+}
+
+// NewDialectSx creates a SelectStatement whose statement is rendered lazily in the dialect of the DB
+// NewDialectSx 创建一个 SelectStatement，其语句根据数据库方言延迟渲染
+func NewDialectSx(render func(dialect Dialect) (string, []interface{})) *SelectStatement {
+	return NewSelectStatement("?", NewDialectExpression(render))
+}
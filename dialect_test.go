@@ -0,0 +1,128 @@
+// Package gormcnm tests validate the SQL dialect abstraction and lazy dialect rendering
+// Auto verifies quoting, boolean literals, NULL functions and JSON operators in each dialect
+// Tests examine lazy expressions built against SQLite and DryRun databases of other dialects
+//
+// gormcnm 测试包验证 SQL 方言抽象和延迟方言渲染
+// 自动验证各方言的引号、布尔字面量、NULL 函数和 JSON 操作符
+// 测试涵盖在 SQLite 和其他方言的 DryRun 数据库上构建的延迟表达式
+package gormcnm
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/yyle88/gormcnm/internal/tests"
+	"gorm.io/gorm"
+)
+
+func TestNewDialect(t *testing.T) {
+	require.Equal(t, DialectSQLite, NewDialect("sqlite3"))
+	require.Equal(t, DialectMySQL, NewDialect("MySQL"))
+	require.Equal(t, DialectPostgres, NewDialect("postgresql"))
+	require.Equal(t, DialectSQLServer, NewDialect("mssql"))
+	require.Equal(t, Dialect("oracle"), NewDialect("oracle"))
+}
+
+func TestDialectOf(t *testing.T) {
+	tests.NewDBRun(t, func(db *gorm.DB) {
+		require.Equal(t, DialectSQLite, DialectOf(db))
+	})
+	require.Equal(t, DialectMySQL, DialectOf(tests.NewDryRunDB(t, "mysql")))
+	require.Equal(t, Dialect(""), DialectOf(nil))
+}
+
+func TestDialect_Quote(t *testing.T) {
+	require.Equal(t, "`type`", DialectMySQL.Quote("type"))
+	require.Equal(t, "`users`.`type`", DialectSQLite.Quote("users.type"))
+	require.Equal(t, `"users"."type"`, DialectPostgres.Quote("users.type"))
+	require.Equal(t, "[users].[type]", DialectSQLServer.Quote("users.type"))
+	require.Equal(t, `"users".*`, DialectPostgres.Quote("users.*"))
+	require.Equal(t, "users.type", Dialect("").Quote("users.type"))
+	require.Equal(t, "`a``b`", DialectMySQL.Quote("a`b"))
+}
+
+func TestDialect_BoolLiteral(t *testing.T) {
+	require.Equal(t, "TRUE", DialectMySQL.BoolLiteral(true))
+	require.Equal(t, "FALSE", DialectPostgres.BoolLiteral(false))
+	require.Equal(t, "1", DialectSQLServer.BoolLiteral(true))
+	require.Equal(t, "flag IS TRUE", DialectSQLite.IsTrue("flag"))
+	require.Equal(t, "flag = 0", DialectSQLServer.IsFalse("flag"))
+}
+
+func TestDialect_IfNullFunc(t *testing.T) {
+	require.Equal(t, "IFNULL", DialectMySQL.IfNullFunc())
+	require.Equal(t, "IFNULL", DialectSQLite.IfNullFunc())
+	require.Equal(t, "COALESCE", DialectPostgres.IfNullFunc())
+	require.Equal(t, "ISNULL", DialectSQLServer.IfNullFunc())
+	require.Equal(t, "ISNULL", DialectSQLServer.NullFunc("IFNULL"))
+	require.Equal(t, "COALESCE", DialectSQLServer.NullFunc("COALESCE"))
+}
+
+func TestDialect_JSONGet(t *testing.T) {
+	require.Equal(t, "meta ->> '$.brand'", DialectSQLite.JSONGet("meta", "brand"))
+	require.Equal(t, "meta ->> '$.a.b'", DialectMySQL.JSONGet("meta", "a.b"))
+	require.Equal(t, "meta::jsonb #>> '{a,b,0}'", DialectPostgres.JSONGet("meta", "a.b[0]"))
+	require.Equal(t, "JSON_VALUE(meta, '$.brand')", DialectSQLServer.JSONGet("meta", "brand"))
+	require.Equal(t, "meta -> '$.a'", Dialect("").JSONExtract("meta", "a"))
+	require.Equal(t, "JSON_QUERY(meta, '$.a')", DialectSQLServer.JSONExtract("meta", "a"))
+	require.Equal(t, "CAST(x AS SIGNED)", DialectMySQL.CastInteger("x"))
+	require.Equal(t, `meta ->> '$.a''); DROP TABLE x; --'`, DialectSQLite.JSONGet("meta", "a'); DROP TABLE x; --"))
+	require.Equal(t, `'{a'',b}'`, DialectPostgres.JSONPathLiteral("a'.b"))
+	require.Equal(t, `'$.a\\'' OR 1=1'`, DialectMySQL.JSONPathLiteral(`a\' OR 1=1`))
+}
+
+func TestDialect_EscapeLike(t *testing.T) {
+	require.Equal(t, `50\%\_a\\b[c]`, DialectSQLite.EscapeLike(`50%_a\b[c]`))
+	require.Equal(t, `50\%\_a\\b\[c]`, DialectSQLServer.EscapeLike(`50%_a\b[c]`))
+	require.Equal(t, "", DialectMySQL.LikeEscape())
+	require.Equal(t, ` ESCAPE '\'`, DialectPostgres.LikeEscape())
+}
+
+func TestNewDialectQx(t *testing.T) {
+	type Example struct {
+		Name string `gorm:"primary_key;type:varchar(100);"`
+		Type string `gorm:"column:type;"`
+	}
+
+	qx := NewDialectQx(func(dialect Dialect) (string, []interface{}) {
+		return dialect.Quote("type") + " = ?", []interface{}{"xyz"}
+	})
+
+	t.Run("mysql", func(t *testing.T) {
+		db := tests.NewDryRunDB(t, "mysql")
+		stmt := db.Where(qx).Find(&[]*Example{}).Statement
+		require.Equal(t, "SELECT * FROM `examples` WHERE (`type` = ?)", stmt.SQL.String())
+		require.Equal(t, []interface{}{"xyz"}, stmt.Vars)
+	})
+	t.Run("postgres", func(t *testing.T) {
+		db := tests.NewDryRunDB(t, "postgres")
+		stmt := db.Where(qx.AND(Qx(ColumnName[string]("name").Eq("abc")))).Find(&[]*Example{}).Statement
+		require.Equal(t, `SELECT * FROM "examples" WHERE (("type" = $1) AND "name"=$2)`, stmt.SQL.String())
+		require.Equal(t, []interface{}{"xyz", "abc"}, stmt.Vars)
+	})
+	t.Run("sqlite", func(t *testing.T) {
+		tests.NewDBRun(t, func(db *gorm.DB) {
+			require.NoError(t, db.AutoMigrate(&Example{}))
+			require.NoError(t, db.Save(&Example{Name: "abc", Type: "xyz"}).Error)
+			require.NoError(t, db.Save(&Example{Name: "aaa", Type: "xxx"}).Error)
+
+			var one Example
+			require.NoError(t, db.Where(qx.Qx1()).First(&one).Error)
+			require.Equal(t, "abc", one.Name)
+		})
+	})
+}
+
+func TestNewDialectSx(t *testing.T) {
+	type Example struct {
+		Name string `gorm:"primary_key;type:varchar(100);"`
+	}
+
+	sx := NewDialectSx(func(dialect Dialect) (string, []interface{}) {
+		return dialect.Quote("name") + " as who", nil
+	})
+
+	db := tests.NewDryRunDB(t, "sqlserver")
+	stmt := db.Model(&Example{}).Clauses(sx).Find(&[]map[string]interface{}{}).Statement
+	require.Equal(t, "SELECT [name] as who FROM [examples]", stmt.SQL.String())
+}
@@ -0,0 +1,235 @@
+// Package gormcnm provides debug rendering of conditions, selects, orders and updates as final SQL
+// Auto builds the statements with the DB's dialect quoting and interpolates the arguments via the Dialector's Explain
+// Supports redacting the arguments of selected columns, so that logs do not leak personal data
+//
+// gormcnm 提供将条件、选择、排序和更新渲染为最终 SQL 的调试功能
+// 自动使用数据库方言的引号构建语句，并通过 Dialector 的 Explain 插入参数值
+// 支持隐藏指定列的参数值，避免日志泄露个人数据
+package gormcnm
+
+import (
+	"database/sql"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/yyle88/gormcnm/internal/utils"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// redactedValue replaces the redacted arguments in the rendered SQL
+// redactedValue 在渲染的 SQL 中替换被隐藏的参数
+const redactedValue = "[REDACTED]"
+
+// explainConfig holds the options of the debug rendering
+// explainConfig 保存调试渲染的选项
+type explainConfig struct {
+	redacted map[string]bool // Columns whose arguments are redacted, by the unqualified name // 需要隐藏参数的列，以不带表名的列名为键
+}
+
+// isRedacted tells whether the arguments of the column are redacted, matching the column without the table name
+// isRedacted 判断该列的参数是否需要隐藏，匹配时忽略表名
+func (config *explainConfig) isRedacted(column string) bool {
+	return config.redacted[unqualifiedColumn(column)]
+}
+
+// unqualifiedColumn returns the column name without the table name, e.g. "name" of "users.name"
+// unqualifiedColumn 返回不带表名的列名，例如 "users.name" 返回 "name"
+func unqualifiedColumn(column string) string {
+	if idx := strings.LastIndexByte(column, '.'); idx >= 0 {
+		return column[idx+1:]
+	}
+	return column
+}
+
+// ExplainOption configures the debug rendering of ToSQL
+// ExplainOption 配置 ToSQL 的调试渲染
+type ExplainOption func(config *explainConfig)
+
+// RedactColumns redacts the arguments compared with (or assigned to) the columns, e.g. RedactColumns(cls.Password, cls.Phone).
+// The columns match without the table name, thus RedactColumns(cls.Password) also redacts "users.password" and the other way round.
+// In conditions the leaves describing a column (see QxPredicate) are redacted by column, while the raw statements
+// have all their arguments redacted, since the columns of their arguments are unknown. The same goes for the select columns.
+//
+// RedactColumns 隐藏与指定列比较（或赋值给指定列）的参数，例如 RedactColumns(cls.Password, cls.Phone)。
+// 列名匹配时忽略表名，因此 RedactColumns(cls.Password) 同样会隐藏 "users.password"，反之亦然。
+// 在条件中描述了列的叶子节点（见 QxPredicate）按列隐藏，而原始语句由于无法得知参数对应的列，会隐藏其全部参数。
+// 选择的列同理。
+func RedactColumns(columns ...utils.ColumnNameInterface) ExplainOption {
+	return func(config *explainConfig) {
+		for _, column := range columns {
+			config.redacted[unqualifiedColumn(column.Name())] = true
+		}
+	}
+}
+
+// newExplainConfig creates the explainConfig with the options
+// newExplainConfig 使用选项创建 explainConfig
+func newExplainConfig(options []ExplainOption) *explainConfig {
+	var config = &explainConfig{redacted: map[string]bool{}}
+	for _, option := range options {
+		option(config)
+	}
+	return config
+}
+
+// Explain renders the expression as SQL in the dialect of the DB, with the arguments interpolated.
+// The SQL is for debugging and logging only, never execute it, as the interpolation is not injection safe.
+//
+// Explain 按数据库方言将表达式渲染为 SQL，并插入参数值。
+// 该 SQL 仅用于调试和日志，不要执行它，因为插值的方式不能防止注入。
+func Explain(db *gorm.DB, expression clause.Expression) string {
+	return explainWith(db, func(stmt *gorm.Statement) {
+		expression.Build(stmt)
+	})
+}
+
+// explainWith builds with a new statement on a dry-run session of the DB, then interpolates the arguments with the Dialector
+// The DB itself is left untouched, errors found when building go to the session
+//
+// explainWith 在 DB 的 dry-run 会话上使用新的语句构建，然后通过 Dialector 插入参数值
+// DB 本身不会被修改，构建时发现的错误会记录在会话中
+func explainWith(db *gorm.DB, build func(stmt *gorm.Statement)) string {
+	tx := db.Session(&gorm.Session{NewDB: true, DryRun: true})
+	stmt := &gorm.Statement{DB: tx, Context: tx.Statement.Context, Clauses: map[string]clause.Clause{}}
+	build(stmt)
+	return db.Dialector.Explain(stmt.SQL.String(), stmt.Vars...)
+}
+
+// ToSQL renders the condition as SQL in the dialect of the DB with the arguments interpolated, e.g. "`name`='abc' AND `rank`>1".
+// The columns of the typed conditions are quoted in the dialect of the DB, like Scope and Build. The empty condition gives "".
+//
+// ToSQL 按数据库方言将条件渲染为插入参数值的 SQL，例如 "`name`='abc' AND `rank`>1"。
+// 类型化条件的列名按数据库方言加引号，与 Scope 和 Build 相同。空条件返回 ""。
+func (qx *QxConjunction) ToSQL(db *gorm.DB, options ...ExplainOption) string {
+	if qx.IsEmpty() {
+		return ""
+	}
+	var config = newExplainConfig(options)
+	if len(config.redacted) > 0 {
+		qx = newQxFromNode(redactNode(qx.Node(), config), nil)
+	}
+	return Explain(db, qx.renderIn(DialectOf(db)).expression())
+}
+
+// ToSQL renders the select columns as SQL in the dialect of the DB with the arguments interpolated.
+// With RedactColumns all the arguments are redacted, since the columns of the select arguments are unknown.
+//
+// ToSQL 按数据库方言将选择的列渲染为插入参数值的 SQL。
+// 使用 RedactColumns 时会隐藏全部参数，因为无法得知选择参数对应的列。
+func (sx *SelectStatement) ToSQL(db *gorm.DB, options ...ExplainOption) string {
+	var config = newExplainConfig(options)
+	if len(config.redacted) > 0 {
+		return Explain(db, newStatementArgumentsTuple(sx.Qs(), redactArguments(sx.Args())).expression())
+	}
+	return Explain(db, sx.expression())
+}
+
+// regexpOrderColumn matches a plain (optionally table qualified) column name in the ORDER BY items
+// regexpOrderColumn 匹配 ORDER BY 项中的普通列名（可带表名前缀）
+var regexpOrderColumn = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*(\.[A-Za-z_][A-Za-z0-9_]*)?$`)
+
+// ToSQL renders the ordering as SQL, quoting the plain column names in the dialect of the DB, e.g. "`name` asc, `rank` desc".
+// ToSQL 将排序渲染为 SQL，并按数据库方言给普通列名加引号，例如 "`name` asc, `rank` desc"。
+func (ob OrderByBottle) ToSQL(db *gorm.DB) string {
+	return explainWith(db, func(stmt *gorm.Statement) {
+		for idx, item := range strings.Split(string(ob), ",") {
+			if idx > 0 {
+				stmt.WriteString(", ")
+			}
+			fields := strings.Fields(item)
+			if len(fields) > 0 && regexpOrderColumn.MatchString(fields[0]) && isOrderModifiers(fields[1:]) {
+				stmt.QuoteTo(stmt, fields[0])
+				for _, field := range fields[1:] {
+					stmt.WriteString(" " + field)
+				}
+			} else {
+				stmt.WriteString(strings.TrimSpace(item))
+			}
+		}
+	})
+}
+
+// isOrderModifiers tells whether the words are the modifiers after the column of an ORDER BY item
+// isOrderModifiers 判断这些单词是否为 ORDER BY 项中列名后面的修饰词
+func isOrderModifiers(words []string) bool {
+	for _, word := range words {
+		switch strings.ToUpper(word) {
+		case "ASC", "DESC", "NULLS", "FIRST", "LAST":
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// ToSQL renders the assignments as SQL in the dialect of the DB with the values interpolated, sorted by column, e.g. "`name`='abc', `rank`=1".
+// ToSQL 按数据库方言将赋值渲染为插入参数值的 SQL，按列名排序，例如 "`name`='abc', `rank`=1"。
+func (mp ColumnValueMap) ToSQL(db *gorm.DB, options ...ExplainOption) string {
+	var config = newExplainConfig(options)
+	var columns = make([]string, 0, len(mp))
+	for column := range mp {
+		columns = append(columns, column)
+	}
+	sort.Strings(columns)
+	return explainWith(db, func(stmt *gorm.Statement) {
+		for idx, column := range columns {
+			if idx > 0 {
+				stmt.WriteString(", ")
+			}
+			stmt.WriteQuoted(column)
+			stmt.WriteByte('=')
+			if config.isRedacted(column) {
+				stmt.AddVar(stmt, redactedValue)
+			} else {
+				stmt.AddVar(stmt, mp[column])
+			}
+		}
+	})
+}
+
+// redactNode returns a copy of the tree, with the arguments of the leaves on the redacted columns replaced
+// The leaves without a predicate have all their arguments replaced, since the columns of the arguments are unknown
+//
+// redactNode 返回条件树的副本，其中涉及被隐藏列的叶子节点的参数会被替换
+// 没有谓词的叶子节点会替换全部参数，因为无法得知参数对应的列
+func redactNode(node *QxNode, config *explainConfig) *QxNode {
+	if node.Kind == QxKindLeaf {
+		if len(node.Args) == 0 || (node.Predicate != nil && !config.isRedacted(node.Predicate.Column)) {
+			return node
+		}
+		return newLeafNode(node.Stmt, redactArguments(node.Args), node.Predicate)
+	}
+	var children = make([]*QxNode, 0, len(node.Children))
+	for _, child := range node.Children {
+		children = append(children, redactNode(child, config))
+	}
+	return &QxNode{Kind: node.Kind, Children: children}
+}
+
+// redactArguments replaces the argument values, keeping the names of the named arguments and the lazy rendering
+// redactArguments 替换参数值，保留命名参数的名称以及延迟渲染
+func redactArguments(args []interface{}) []interface{} {
+	var results = make([]interface{}, 0, len(args))
+	for _, arg := range args {
+		switch value := arg.(type) {
+		case sql.NamedArg:
+			results = append(results, sql.Named(value.Name, redactedValue))
+		case map[string]interface{}:
+			var mp = make(map[string]interface{}, len(value))
+			for name := range value {
+				mp[name] = redactedValue
+			}
+			results = append(results, mp)
+		case *DialectExpression:
+			results = append(results, NewDialectExpression(func(dialect Dialect) (string, []interface{}) {
+				stmt, args := value.Render(dialect)
+				return stmt, redactArguments(args)
+			}))
+		default:
+			results = append(results, redactedValue)
+		}
+	}
+	return results
+}
@@ -0,0 +1,104 @@
+// Package gormcnm tests validate the debug rendering of conditions, selects, orders and updates
+// Auto verifies the dialect quoting, the interpolated arguments and the redacted columns
+// Tests examine dry-run DBs of several dialects
+//
+// gormcnm 测试包验证条件、选择、排序和更新的调试渲染
+// 自动验证方言引号、插入的参数值以及被隐藏的列
+// 测试涵盖多种方言的 dry-run DB
+package gormcnm
+
+import (
+	"database/sql"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/yyle88/gormcnm/internal/tests"
+	"gorm.io/gorm"
+)
+
+func TestQxConjunction_ToSQL(t *testing.T) {
+	const (
+		columnName  = ColumnName[string]("name")
+		columnType  = ColumnName[string]("type")
+		columnRank  = ColumnName[int]("rank")
+		columnEmail = ColumnName[string]("email")
+	)
+
+	qx := Qx(columnName.Eq("abc")).AND(Qx(columnType.Eq("xyz")), Qx(columnRank.In([]int{1, 2})).OR(Qx(columnEmail.Eq("a@b.com"))))
+
+	t.Run("mysql", func(t *testing.T) {
+		db := tests.NewDryRunDB(t, "mysql")
+		require.Equal(t, "`name`='abc' AND `type`='xyz' AND (`rank` IN(1,2) OR `email`='a@b.com')", qx.ToSQL(db))
+		require.Equal(t, "`name`='abc' AND `type`='[REDACTED]' AND (`rank` IN(1,2) OR `email`='[REDACTED]')", qx.ToSQL(db, RedactColumns(columnType, columnEmail)))
+		require.Equal(t, "", NewEmptyQx().ToSQL(db))
+	})
+
+	t.Run("postgres", func(t *testing.T) {
+		db := tests.NewDryRunDB(t, "postgres")
+		require.Equal(t, `"name"='abc' AND "type"='xyz' AND ("rank" IN(1,2) OR "email"='a@b.com')`, qx.ToSQL(db))
+	})
+
+	t.Run("named", func(t *testing.T) {
+		db := tests.NewDryRunDB(t, "sqlite")
+		named := Qx("email = @email", sql.Named("email", "a@b.com")).withPredicate(&QxPredicate{Column: columnEmail.Name(), Op: "="})
+		require.Equal(t, "`rank`>1 AND email = 'a@b.com'", Qx(columnRank.Gt(1)).AND(named).ToSQL(db))
+		require.Equal(t, "`rank`>1 AND email = '[REDACTED]'", Qx(columnRank.Gt(1)).AND(named).ToSQL(db, RedactColumns(columnEmail)))
+	})
+
+	t.Run("raw", func(t *testing.T) {
+		db := tests.NewDryRunDB(t, "sqlite")
+		raw := Qx(columnRank.Gt(1)).AND(Qx("lower(email) = ? OR phone = ?", "a@b.com", "123"))
+		require.Equal(t, "`rank`>1 AND (lower(email) = 'a@b.com' OR phone = '123')", raw.ToSQL(db))
+		require.Equal(t, "`rank`>1 AND (lower(email) = '[REDACTED]' OR phone = '[REDACTED]')", raw.ToSQL(db, RedactColumns(columnEmail)))
+	})
+
+	t.Run("qualified", func(t *testing.T) {
+		db := tests.NewDryRunDB(t, "mysql")
+		qualified := Qx(ColumnName[string]("zz_users.name").Eq("secret")).AND(Qx(columnType.Eq("xyz")))
+		require.Equal(t, "`zz_users`.`name`='[REDACTED]' AND `type`='xyz'", qualified.ToSQL(db, RedactColumns(columnName)))
+		require.Equal(t, "`name`='abc' AND `type`='[REDACTED]' AND (`rank` IN(1,2) OR `email`='a@b.com')", qx.ToSQL(db, RedactColumns(ColumnName[string]("zz_users.type"))))
+	})
+
+	t.Run("unchanged", func(t *testing.T) {
+		db := tests.NewDryRunDB(t, "mysql")
+		qx.ToSQL(db, RedactColumns(columnName))
+		require.Equal(t, []interface{}{"abc"}, qx.Node().Children[0].Args)
+		require.NoError(t, db.Error)
+	})
+}
+
+func TestSelectStatement_ToSQL(t *testing.T) {
+	const columnName = ColumnName[string]("name")
+
+	db := tests.NewDryRunDB(t, "mysql")
+	sx := columnName.AsAlias("n")
+	cnt := NewSx(sx).Combine(Case[int]().When(Qx(columnName.Eq("abc")), 1).Count("cnt"))
+	require.Equal(t, "name as n, COUNT(CASE WHEN (`name`='abc') THEN 1 END) as cnt", cnt.ToSQL(db))
+	require.Equal(t, "name as n, COUNT(CASE WHEN (`name`='[REDACTED]') THEN '[REDACTED]' END) as cnt", cnt.ToSQL(db, RedactColumns(columnName)))
+}
+
+func TestOrderByBottle_ToSQL(t *testing.T) {
+	const (
+		columnName = ColumnName[string]("name")
+		columnRank = ColumnName[int]("rank")
+	)
+
+	ob := columnName.Ob("asc").Ob(columnRank.Ob("desc")).Ob("COALESCE(a.type, b.type) DESC")
+	require.Equal(t, "`name` asc, `rank` desc, COALESCE(a.type, b.type) DESC", ob.ToSQL(tests.NewDryRunDB(t, "mysql")))
+	require.Equal(t, `"name" asc, "rank" desc, COALESCE(a.type, b.type) DESC`, ob.ToSQL(tests.NewDryRunDB(t, "postgres")))
+	require.Equal(t, `"a"."name" DESC NULLS LAST`, OrderByBottle("a.name DESC NULLS LAST").ToSQL(tests.NewDryRunDB(t, "postgres")))
+}
+
+func TestColumnValueMap_ToSQL(t *testing.T) {
+	const (
+		columnName     = ColumnName[string]("name")
+		columnPassword = ColumnName[string]("password")
+		columnRank     = ColumnName[int]("rank")
+	)
+
+	mp := Kw(columnName.Kv("abc")).Kw(columnPassword.Kv("secret")).Kw(columnRank.Kv(1)).Kw(columnRank.KeAdd(1))
+	tests.NewDBRun(t, func(db *gorm.DB) {
+		require.Equal(t, "`name`=\"abc\", `password`=\"[REDACTED]\", `rank`=rank + 1", mp.ToSQL(db, RedactColumns(columnPassword)))
+		require.Equal(t, "`name`=\"abc\", `password`=\"[REDACTED]\", `rank`=rank + 1", mp.ToSQL(db, RedactColumns(ColumnName[string]("zz_users.password"))))
+	})
+}
@@ -0,0 +1,216 @@
+// Package gormcnm provides the stable JSON representation of QxConjunction trees, e.g. to store saved searches
+// Auto encodes the leaves from their predicates as {"column", "op", "value"} and the nesting as {"and"}, {"or"}, {"not"}
+// Supports parsing the JSON back only against a FilterRegistry, thus outside input never reaches the SQL unchecked
+//
+// gormcnm 提供 QxConjunction 条件树的稳定 JSON 表示，例如用于保存搜索条件
+// 自动根据叶子节点的谓词编码为 {"column", "op", "value"}，嵌套结构编码为 {"and"}、{"or"}、{"not"}
+// 支持仅依据 FilterRegistry 将 JSON 解析回条件，外部输入不会未经检查就进入 SQL
+package gormcnm
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"strconv"
+
+	"github.com/pkg/errors"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// FilterNode is the JSON representation of a condition tree node, exactly one of the leaf, "and", "or" and "not" is set.
+// The value is the JSON of the column type, a list for "in", a [low, high] pair for "between", absent for "is_null".
+// The empty condition is the empty object {}, e.g.
+//
+//	{"and":[{"column":"name","op":"contains","value":"abc"},{"not":{"column":"rank","op":"in","value":[1,2]}}]}
+//
+// FilterNode 是条件树节点的 JSON 表示，叶子、"and"、"or"、"not" 恰好设置其中之一。
+// 值为列类型的 JSON，"in" 为列表，"between" 为 [low, high] 对，"is_null" 没有值。
+// 空条件为空对象 {}，例如
+//
+//	{"and":[{"column":"name","op":"contains","value":"abc"},{"not":{"column":"rank","op":"in","value":[1,2]}}]}
+type FilterNode struct {
+	Column string          `json:"column,omitempty"` // Leaf column, or the public field name when parsed by a registry // 叶子节点的列，由注册表解析时为公开的字段名
+	Op     FilterOp        `json:"op,omitempty"`     // Leaf operator // 叶子节点的运算符
+	Value  json.RawMessage `json:"value,omitempty"`  // Leaf value // 叶子节点的值
+	AND    []*FilterNode   `json:"and,omitempty"`    // All the children hold // 所有子节点都成立
+	OR     []*FilterNode   `json:"or,omitempty"`     // Any of the children holds // 任一子节点成立
+	NOT    *FilterNode     `json:"not,omitempty"`    // The child does not hold // 子节点不成立
+}
+
+// NewFilterNode converts the condition into its JSON representation.
+// Fails with ErrFilterUnsupported on leaves without a predicate (raw statements) and on values being expressions (subqueries).
+//
+// NewFilterNode 将条件转换为 JSON 表示。
+// 遇到没有谓词的叶子节点（原始语句）或值为表达式（子查询）时返回 ErrFilterUnsupported。
+func NewFilterNode(qx *QxConjunction) (*FilterNode, error) {
+	return newFilterNode(qx.Node(), func(column string) (string, error) {
+		return column, nil
+	})
+}
+
+// NewFilterNode converts the condition into its JSON representation, naming the columns by their registered field names.
+// Fails with ErrFilterColumn on columns missing in the registry, thus the result always parses back with ParseJSON.
+//
+// NewFilterNode 将条件转换为 JSON 表示，使用注册的字段名表示各列。
+// 遇到注册表中不存在的列时返回 ErrFilterColumn，因此结果总能通过 ParseJSON 解析回来。
+func (registry *FilterRegistry) NewFilterNode(qx *QxConjunction) (*FilterNode, error) {
+	return newFilterNode(qx.Node(), func(column string) (string, error) {
+		field, ok := registry.columns[column]
+		if !ok {
+			return "", errors.WithMessagef(ErrFilterColumn, "column %q", column)
+		}
+		return field, nil
+	})
+}
+
+// newFilterNode converts the tree node into the FilterNode recursively, naming the columns with the function
+// newFilterNode 递归地将条件树节点转换为 FilterNode，使用该函数命名各列
+func newFilterNode(node *QxNode, nameOf func(column string) (string, error)) (*FilterNode, error) {
+	switch node.Kind {
+	case QxKindAND, QxKindOR:
+		var children = make([]*FilterNode, 0, len(node.Children))
+		for _, child := range node.Children {
+			item, err := newFilterNode(child, nameOf)
+			if err != nil {
+				return nil, err
+			}
+			children = append(children, item)
+		}
+		if node.Kind == QxKindAND {
+			return &FilterNode{AND: children}, nil
+		}
+		return &FilterNode{OR: children}, nil
+	case QxKindNOT:
+		item, err := newFilterNode(node.Children[0], nameOf)
+		if err != nil {
+			return nil, err
+		}
+		return &FilterNode{NOT: item}, nil
+	}
+	var predicate = node.Predicate
+	if predicate == nil {
+		return nil, errors.WithMessagef(ErrFilterUnsupported, "raw statement %q", node.Stmt)
+	}
+	op, ok := filterOpOf(predicate.Op)
+	if !ok {
+		return nil, errors.WithMessagef(ErrFilterUnsupported, "operator %q on column %q", predicate.Op, predicate.Column)
+	}
+	for _, value := range predicate.Values {
+		switch value.(type) {
+		case clause.Expression, *gorm.DB:
+			return nil, errors.WithMessagef(ErrFilterUnsupported, "expression value on column %q", predicate.Column)
+		}
+	}
+	name, err := nameOf(predicate.Column)
+	if err != nil {
+		return nil, err
+	}
+	var res = &FilterNode{Column: name, Op: op}
+	var value interface{}
+	switch filterArities[op] {
+	case filterArityNone:
+		return res, nil
+	case filterArityPair:
+		value = predicate.Values
+	default:
+		value = predicate.Values[0]
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return nil, errors.WithMessagef(ErrFilterUnsupported, "value on column %q: %s", predicate.Column, err.Error())
+	}
+	res.Value = data
+	return res, nil
+}
+
+// ParseJSON parses the JSON filter into the condition, checking each leaf against the registry.
+// Unknown fields, unknown columns, disallowed operators and values not of the column type are rejected.
+// So are an empty "or" and an empty condition under "or" or "not", which would match all the rows, e.g. {"or":[]}.
+//
+// ParseJSON 将 JSON 过滤条件解析为条件，并依据注册表检查每个叶子节点。
+// 未知的字段、未知的列、不允许的运算符以及不符合列类型的值都会被拒绝。
+// 空的 "or" 以及 "or" 或 "not" 下的空条件同样会被拒绝，因为它们会匹配所有行，例如 {"or":[]}。
+func (registry *FilterRegistry) ParseJSON(data []byte) (*QxConjunction, error) {
+	var node FilterNode
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&node); err != nil {
+		return nil, errors.WithMessagef(ErrFilterSyntax, "%s", err.Error())
+	}
+	if _, err := decoder.Token(); err != io.EOF {
+		return nil, errors.WithMessage(ErrFilterSyntax, "data after the filter")
+	}
+	return registry.BuildNode(&node)
+}
+
+// BuildNode builds the condition of the FilterNode, checking each leaf against the registry, see ParseJSON
+// BuildNode 构建 FilterNode 对应的条件，并依据注册表检查每个叶子节点，见 ParseJSON
+func (registry *FilterRegistry) BuildNode(node *FilterNode) (*QxConjunction, error) {
+	return registry.buildNode(node, "$", 0)
+}
+
+// buildNode builds the node recursively, the path locating the node in the errors, e.g. "$.and[1].not"
+// buildNode 递归地构建节点，路径用于在错误中定位节点，例如 "$.and[1].not"
+func (registry *FilterRegistry) buildNode(node *FilterNode, path string, depth int) (*QxConjunction, error) {
+	if node == nil {
+		return nil, errors.WithMessagef(ErrFilterSyntax, "%s: null node", path)
+	}
+	if depth > maxFilterDepth {
+		return nil, errors.WithMessagef(ErrFilterSyntax, "%s: nested deeper than %d", path, maxFilterDepth)
+	}
+	var isLeaf = node.Column != "" || node.Op != "" || len(node.Value) != 0
+	var kinds int
+	for _, set := range []bool{isLeaf, node.AND != nil, node.OR != nil, node.NOT != nil} {
+		if set {
+			kinds++
+		}
+	}
+	if kinds > 1 {
+		return nil, errors.WithMessagef(ErrFilterSyntax, "%s: a node is exactly one of leaf, and, or, not", path)
+	}
+	switch {
+	case node.AND != nil || node.OR != nil:
+		var name, items = "and", node.AND
+		if node.OR != nil {
+			name, items = "or", node.OR
+		}
+		// An empty OR is FALSE in logic, while the empty condition matches all the rows, thus it is rejected
+		// 空的 OR 在逻辑上为 FALSE，而空条件会匹配所有行，因此拒绝它
+		if name == "or" && len(items) == 0 {
+			return nil, errors.WithMessagef(ErrFilterSyntax, "%s.or: empty list", path)
+		}
+		var children = make([]*QxConjunction, 0, len(items))
+		for idx, item := range items {
+			var itemPath = path + "." + name + "[" + strconv.Itoa(idx) + "]"
+			child, err := registry.buildNode(item, itemPath, depth+1)
+			if err != nil {
+				return nil, err
+			}
+			if name == "or" && child.IsEmpty() {
+				return nil, errors.WithMessagef(ErrFilterSyntax, "%s: empty condition under or", itemPath)
+			}
+			children = append(children, child)
+		}
+		if name == "and" {
+			return QxAND(children...), nil
+		}
+		return QxOR(children...), nil
+	case node.NOT != nil:
+		child, err := registry.buildNode(node.NOT, path+".not", depth+1)
+		if err != nil {
+			return nil, err
+		}
+		if child.IsEmpty() {
+			return nil, errors.WithMessagef(ErrFilterSyntax, "%s.not: empty condition under not", path)
+		}
+		return child.NOT(), nil
+	case isLeaf:
+		qx, err := registry.Build(node.Column, node.Op, node.Value)
+		if err != nil {
+			return nil, errors.WithMessage(err, path)
+		}
+		return qx, nil
+	}
+	return NewEmptyQx(), nil
+}
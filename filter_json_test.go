@@ -0,0 +1,156 @@
+// Package gormcnm tests validate the JSON representation of condition trees
+// Auto verifies the stable encoding and the round trip through the registry
+// Tests examine SQLite execution of parsed filters and the rejection of malformed or unchecked input
+//
+// gormcnm 测试包验证条件树的 JSON 表示
+// 自动验证稳定的编码以及经由注册表的往返转换
+// 测试涵盖解析后过滤条件的 SQLite 执行，以及拒绝格式错误或未经检查的输入
+package gormcnm
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/yyle88/gormcnm/internal/tests"
+	"gorm.io/gorm"
+)
+
+func TestNewFilterNode(t *testing.T) {
+	type Example struct {
+		Name string `gorm:"primary_key;type:varchar(100);"`
+		Type string `gorm:"column:type;"`
+		Rank int    `gorm:"column:rank;"`
+	}
+
+	const (
+		columnName = ColumnName[string]("name")
+		columnType = ColumnName[string]("type")
+		columnRank = ColumnName[int]("rank")
+	)
+
+	qx := QxAND(
+		NewStringColumn(columnName).Contains("a"),
+		Qx(columnType.Eq("xyz")).OR(Qx(columnType.IsNULL())),
+		Qx(columnRank.Between(1, 2)).NOT(),
+	)
+	data, err := marshalFilterNode(qx)
+	require.NoError(t, err)
+	const expected = `{"and":[` +
+		`{"column":"name","op":"contains","value":"a"},` +
+		`{"or":[{"column":"type","op":"eq","value":"xyz"},{"column":"type","op":"is_null"}]},` +
+		`{"not":{"column":"rank","op":"between","value":[1,2]}}]}`
+	require.Equal(t, expected, string(data))
+
+	registry := NewFilterRegistry()
+	RegisterFilterColumn(registry, columnName)
+	RegisterFilterColumn(registry, columnType)
+	RegisterFilterColumn(registry, columnRank)
+
+	res, err := registry.ParseJSON(data)
+	require.NoError(t, err)
+	dryRunDB := tests.NewDryRunDB(t, "sqlite")
+	require.Equal(t, qx.ToSQL(dryRunDB), res.ToSQL(dryRunDB))
+
+	again, err := marshalFilterNode(res)
+	require.NoError(t, err)
+	require.Equal(t, expected, string(again))
+
+	tests.NewDBRun(t, func(db *gorm.DB) {
+		require.NoError(t, db.AutoMigrate(&Example{}))
+		require.NoError(t, db.Create(&[]*Example{
+			{Name: "abc", Type: "xyz", Rank: 1},
+			{Name: "aaa", Type: "xyz", Rank: 3},
+			{Name: "bbb", Type: "xyz", Rank: 4},
+			{Name: "axe", Type: "uvw", Rank: 5},
+		}).Error)
+
+		var names []string
+		require.NoError(t, db.Model(&Example{}).Scopes(res.Scope()).Order(columnName.Name()).Pluck(columnName.Name(), &names).Error)
+		require.Equal(t, []string{"aaa"}, names)
+	})
+}
+
+func TestNewFilterNode_Empty(t *testing.T) {
+	data, err := marshalFilterNode(NewEmptyQx())
+	require.NoError(t, err)
+	require.Equal(t, `{}`, string(data))
+
+	res, err := NewFilterRegistry().ParseJSON(data)
+	require.NoError(t, err)
+	require.True(t, res.IsEmpty())
+}
+
+func TestNewFilterNode_Unsupported(t *testing.T) {
+	type Example struct {
+		Name string `gorm:"primary_key;type:varchar(100);"`
+	}
+
+	const columnName = ColumnName[string]("name")
+
+	_, err := NewFilterNode(Qx(columnName.Eq("abc")).AND(Qx("LENGTH(name) > ?", 3)))
+	require.ErrorIs(t, err, ErrFilterUnsupported)
+
+	db := tests.NewDryRunDB(t, "sqlite")
+	sub := NewSubQuery[string](db.Model(&Example{}).Select(columnName.Name()))
+	_, err = NewFilterNode(columnName.InSub(sub))
+	require.ErrorIs(t, err, ErrFilterUnsupported)
+}
+
+// marshalFilterNode encodes the condition as the JSON of its FilterNode
+// marshalFilterNode 将条件编码为其 FilterNode 的 JSON
+func marshalFilterNode(qx *QxConjunction) ([]byte, error) {
+	node, err := NewFilterNode(qx)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(node)
+}
+
+func TestFilterRegistry_ParseJSON(t *testing.T) {
+	const (
+		columnName   = ColumnName[string]("name")
+		columnRank   = ColumnName[int]("rank")
+		columnSecret = ColumnName[string]("secret")
+	)
+
+	registry := NewFilterRegistry()
+	RegisterFilterColumn(registry, columnName, FilterEq, FilterIn)
+	RegisterFilterColumn(registry, columnRank)
+
+	qx, err := registry.ParseJSON([]byte(`{"or":[{"column":"name","op":"in","value":["a","b"]},{"column":"rank","op":"gte","value":3}]}`))
+	require.NoError(t, err)
+	require.Equal(t, "name IN(?) OR rank>=?", qx.Qs())
+	require.Equal(t, []interface{}{[]string{"a", "b"}, 3}, qx.Args())
+
+	for data, expected := range map[string]error{
+		`{"column":"secret","op":"eq","value":"x"}`:          ErrFilterColumn,
+		`{"column":"name","op":"contains","value":"x"}`:      ErrFilterOperator,
+		`{"column":"name","op":"drop","value":"x"}`:          ErrFilterOperator,
+		`{"not":{"column":"rank","op":"eq","value":"1"}}`:    ErrFilterValue,
+		`{"column":"rank","op":"eq","value":1.5}`:            ErrFilterValue,
+		`{"column":"rank","op":"eq","value":null}`:           ErrFilterValue,
+		`{"column":"rank","op":"eq"}`:                        ErrFilterValue,
+		`{"column":"rank","op":"between","value":[1]}`:       ErrFilterValue,
+		`{"column":"rank","op":"is_null","value":1}`:         ErrFilterValue,
+		`{"column":"rank","op":"eq","value":1,"and":[]}`:     ErrFilterSyntax,
+		`{"column":"rank","op":"eq","value":1,"extra":true}`: ErrFilterSyntax,
+		`{"and":[null]}`:      ErrFilterSyntax,
+		`{"and":[]} {}`:       ErrFilterSyntax,
+		`{"or":[]}`:           ErrFilterSyntax,
+		`{"not":{"or":[]}}`:   ErrFilterSyntax,
+		`{"not":{"and":[]}}`:  ErrFilterSyntax,
+		`{"not":{}}`:          ErrFilterSyntax,
+		`{"or":[{"and":[]}]}`: ErrFilterSyntax,
+		`[{"column":"rank","op":"eq","value":1}]`:                             ErrFilterSyntax,
+		`{"and":[{"column":"rank","op":"eq","value":1},{"column":"secret"}]}`: ErrFilterColumn,
+	} {
+		_, err := registry.ParseJSON([]byte(data))
+		require.ErrorIs(t, err, expected, data)
+	}
+
+	_, err = registry.ParseJSON([]byte(`{"and":[{"column":"rank","op":"eq","value":1},{"column":"secret","op":"eq","value":"x"}]}`))
+	require.ErrorContains(t, err, "$.and[1]")
+	_, err = NewFilterNode(Qx(columnSecret.Eq("x")))
+	require.NoError(t, err) // Encoding needs no registry, only parsing checks the columns
+}
@@ -0,0 +1,359 @@
+// Package gormcnm provides the registry of the columns allowed in filters coming from outside, e.g. saved searches
+// Auto decodes the values into the typed column and builds the condition with the typed column operations
+// Supports limiting the operators of each column, rejecting unknown columns, disallowed operators and wrong value types
+//
+// gormcnm 提供外部传入过滤条件（例如保存的搜索）所允许的列的注册表
+// 自动将值解码为列的类型，并使用类型化的列操作构建条件
+// 支持限制每个列的运算符，拒绝未知的列、不允许的运算符以及类型错误的值
+package gormcnm
+
+import (
+	"encoding/json"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/yyle88/must"
+)
+
+var (
+	// ErrFilterColumn means the filter uses a field (or column) missing in the registry
+	// ErrFilterColumn 表示过滤条件使用了注册表中不存在的字段（或列）
+	ErrFilterColumn = errors.New("unknown filter field")
+	// ErrFilterOperator means the filter uses an unknown operator, or one not allowed on the column
+	// ErrFilterOperator 表示过滤条件使用了未知的运算符，或该列不允许的运算符
+	ErrFilterOperator = errors.New("filter operator not allowed")
+	// ErrFilterValue means the filter value is missing, null, or not decodable into the column type
+	// ErrFilterValue 表示过滤条件的值缺失、为 null，或无法解码为列的类型
+	ErrFilterValue = errors.New("invalid filter value")
+	// ErrFilterSyntax means the filter is malformed, e.g. a node being both a leaf and an AND
+	// ErrFilterSyntax 表示过滤条件格式错误，例如节点同时是叶子和 AND
+	ErrFilterSyntax = errors.New("invalid filter syntax")
+	// ErrFilterUnsupported means the condition can not be serialized, e.g. a raw statement or a subquery
+	// ErrFilterUnsupported 表示条件无法被序列化，例如原始语句或子查询
+	ErrFilterUnsupported = errors.New("condition not serializable")
+)
+
+// maxFilterDepth limits the nesting of the parsed filters
+// maxFilterDepth 限制解析的过滤条件的嵌套深度
+const maxFilterDepth = 32
+
+// FilterOp represents an operator of the serialized filters, e.g. "eq", "in", "is_null", "contains"
+// FilterOp 表示序列化过滤条件中的运算符，例如 "eq"、"in"、"is_null"、"contains"
+type FilterOp string
+
+const (
+	FilterEq                FilterOp = "eq"                   // column = value
+	FilterNe                FilterOp = "ne"                   // column <> value
+	FilterGt                FilterOp = "gt"                   // column > value
+	FilterGte               FilterOp = "gte"                  // column >= value
+	FilterLt                FilterOp = "lt"                   // column < value
+	FilterLte               FilterOp = "lte"                  // column <= value
+	FilterIn                FilterOp = "in"                   // column IN (values...)
+	FilterNotIn             FilterOp = "not_in"               // column NOT IN (values...)
+	FilterLike              FilterOp = "like"                 // column LIKE pattern
+	FilterNotLike           FilterOp = "not_like"             // column NOT LIKE pattern
+	FilterBetween           FilterOp = "between"              // column BETWEEN low AND high
+	FilterNotBetween        FilterOp = "not_between"          // column NOT BETWEEN low AND high
+	FilterIsNull            FilterOp = "is_null"              // column IS NULL
+	FilterIsNotNull         FilterOp = "is_not_null"          // column IS NOT NULL
+	FilterIsTrue            FilterOp = "is_true"              // column IS TRUE
+	FilterIsFalse           FilterOp = "is_false"             // column IS FALSE
+	FilterIsDistinctFrom    FilterOp = "is_distinct_from"     // column IS DISTINCT FROM value
+	FilterIsNotDistinctFrom FilterOp = "is_not_distinct_from" // column IS NOT DISTINCT FROM value
+	FilterContains          FilterOp = "contains"             // column contains the text
+	FilterNotContains       FilterOp = "not_contains"         // column does not contain the text
+	FilterHasPrefix         FilterOp = "has_prefix"           // column starts with the text
+	FilterNotHasPrefix      FilterOp = "not_has_prefix"       // column does not start with the text
+	FilterHasSuffix         FilterOp = "has_suffix"           // column ends with the text
+	FilterNotHasSuffix      FilterOp = "not_has_suffix"       // column does not end with the text
+	FilterContainsFold      FilterOp = "contains_fold"        // column contains the text, case-insensitive
+	FilterNotContainsFold   FilterOp = "not_contains_fold"    // column does not contain the text, case-insensitive
+	FilterHasPrefixFold     FilterOp = "has_prefix_fold"      // column starts with the text, case-insensitive
+	FilterNotHasPrefixFold  FilterOp = "not_has_prefix_fold"  // column does not start with the text, case-insensitive
+	FilterHasSuffixFold     FilterOp = "has_suffix_fold"      // column ends with the text, case-insensitive
+	FilterNotHasSuffixFold  FilterOp = "not_has_suffix_fold"  // column does not end with the text, case-insensitive
+)
+
+// filterArity represents the shape of the value taken by a FilterOp
+// filterArity 表示 FilterOp 所接受的值的形式
+type filterArity int
+
+const (
+	filterArityNone filterArity = iota + 1 // No value // 没有值
+	filterArityOne                         // A value of the column type // 列类型的单个值
+	filterArityList                        // A list of the column type // 列类型的列表
+	filterArityPair                        // A [low, high] pair of the column type // 列类型的 [low, high] 对
+	filterArityText                        // A string, whatever the column type // 字符串，与列类型无关
+)
+
+// filterArities gives the value shape of each known FilterOp
+// filterArities 给出每个已知 FilterOp 的值的形式
+var filterArities = map[FilterOp]filterArity{
+	FilterEq: filterArityOne, FilterNe: filterArityOne,
+	FilterGt: filterArityOne, FilterGte: filterArityOne, FilterLt: filterArityOne, FilterLte: filterArityOne,
+	FilterIn: filterArityList, FilterNotIn: filterArityList,
+	FilterLike: filterArityOne, FilterNotLike: filterArityOne,
+	FilterBetween: filterArityPair, FilterNotBetween: filterArityPair,
+	FilterIsNull: filterArityNone, FilterIsNotNull: filterArityNone,
+	FilterIsTrue: filterArityNone, FilterIsFalse: filterArityNone,
+	FilterIsDistinctFrom: filterArityOne, FilterIsNotDistinctFrom: filterArityOne,
+	FilterContains: filterArityText, FilterNotContains: filterArityText,
+	FilterHasPrefix: filterArityText, FilterNotHasPrefix: filterArityText,
+	FilterHasSuffix: filterArityText, FilterNotHasSuffix: filterArityText,
+	FilterContainsFold: filterArityText, FilterNotContainsFold: filterArityText,
+	FilterHasPrefixFold: filterArityText, FilterNotHasPrefixFold: filterArityText,
+	FilterHasSuffixFold: filterArityText, FilterNotHasSuffixFold: filterArityText,
+}
+
+// filterSymbolOps maps the symbol operators of the predicates to the FilterOp
+// filterSymbolOps 将谓词中的符号运算符映射为 FilterOp
+var filterSymbolOps = map[string]FilterOp{
+	"=": FilterEq, "!=": FilterNe, "<>": FilterNe, ">": FilterGt, ">=": FilterGte, "<": FilterLt, "<=": FilterLte,
+}
+
+// filterOpOf converts the operator of a QxPredicate into the FilterOp, e.g. "NOT IN" -> "not_in", "HAS PREFIX FOLD" -> "has_prefix_fold"
+// filterOpOf 将 QxPredicate 的运算符转换为 FilterOp，例如 "NOT IN" -> "not_in"、"HAS PREFIX FOLD" -> "has_prefix_fold"
+func filterOpOf(op string) (FilterOp, bool) {
+	if filterOp, ok := filterSymbolOps[op]; ok {
+		return filterOp, true
+	}
+	var filterOp = FilterOp(strings.ToLower(strings.ReplaceAll(op, " ", "_")))
+	_, ok := filterArities[filterOp]
+	return filterOp, ok
+}
+
+// FilterRegistry holds the fields allowed in the parsed filters, each field being a typed column with its allowed operators
+// FilterRegistry 保存解析过滤条件时允许的字段，每个字段对应一个类型化的列及其允许的运算符
+type FilterRegistry struct {
+	fields  map[string]*filterField // Registered fields by public name // 按公开名称保存的已注册字段
+	columns map[string]string       // Public names by column name // 按列名保存的公开名称
+}
+
+// filterField is a registered field, building the conditions with the typed column operations
+// filterField 是已注册的字段，使用类型化的列操作构建条件
+type filterField struct {
+	column    string                                                           // Column name // 列名
+	operators map[FilterOp]bool                                                // Allowed operators // 允许的运算符
+	check     func(value json.RawMessage) error                                // Checks a value of the column type // 检查列类型的单个值
+	build     func(op FilterOp, value json.RawMessage) (*QxConjunction, error) // Typed condition builder // 类型化的条件构建函数
+}
+
+// NewFilterRegistry creates an empty FilterRegistry, register the columns with RegisterFilterColumn or RegisterFilterField
+// NewFilterRegistry 创建空的 FilterRegistry，使用 RegisterFilterColumn 或 RegisterFilterField 注册列
+func NewFilterRegistry() *FilterRegistry {
+	return &FilterRegistry{fields: map[string]*filterField{}, columns: map[string]string{}}
+}
+
+// RegisterFilterColumn allows the column in the filters under its own name, see RegisterFilterField
+// RegisterFilterColumn 允许在过滤条件中以列名使用该列，见 RegisterFilterField
+func RegisterFilterColumn[TYPE any](registry *FilterRegistry, column ColumnName[TYPE], operators ...FilterOp) {
+	RegisterFilterField(registry, column.Name(), column, operators...)
+}
+
+// RegisterFilterField allows the column in the filters under the public field name, e.g. "createdAt" for "created_at",
+// with the operators or the default ones when none is given. The defaults are the comparisons, IN, BETWEEN and the NULL checks,
+// plus LIKE and the text patterns on string columns, plus IS TRUE/FALSE on bool columns.
+// Go has no generic methods, thus this is a function taking the registry.
+//
+// RegisterFilterField 允许在过滤条件中以公开的字段名使用该列，例如用 "createdAt" 表示 "created_at"，
+// 可指定运算符，未指定时使用默认运算符。默认运算符为比较、IN、BETWEEN 和 NULL 判断，
+// 字符串列另有 LIKE 和文本匹配，布尔列另有 IS TRUE/FALSE。
+// Go 不支持泛型方法，因此这是一个接收注册表的函数。
+func RegisterFilterField[TYPE any](registry *FilterRegistry, field string, column ColumnName[TYPE], operators ...FilterOp) {
+	must.Nice(field)
+	if len(operators) == 0 {
+		operators = defaultFilterOperators(reflect.TypeOf((*TYPE)(nil)).Elem())
+	}
+	var allowed = make(map[FilterOp]bool, len(operators))
+	for _, op := range operators {
+		must.True(filterArities[op] != 0)
+		allowed[op] = true
+	}
+	registry.fields[field] = &filterField{
+		column:    column.Name(),
+		operators: allowed,
+		check: func(value json.RawMessage) error {
+			var x TYPE
+			return decodeFilterValue(value, &x)
+		},
+		build: func(op FilterOp, value json.RawMessage) (*QxConjunction, error) {
+			return buildFilter(column, op, value)
+		},
+	}
+	registry.columns[column.Name()] = field
+}
+
+// defaultFilterOperators returns the operators fitting the column type
+// defaultFilterOperators 返回适合该列类型的运算符
+func defaultFilterOperators(typ reflect.Type) []FilterOp {
+	var operators = []FilterOp{
+		FilterEq, FilterNe, FilterGt, FilterGte, FilterLt, FilterLte,
+		FilterIn, FilterNotIn, FilterBetween, FilterNotBetween,
+		FilterIsNull, FilterIsNotNull, FilterIsDistinctFrom, FilterIsNotDistinctFrom,
+	}
+	for typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+	switch typ.Kind() {
+	case reflect.String:
+		for op, arity := range filterArities {
+			if arity == filterArityText {
+				operators = append(operators, op)
+			}
+		}
+		operators = append(operators, FilterLike, FilterNotLike)
+	case reflect.Bool:
+		operators = append(operators, FilterIsTrue, FilterIsFalse)
+	}
+	return operators
+}
+
+// Fields returns the names of the registered fields, sorted
+// Fields 返回已注册字段的名称，按字母排序
+func (registry *FilterRegistry) Fields() []string {
+	var names = make([]string, 0, len(registry.fields))
+	for name := range registry.fields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// lookup returns the registered field, failing with ErrFilterColumn when missing
+// lookup 返回已注册的字段，不存在时返回 ErrFilterColumn
+func (registry *FilterRegistry) lookup(field string) (*filterField, error) {
+	item, ok := registry.fields[field]
+	if !ok {
+		return nil, errors.WithMessagef(ErrFilterColumn, "field %q", field)
+	}
+	return item, nil
+}
+
+// Build builds the condition on the registered field, the value being the JSON of the column type, e.g. Build("rank", FilterGt, []byte("1"))
+// Build 在已注册的字段上构建条件，值为列类型的 JSON，例如 Build("rank", FilterGt, []byte("1"))
+func (registry *FilterRegistry) Build(field string, op FilterOp, value json.RawMessage) (*QxConjunction, error) {
+	item, err := registry.lookup(field)
+	if err != nil {
+		return nil, err
+	}
+	if !item.operators[op] {
+		return nil, errors.WithMessagef(ErrFilterOperator, "operator %q on field %q", op, field)
+	}
+	if filterArities[op] == filterArityNone {
+		if len(value) != 0 && string(value) != "null" {
+			return nil, errors.WithMessagef(ErrFilterValue, "operator %q on field %q takes no value", op, field)
+		}
+		value = nil
+	}
+	qx, err := item.build(op, value)
+	if err != nil {
+		return nil, errors.WithMessagef(err, "operator %q on field %q", op, field)
+	}
+	return qx, nil
+}
+
+// buildFilter decodes the value into the column type and builds the condition with the typed column operation
+// buildFilter 将值解码为列的类型，并使用类型化的列操作构建条件
+func buildFilter[TYPE any](column ColumnName[TYPE], op FilterOp, value json.RawMessage) (*QxConjunction, error) {
+	switch filterArities[op] {
+	case filterArityNone:
+		switch op {
+		case FilterIsNull:
+			return Qx(column.IsNULL()), nil
+		case FilterIsNotNull:
+			return Qx(column.IsNotNULL()), nil
+		case FilterIsTrue:
+			return Qx(column.IsTRUE()), nil
+		default:
+			return Qx(column.IsFALSE()), nil
+		}
+	case filterArityList:
+		var values []TYPE
+		if err := decodeFilterValue(value, &values); err != nil {
+			return nil, err
+		}
+		if op == FilterIn {
+			return Qx(column.In(values)), nil
+		}
+		return Qx(column.NotIn(values)), nil
+	case filterArityPair:
+		var values []TYPE
+		if err := decodeFilterValue(value, &values); err != nil {
+			return nil, err
+		}
+		if len(values) != 2 {
+			return nil, errors.WithMessagef(ErrFilterValue, "%d values but a [low, high] pair expected", len(values))
+		}
+		if op == FilterBetween {
+			return Qx(column.Between(values[0], values[1])), nil
+		}
+		return Qx(column.NotBetween(values[0], values[1])), nil
+	case filterArityText:
+		var text string
+		if err := decodeFilterValue(value, &text); err != nil {
+			return nil, err
+		}
+		return buildTextFilter(column, op, text), nil
+	}
+	var x TYPE
+	if err := decodeFilterValue(value, &x); err != nil {
+		return nil, err
+	}
+	switch op {
+	case FilterEq:
+		return Qx(column.Eq(x)), nil
+	case FilterNe:
+		return Qx(column.Ne(x)), nil
+	case FilterGt:
+		return Qx(column.Gt(x)), nil
+	case FilterGte:
+		return Qx(column.Gte(x)), nil
+	case FilterLt:
+		return Qx(column.Lt(x)), nil
+	case FilterLte:
+		return Qx(column.Lte(x)), nil
+	case FilterLike:
+		return Qx(column.Like(x)), nil
+	case FilterNotLike:
+		return Qx(column.NotLike(x)), nil
+	case FilterIsDistinctFrom:
+		return column.DistinctFrom(x), nil
+	default:
+		return column.NotDistinctFrom(x), nil
+	}
+}
+
+// buildTextFilter builds the text pattern condition, the text being matched literally
+// The operators are registered on the string columns by default, see StringColumn for the typed API
+//
+// buildTextFilter 构建文本匹配条件，文本按字面值匹配
+// 这些运算符默认注册在字符串列上，类型化的 API 见 StringColumn
+func buildTextFilter[TYPE any](column ColumnName[TYPE], op FilterOp, text string) *QxConjunction {
+	var prefix, suffix = "%", "%"
+	switch op {
+	case FilterHasPrefix, FilterNotHasPrefix, FilterHasPrefixFold, FilterNotHasPrefixFold:
+		prefix = ""
+	case FilterHasSuffix, FilterNotHasSuffix, FilterHasSuffixFold, FilterNotHasSuffixFold:
+		suffix = ""
+	}
+	var not = strings.HasPrefix(string(op), "not_")
+	var fold = strings.HasSuffix(string(op), "_fold")
+	return column.escapedLike(prefix, text, suffix, not, fold)
+}
+
+// decodeFilterValue decodes the JSON value into the target, rejecting the missing and null values
+// decodeFilterValue 将 JSON 值解码到目标中，拒绝缺失和 null 的值
+func decodeFilterValue(value json.RawMessage, target interface{}) error {
+	if len(value) == 0 {
+		return errors.WithMessage(ErrFilterValue, "missing value")
+	}
+	if string(value) == "null" {
+		return errors.WithMessage(ErrFilterValue, "null value, use is_null or is_not_null")
+	}
+	if err := json.Unmarshal(value, target); err != nil {
+		return errors.WithMessagef(ErrFilterValue, "%s", err.Error())
+	}
+	return nil
+}
@@ -0,0 +1,73 @@
+// Package gormcnm tests validate the registry of the columns allowed in filters
+// Auto verifies the default operators fitting the column types and the typed value decoding
+// Tests examine the conditions built on registered columns and the rejected input
+//
+// gormcnm 测试包验证过滤条件中允许使用的列的注册表
+// 自动验证适合列类型的默认运算符以及类型化的值解码
+// 测试涵盖在已注册列上构建的条件以及被拒绝的输入
+package gormcnm
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestFilterRegistry_Build(t *testing.T) {
+	const (
+		columnName    = ColumnName[string]("name")
+		columnRank    = ColumnName[int]("rank")
+		columnActive  = ColumnName[bool]("active")
+		columnCreated = ColumnName[time.Time]("created_at")
+	)
+
+	registry := NewFilterRegistry()
+	RegisterFilterColumn(registry, columnName)
+	RegisterFilterColumn(registry, columnRank)
+	RegisterFilterColumn(registry, columnActive)
+	RegisterFilterField(registry, "createdAt", columnCreated, FilterGte, FilterLt)
+	require.Equal(t, []string{"active", "createdAt", "name", "rank"}, registry.Fields())
+
+	qx, err := registry.Build("name", FilterHasPrefix, json.RawMessage(`"a_"`))
+	require.NoError(t, err)
+	require.Equal(t, "HAS PREFIX", qx.Node().Predicate.Op)
+
+	qx, err = registry.Build("rank", FilterNotIn, json.RawMessage(`[1,2]`))
+	require.NoError(t, err)
+	require.Equal(t, "rank NOT IN(?)", qx.Qs())
+	require.Equal(t, []interface{}{[]int{1, 2}}, qx.Args())
+
+	qx, err = registry.Build("active", FilterIsTrue, nil)
+	require.NoError(t, err)
+	require.Equal(t, []string{"active"}, qx.Columns())
+
+	qx, err = registry.Build("createdAt", FilterGte, json.RawMessage(`"2024-01-02T03:04:05Z"`))
+	require.NoError(t, err)
+	require.Equal(t, []interface{}{time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)}, qx.Args())
+
+	_, err = registry.Build("created_at", FilterGte, json.RawMessage(`"2024-01-02T03:04:05Z"`))
+	require.ErrorIs(t, err, ErrFilterColumn) // Only the public field name resolves
+
+	node, err := registry.NewFilterNode(qx)
+	require.NoError(t, err)
+	require.Equal(t, &FilterNode{Column: "createdAt", Op: FilterGte, Value: json.RawMessage(`"2024-01-02T03:04:05Z"`)}, node)
+
+	_, err = registry.Build("rank", FilterContains, json.RawMessage(`"1"`))
+	require.ErrorIs(t, err, ErrFilterOperator) // Text patterns are defaults on string columns only
+	_, err = registry.Build("name", FilterIsTrue, nil)
+	require.ErrorIs(t, err, ErrFilterOperator)
+	_, err = registry.Build("createdAt", FilterEq, json.RawMessage(`"2024-01-02T03:04:05Z"`))
+	require.ErrorIs(t, err, ErrFilterOperator)
+	_, err = registry.Build("createdAt", FilterLt, json.RawMessage(`"yesterday"`))
+	require.ErrorIs(t, err, ErrFilterValue)
+	_, err = registry.Build("rank", FilterIn, json.RawMessage(`["1"]`))
+	require.ErrorIs(t, err, ErrFilterValue)
+}
+
+func TestRegisterFilterColumn_UnknownOperator(t *testing.T) {
+	require.Panics(t, func() {
+		RegisterFilterColumn(NewFilterRegistry(), ColumnName[string]("name"), FilterOp("drop"))
+	})
+}
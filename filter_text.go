@@ -0,0 +1,496 @@
+// Package gormcnm provides the text filter language, e.g. `rank > 100 AND (type = "xyz" OR name LIKE "a%")`
+// Auto resolves the identifiers only through a FilterRegistry and converts the literals into the column types
+// Supports position-accurate errors, and the user text never reaches the SQL, only the registered columns and the arguments do
+//
+// gormcnm 提供文本过滤语言，例如 `rank > 100 AND (type = "xyz" OR name LIKE "a%")`
+// 自动仅通过 FilterRegistry 解析标识符，并将字面量转换为列的类型
+// 支持精确定位的错误信息，用户文本不会进入 SQL，进入 SQL 的只有已注册的列和参数
+package gormcnm
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/pkg/errors"
+)
+
+// FilterTextError is the error of ParseText, locating the offending token in the text.
+// Unwraps to ErrFilterSyntax, ErrFilterColumn, ErrFilterOperator or ErrFilterValue.
+//
+// FilterTextError 是 ParseText 的错误，定位文本中出错的词法单元。
+// 可解包为 ErrFilterSyntax、ErrFilterColumn、ErrFilterOperator 或 ErrFilterValue。
+type FilterTextError struct {
+	Offset int   // Byte offset of the offending token, the text length at the end of the text // 出错词法单元的字节偏移，文本末尾时为文本长度
+	Err    error // The error at the offset // 该偏移处的错误
+}
+
+// Error returns the message with the 1-based position, e.g. `at position 7: unknown filter field: field "secret"`
+// Error 返回带有从 1 开始的位置的消息，例如 `at position 7: unknown filter field: field "secret"`
+func (e *FilterTextError) Error() string {
+	return fmt.Sprintf("at position %d: %s", e.Offset+1, e.Err.Error())
+}
+
+// Unwrap returns the error at the offset
+// Unwrap 返回该偏移处的错误
+func (e *FilterTextError) Unwrap() error {
+	return e.Err
+}
+
+// filterTokenKind represents the kind of a token of the text filter language
+// filterTokenKind 表示文本过滤语言中词法单元的类型
+type filterTokenKind int
+
+const (
+	filterTokenEOF      filterTokenKind = iota // End of the text // 文本结束
+	filterTokenIdent                           // Field name // 字段名
+	filterTokenKeyword                         // AND, OR, NOT, IN, LIKE, BETWEEN, IS, NULL, TRUE, FALSE
+	filterTokenOperator                        // = != <> > >= < <=
+	filterTokenString                          // Quoted string literal // 带引号的字符串字面量
+	filterTokenNumber                          // Number literal // 数字字面量
+	filterTokenPunct                           // ( ) ,
+)
+
+// filterKeywords are the reserved words of the text filter language, case-insensitive
+// filterKeywords 是文本过滤语言的保留字，不区分大小写
+var filterKeywords = map[string]bool{
+	"AND": true, "OR": true, "NOT": true, "IN": true, "LIKE": true, "BETWEEN": true,
+	"IS": true, "NULL": true, "TRUE": true, "FALSE": true,
+}
+
+// filterToken is a token of the text filter language
+// filterToken 是文本过滤语言的词法单元
+type filterToken struct {
+	kind   filterTokenKind // Token kind // 词法单元类型
+	text   string          // Token text, upper case on keywords, unquoted on strings // 词法单元文本，关键字为大写，字符串为去掉引号后的内容
+	offset int             // Byte offset in the text // 在文本中的字节偏移
+}
+
+// ParseText parses the text filter into the condition, resolving the fields and checking the operators and values with the registry.
+// The grammar supports AND, OR, NOT and parentheses, the comparisons = != <> > >= < <=, [NOT] IN (...), [NOT] LIKE,
+// [NOT] BETWEEN ... AND ..., IS [NOT] NULL and IS TRUE/FALSE, with "..." or '...' strings, numbers, true, false and null.
+// The blank text gives the empty condition. The errors are *FilterTextError.
+//
+// ParseText 将文本过滤条件解析为条件，通过注册表解析字段并检查运算符和值。
+// 语法支持 AND、OR、NOT 和括号，比较运算 = != <> > >= < <=、[NOT] IN (...)、[NOT] LIKE、
+// [NOT] BETWEEN ... AND ...、IS [NOT] NULL 和 IS TRUE/FALSE，字面量可以是 "..." 或 '...' 字符串、数字、true、false 和 null。
+// 空白文本返回空条件。错误类型为 *FilterTextError。
+func (registry *FilterRegistry) ParseText(text string) (*QxConjunction, error) {
+	tokens, err := lexFilterText(text)
+	if err != nil {
+		return nil, err
+	}
+	parser := &filterTextParser{registry: registry, tokens: tokens}
+	if parser.peek().kind == filterTokenEOF {
+		return NewEmptyQx(), nil
+	}
+	qx, err := parser.parseOr(0)
+	if err != nil {
+		return nil, err
+	}
+	if token := parser.peek(); token.kind != filterTokenEOF {
+		return nil, newFilterTextError(token.offset, ErrFilterSyntax, "unexpected %s", token.describe())
+	}
+	return qx, nil
+}
+
+// newFilterTextError creates the FilterTextError at the offset with the message
+// newFilterTextError 在该偏移处使用消息创建 FilterTextError
+func newFilterTextError(offset int, err error, format string, args ...interface{}) *FilterTextError {
+	return &FilterTextError{Offset: offset, Err: errors.WithMessagef(err, format, args...)}
+}
+
+// describe returns the token for the error messages
+// describe 返回用于错误消息的词法单元描述
+func (token filterToken) describe() string {
+	switch token.kind {
+	case filterTokenEOF:
+		return "end of text"
+	case filterTokenString:
+		return "string " + quoteFilterString(token.text)
+	default:
+		return fmt.Sprintf("%q", token.text)
+	}
+}
+
+// quoteFilterString quotes the string literal in the syntax of the text filter language
+// quoteFilterString 按文本过滤语言的语法给字符串字面量加引号
+func quoteFilterString(value string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(value) + `"`
+}
+
+// lexFilterText splits the text into the tokens, ending with the EOF token
+// lexFilterText 将文本切分为词法单元，以 EOF 词法单元结尾
+func lexFilterText(text string) ([]filterToken, error) {
+	var tokens []filterToken
+	for idx := 0; idx < len(text); {
+		c := text[idx]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			idx++
+		case c == '(' || c == ')' || c == ',':
+			tokens = append(tokens, filterToken{kind: filterTokenPunct, text: string(c), offset: idx})
+			idx++
+		case c == '=' || c == '!' || c == '<' || c == '>':
+			end := idx + 1
+			if end < len(text) && ((c != '=' && text[end] == '=') || (c == '<' && text[end] == '>')) {
+				end++
+			}
+			if text[idx:end] == "!" {
+				return nil, newFilterTextError(idx, ErrFilterSyntax, "unexpected %q", "!")
+			}
+			tokens = append(tokens, filterToken{kind: filterTokenOperator, text: text[idx:end], offset: idx})
+			idx = end
+		case c == '"' || c == '\'':
+			value, end, ok := unquoteFilterString(text, idx)
+			if !ok {
+				return nil, newFilterTextError(idx, ErrFilterSyntax, "unterminated string")
+			}
+			tokens = append(tokens, filterToken{kind: filterTokenString, text: value, offset: idx})
+			idx = end
+		case isFilterDigit(c) || (c == '-' && idx+1 < len(text) && isFilterDigit(text[idx+1])):
+			end := idx + 1
+			for end < len(text) && (isNameRune(text[end]) || text[end] == '.' || ((text[end] == '+' || text[end] == '-') && (text[end-1] == 'e' || text[end-1] == 'E'))) {
+				end++
+			}
+			if !json.Valid([]byte(text[idx:end])) {
+				return nil, newFilterTextError(idx, ErrFilterSyntax, "invalid number %q", text[idx:end])
+			}
+			tokens = append(tokens, filterToken{kind: filterTokenNumber, text: text[idx:end], offset: idx})
+			idx = end
+		case isNameRune(c):
+			end := idx
+			for end < len(text) && (isNameRune(text[end]) || text[end] == '.') {
+				end++
+			}
+			word := text[idx:end]
+			if upper := strings.ToUpper(word); filterKeywords[upper] {
+				tokens = append(tokens, filterToken{kind: filterTokenKeyword, text: upper, offset: idx})
+			} else {
+				tokens = append(tokens, filterToken{kind: filterTokenIdent, text: word, offset: idx})
+			}
+			idx = end
+		default:
+			r, _ := utf8.DecodeRuneInString(text[idx:])
+			return nil, newFilterTextError(idx, ErrFilterSyntax, "unexpected character %q", r)
+		}
+	}
+	return append(tokens, filterToken{kind: filterTokenEOF, offset: len(text)}), nil
+}
+
+// unquoteFilterString reads the quoted string starting at idx, with the backslash escaping the next character (\n, \t are the control ones)
+// unquoteFilterString 读取从 idx 开始的带引号字符串，反斜杠转义下一个字符（\n、\t 表示控制字符）
+func unquoteFilterString(text string, idx int) (string, int, bool) {
+	var quote = text[idx]
+	var sb strings.Builder
+	for idx++; idx < len(text); idx++ {
+		switch c := text[idx]; c {
+		case quote:
+			return sb.String(), idx + 1, true
+		case '\\':
+			if idx++; idx == len(text) {
+				return "", 0, false
+			}
+			switch text[idx] {
+			case 'n':
+				sb.WriteByte('\n')
+			case 't':
+				sb.WriteByte('\t')
+			default:
+				sb.WriteByte(text[idx])
+			}
+		default:
+			sb.WriteByte(c)
+		}
+	}
+	return "", 0, false
+}
+
+// isFilterDigit tells whether c is a decimal digit
+// isFilterDigit 判断 c 是否为十进制数字
+func isFilterDigit(c byte) bool {
+	return '0' <= c && c <= '9'
+}
+
+// filterTextParser is the recursive descent parser of the text filter language
+// filterTextParser 是文本过滤语言的递归下降解析器
+type filterTextParser struct {
+	registry *FilterRegistry // Resolves the fields // 用于解析字段
+	tokens   []filterToken   // Tokens ending with EOF // 以 EOF 结尾的词法单元
+	idx      int             // Index of the next token // 下一个词法单元的下标
+}
+
+// peek returns the next token without consuming it
+// peek 返回下一个词法单元但不消耗它
+func (p *filterTextParser) peek() filterToken {
+	return p.tokens[p.idx]
+}
+
+// next consumes and returns the next token, staying at EOF
+// next 消耗并返回下一个词法单元，到达 EOF 后停留在 EOF
+func (p *filterTextParser) next() filterToken {
+	token := p.tokens[p.idx]
+	if token.kind != filterTokenEOF {
+		p.idx++
+	}
+	return token
+}
+
+// accept consumes the next token when it is the keyword or punctuation
+// accept 当下一个词法单元是该关键字或标点时消耗它
+func (p *filterTextParser) accept(text string) bool {
+	if token := p.peek(); (token.kind == filterTokenKeyword || token.kind == filterTokenPunct) && token.text == text {
+		p.idx++
+		return true
+	}
+	return false
+}
+
+// expect consumes the keyword or punctuation, failing when the next token is another one
+// expect 消耗该关键字或标点，下一个词法单元不是它时返回错误
+func (p *filterTextParser) expect(text string) error {
+	if token := p.peek(); !p.accept(text) {
+		return newFilterTextError(token.offset, ErrFilterSyntax, "expected %q but got %s", text, token.describe())
+	}
+	return nil
+}
+
+// parseOr parses: and { OR and }
+// parseOr 解析：and { OR and }
+func (p *filterTextParser) parseOr(depth int) (*QxConjunction, error) {
+	qx, err := p.parseAnd(depth)
+	if err != nil {
+		return nil, err
+	}
+	for p.accept("OR") {
+		item, err := p.parseAnd(depth)
+		if err != nil {
+			return nil, err
+		}
+		qx = qx.OR(item)
+	}
+	return qx, nil
+}
+
+// parseAnd parses: unary { AND unary }
+// parseAnd 解析：unary { AND unary }
+func (p *filterTextParser) parseAnd(depth int) (*QxConjunction, error) {
+	qx, err := p.parseUnary(depth)
+	if err != nil {
+		return nil, err
+	}
+	for p.accept("AND") {
+		item, err := p.parseUnary(depth)
+		if err != nil {
+			return nil, err
+		}
+		qx = qx.AND(item)
+	}
+	return qx, nil
+}
+
+// parseUnary parses: NOT unary | "(" or ")" | comparison
+// parseUnary 解析：NOT unary | "(" or ")" | comparison
+func (p *filterTextParser) parseUnary(depth int) (*QxConjunction, error) {
+	if token := p.peek(); depth > maxFilterDepth {
+		return nil, newFilterTextError(token.offset, ErrFilterSyntax, "nested deeper than %d", maxFilterDepth)
+	}
+	if p.accept("NOT") {
+		qx, err := p.parseUnary(depth + 1)
+		if err != nil {
+			return nil, err
+		}
+		return qx.NOT(), nil
+	}
+	if p.accept("(") {
+		qx, err := p.parseOr(depth + 1)
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expect(")"); err != nil {
+			return nil, err
+		}
+		return qx, nil
+	}
+	return p.parseComparison()
+}
+
+// parseComparison parses a comparison of a field, checking the field, the operator and each literal at their offsets
+// parseComparison 解析字段的比较，并在各自的偏移处检查字段、运算符和每个字面量
+func (p *filterTextParser) parseComparison() (*QxConjunction, error) {
+	var fieldToken = p.next()
+	if fieldToken.kind != filterTokenIdent {
+		return nil, newFilterTextError(fieldToken.offset, ErrFilterSyntax, "expected a field but got %s", fieldToken.describe())
+	}
+	field, err := p.registry.lookup(fieldToken.text)
+	if err != nil {
+		return nil, &FilterTextError{Offset: fieldToken.offset, Err: err}
+	}
+	var opToken = p.peek()
+	op, literals, err := p.parseOperation()
+	if err != nil {
+		return nil, err
+	}
+	if !field.operators[op] {
+		return nil, newFilterTextError(opToken.offset, ErrFilterOperator, "operator %q on field %q", op, fieldToken.text)
+	}
+	var values = make([]json.RawMessage, 0, len(literals))
+	for _, literal := range literals {
+		value := literal.value()
+		if filterArities[op] != filterArityText {
+			if err := field.check(value); err != nil {
+				return nil, &FilterTextError{Offset: literal.offset, Err: errors.WithMessagef(err, "field %q", fieldToken.text)}
+			}
+		}
+		values = append(values, value)
+	}
+	var value json.RawMessage
+	switch filterArities[op] {
+	case filterArityNone:
+	case filterArityList, filterArityPair:
+		value = joinRawMessages(values)
+	default:
+		value = values[0]
+	}
+	qx, err := field.build(op, value)
+	if err != nil {
+		return nil, &FilterTextError{Offset: opToken.offset, Err: err}
+	}
+	return qx, nil
+}
+
+// parseOperation parses the operator with its literals after the field
+// parseOperation 解析字段之后的运算符及其字面量
+func (p *filterTextParser) parseOperation() (FilterOp, []filterToken, error) {
+	var token = p.next()
+	switch {
+	case token.kind == filterTokenOperator:
+		literal, err := p.parseLiteral()
+		if err != nil {
+			return "", nil, err
+		}
+		return filterSymbolOps[token.text], []filterToken{literal}, nil
+	case token.kind != filterTokenKeyword:
+		return "", nil, newFilterTextError(token.offset, ErrFilterSyntax, "expected an operator but got %s", token.describe())
+	case token.text == "IS":
+		var not = p.accept("NOT")
+		var word = p.next()
+		var ops = map[string][2]FilterOp{
+			"NULL":  {FilterIsNull, FilterIsNotNull},
+			"TRUE":  {FilterIsTrue, FilterIsFalse},
+			"FALSE": {FilterIsFalse, FilterIsTrue},
+		}
+		pair, ok := ops[word.text]
+		if word.kind != filterTokenKeyword || !ok {
+			return "", nil, newFilterTextError(word.offset, ErrFilterSyntax, "expected NULL, TRUE or FALSE but got %s", word.describe())
+		}
+		if not {
+			return pair[1], nil, nil
+		}
+		return pair[0], nil, nil
+	}
+	var not = token.text == "NOT"
+	if not {
+		token = p.next()
+	}
+	switch {
+	case token.kind == filterTokenKeyword && token.text == "IN":
+		literals, err := p.parseList()
+		if err != nil {
+			return "", nil, err
+		}
+		return pickFilterOp(not, FilterIn, FilterNotIn), literals, nil
+	case token.kind == filterTokenKeyword && token.text == "LIKE":
+		literal, err := p.parseLiteral()
+		if err != nil {
+			return "", nil, err
+		}
+		return pickFilterOp(not, FilterLike, FilterNotLike), []filterToken{literal}, nil
+	case token.kind == filterTokenKeyword && token.text == "BETWEEN":
+		low, err := p.parseLiteral()
+		if err != nil {
+			return "", nil, err
+		}
+		if err := p.expect("AND"); err != nil {
+			return "", nil, err
+		}
+		high, err := p.parseLiteral()
+		if err != nil {
+			return "", nil, err
+		}
+		return pickFilterOp(not, FilterBetween, FilterNotBetween), []filterToken{low, high}, nil
+	}
+	return "", nil, newFilterTextError(token.offset, ErrFilterSyntax, "expected an operator but got %s", token.describe())
+}
+
+// pickFilterOp returns the negated operator when not is set
+// pickFilterOp 当 not 为真时返回取反的运算符
+func pickFilterOp(not bool, op FilterOp, notOp FilterOp) FilterOp {
+	if not {
+		return notOp
+	}
+	return op
+}
+
+// parseList parses: "(" literal { "," literal } ")"
+// parseList 解析："(" literal { "," literal } ")"
+func (p *filterTextParser) parseList() ([]filterToken, error) {
+	if err := p.expect("("); err != nil {
+		return nil, err
+	}
+	var literals []filterToken
+	for {
+		literal, err := p.parseLiteral()
+		if err != nil {
+			return nil, err
+		}
+		literals = append(literals, literal)
+		if !p.accept(",") {
+			break
+		}
+	}
+	if err := p.expect(")"); err != nil {
+		return nil, err
+	}
+	return literals, nil
+}
+
+// parseLiteral parses a string, number, true, false or null literal
+// parseLiteral 解析字符串、数字、true、false 或 null 字面量
+func (p *filterTextParser) parseLiteral() (filterToken, error) {
+	var token = p.next()
+	switch token.kind {
+	case filterTokenString, filterTokenNumber:
+		return token, nil
+	case filterTokenKeyword:
+		if token.text == "NULL" || token.text == "TRUE" || token.text == "FALSE" {
+			return token, nil
+		}
+	}
+	return filterToken{}, newFilterTextError(token.offset, ErrFilterSyntax, "expected a value but got %s", token.describe())
+}
+
+// value converts the literal token into the JSON value, decoded into the column type later
+// value 将字面量词法单元转换为 JSON 值，之后再解码为列的类型
+func (token filterToken) value() json.RawMessage {
+	switch token.kind {
+	case filterTokenString:
+		data, _ := json.Marshal(token.text)
+		return data
+	case filterTokenKeyword:
+		return json.RawMessage(strings.ToLower(token.text))
+	default:
+		return json.RawMessage(token.text)
+	}
+}
+
+// joinRawMessages joins the JSON values into a JSON array
+// joinRawMessages 将 JSON 值连接为 JSON 数组
+func joinRawMessages(values []json.RawMessage) json.RawMessage {
+	var parts = make([]string, 0, len(values))
+	for _, value := range values {
+		parts = append(parts, string(value))
+	}
+	return json.RawMessage("[" + strings.Join(parts, ",") + "]")
+}
@@ -0,0 +1,131 @@
+// Package gormcnm tests validate the text filter language bound to the registry
+// Auto verifies the precedence of NOT, AND, OR and the literals converted into the column types
+// Tests examine SQLite execution of parsed filters and the offsets of the errors
+//
+// gormcnm 测试包验证绑定到注册表的文本过滤语言
+// 自动验证 NOT、AND、OR 的优先级以及转换为列类型的字面量
+// 测试涵盖解析后过滤条件的 SQLite 执行以及错误的偏移
+package gormcnm
+
+import (
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+	"github.com/yyle88/gormcnm/internal/tests"
+	"gorm.io/gorm"
+)
+
+func TestFilterRegistry_ParseText(t *testing.T) {
+	type Example struct {
+		Name   string  `gorm:"primary_key;type:varchar(100);"`
+		Type   string  `gorm:"column:type;"`
+		Rank   int     `gorm:"column:rank;"`
+		Remark *string `gorm:"column:remark;"`
+	}
+
+	const (
+		columnName   = ColumnName[string]("name")
+		columnType   = ColumnName[string]("type")
+		columnRank   = ColumnName[int]("rank")
+		columnRemark = ColumnName[*string]("remark")
+	)
+
+	registry := NewFilterRegistry()
+	RegisterFilterColumn(registry, columnName)
+	RegisterFilterColumn(registry, columnType)
+	RegisterFilterField(registry, "score", columnRank)
+	RegisterFilterColumn(registry, columnRemark, FilterIsNull, FilterIsNotNull)
+
+	qx, err := registry.ParseText(`score > 100 AND (type = "xyz" OR name LIKE 'a%')`)
+	require.NoError(t, err)
+	require.Equal(t, "rank>? AND (type=? OR name LIKE ?)", qx.Qs())
+	require.Equal(t, []interface{}{100, "xyz", "a%"}, qx.Args())
+
+	qx, err = registry.ParseText(`not name in ("a", "b") or score between 1 and 2 and remark is not null`)
+	require.NoError(t, err)
+	require.Equal(t, "NOT (name IN(?)) OR ((rank BETWEEN ? AND ?) AND remark IS NOT NULL)", qx.Qs())
+	require.Equal(t, []interface{}{[]string{"a", "b"}, 1, 2}, qx.Args())
+
+	qx, err = registry.ParseText(`name = "it's \"quoted\" ' OR 1=1 --"`)
+	require.NoError(t, err)
+	require.Equal(t, "name=?", qx.Qs()) // The user text only goes to the arguments
+	require.Equal(t, []interface{}{`it's "quoted" ' OR 1=1 --`}, qx.Args())
+
+	qx, err = registry.ParseText("  ")
+	require.NoError(t, err)
+	require.True(t, qx.IsEmpty())
+
+	// No empty condition hides under NOT or OR, the grammar needs a comparison inside the parentheses
+	// NOT 或 OR 下不会出现空条件，语法要求括号内有比较
+	for _, text := range []string{"NOT ()", `name = "a" OR ()`, "NOT"} {
+		_, err = registry.ParseText(text)
+		require.ErrorIs(t, err, ErrFilterSyntax, text)
+	}
+
+	tests.NewDBRun(t, func(db *gorm.DB) {
+		require.NoError(t, db.AutoMigrate(&Example{}))
+		remark := "ok"
+		require.NoError(t, db.Create(&[]*Example{
+			{Name: "abc", Type: "xyz", Rank: 1, Remark: &remark},
+			{Name: "aaa", Type: "xxx", Rank: 200},
+			{Name: "bbb", Type: "xyz", Rank: 300},
+			{Name: "ccc", Type: "uvw", Rank: 400},
+		}).Error)
+
+		selectNames := func(text string) []string {
+			qx, err := registry.ParseText(text)
+			require.NoError(t, err)
+			var names []string
+			require.NoError(t, db.Model(&Example{}).Scopes(qx.Scope()).Order(columnName.Name()).Pluck(columnName.Name(), &names).Error)
+			return names
+		}
+
+		require.Equal(t, []string{"aaa", "bbb"}, selectNames(`score > 100 AND (type = "xyz" OR name LIKE "a%")`))
+		require.Equal(t, []string{"abc"}, selectNames(`remark IS NOT NULL`))
+		require.Equal(t, []string{"aaa", "ccc"}, selectNames(`NOT type = "xyz"`))
+		require.Equal(t, []string{"bbb", "ccc"}, selectNames(`score NOT BETWEEN 1 AND 200`))
+	})
+}
+
+func TestFilterRegistry_ParseText_Errors(t *testing.T) {
+	const (
+		columnName = ColumnName[string]("name")
+		columnRank = ColumnName[int]("rank")
+	)
+
+	registry := NewFilterRegistry()
+	RegisterFilterColumn(registry, columnName, FilterEq, FilterIn)
+	RegisterFilterColumn(registry, columnRank)
+
+	type errorCase struct {
+		text   string
+		offset int
+		err    error
+	}
+	for _, item := range []errorCase{
+		{`rank > 1 AND secret = "x"`, 13, ErrFilterColumn},
+		{`rank > 1 AND name LIKE "a%"`, 18, ErrFilterOperator},
+		{`rank > "abc"`, 7, ErrFilterValue},
+		{`rank IN (1, 2.5, 3)`, 12, ErrFilterValue},
+		{`rank = null`, 7, ErrFilterValue},
+		{`rank > 1 AND`, 12, ErrFilterSyntax},
+		{`(rank > 1`, 9, ErrFilterSyntax},
+		{`rank > 1)`, 8, ErrFilterSyntax},
+		{`name = "abc`, 7, ErrFilterSyntax},
+		{`rank ! 1`, 5, ErrFilterSyntax},
+		{`rank == 1`, 6, ErrFilterSyntax},
+		{`rank IS 1`, 8, ErrFilterSyntax},
+		{`rank > 1 OR 名字 = "x"`, 12, ErrFilterSyntax},
+		{`rank > 1; DROP TABLE examples`, 8, ErrFilterSyntax},
+	} {
+		_, err := registry.ParseText(item.text)
+		var textError *FilterTextError
+		require.True(t, errors.As(err, &textError), item.text)
+		require.Equal(t, item.offset, textError.Offset, item.text)
+		require.ErrorIs(t, err, item.err, item.text)
+	}
+
+	_, err := registry.ParseText(`rank > 1 AND secret = "x"`)
+	require.EqualError(t, err, `at position 14: field "secret": unknown filter field`)
+}
@@ -0,0 +1,499 @@
+// Package gormcnm provides the canonical form and the fingerprints of conditions, selects and orders, e.g. as cache keys
+// Auto sorts the commutative AND/OR children and normalizes the whitespace, thus equivalent conditions built in other orders match
+// Supports the shape fingerprint without the argument values (metric labels) and the cache key with them
+//
+// gormcnm 提供条件、选择和排序的规范形式及指纹，例如用作缓存键
+// 自动排序可交换的 AND/OR 子节点并规范化空白，使以不同顺序构建的等价条件一致
+// 支持不含参数值的形状指纹（用作指标标签）以及包含参数值的缓存键
+package gormcnm
+
+import (
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ErrFingerprintValue means an argument has no stable text to take part in the fingerprints, e.g. a function or a cyclic value
+// ErrFingerprintValue 表示参数没有可参与指纹计算的稳定文本，例如函数或循环引用的值
+var ErrFingerprintValue = errors.New("value without a stable fingerprint")
+
+// Fingerprintable is a part of the query taking part in the fingerprints, implemented by
+// *QxConjunction (WHERE), *SelectStatement (SELECT), OrderByBottle and *Ordering (ORDER BY)
+//
+// Fingerprintable 是参与指纹计算的查询组成部分，由
+// *QxConjunction（WHERE）、*SelectStatement（SELECT）、OrderByBottle 和 *Ordering（ORDER BY）实现
+type Fingerprintable interface {
+	fingerprintTo(parts *fingerprintParts)
+}
+
+// fingerprintParts collects the parts of the query by clause
+// fingerprintParts 按子句收集查询的组成部分
+type fingerprintParts struct {
+	selects []*SelectStatement // SELECT parts in order // 按顺序排列的 SELECT 部分
+	wheres  []*QxConjunction   // WHERE parts, combined with AND // WHERE 部分，使用 AND 组合
+	orders  []OrderByBottle    // ORDER BY parts in order // 按顺序排列的 ORDER BY 部分
+}
+
+func (qx *QxConjunction) fingerprintTo(parts *fingerprintParts) {
+	parts.wheres = append(parts.wheres, qx)
+}
+
+func (sx *SelectStatement) fingerprintTo(parts *fingerprintParts) {
+	parts.selects = append(parts.selects, sx)
+}
+
+func (ob OrderByBottle) fingerprintTo(parts *fingerprintParts) {
+	parts.orders = append(parts.orders, ob)
+}
+
+// Canonical returns the canonical form of the query parts with "?" in place of the values, e.g. "SELECT name WHERE name=? AND rank>? ORDER BY rank DESC".
+// The WHERE parts are combined with AND, the commutative AND/OR children are sorted, the whitespace is normalized,
+// and the lazy dialect statements are spelled in the default dialect. The SELECT and ORDER BY parts keep their order.
+// The values sort the AND/OR children of the same shape, thus it fails with ErrFingerprintValue like CacheKey.
+//
+// Canonical 返回查询组成部分的规范形式，值以 "?" 表示，例如 "SELECT name WHERE name=? AND rank>? ORDER BY rank DESC"。
+// WHERE 部分使用 AND 组合，可交换的 AND/OR 子节点会被排序，空白会被规范化，
+// 延迟渲染的方言语句使用默认方言拼写。SELECT 和 ORDER BY 部分保持原有顺序。
+// 值参与相同形状的 AND/OR 子节点的排序，因此与 CacheKey 一样可能返回 ErrFingerprintValue。
+func Canonical(parts ...Fingerprintable) (string, error) {
+	stmt, _, err := canonicalParts(parts)
+	if err != nil {
+		return "", err
+	}
+	return stmt, nil
+}
+
+// Fingerprint returns the hash of the canonical form without the values, the same for the queries of the same shape, e.g. as metric labels
+// Fingerprint 返回不含值的规范形式的哈希，相同形状的查询结果相同，例如用作指标标签
+func Fingerprint(parts ...Fingerprintable) (string, error) {
+	stmt, _, err := canonicalParts(parts)
+	if err != nil {
+		return "", err
+	}
+	return hashFingerprint(stmt), nil
+}
+
+// CacheKey returns the hash of the canonical form with the values, the same for the equivalent queries, e.g. as cache keys
+// The values are encoded by their contents, pointers by their targets.
+// Fails with ErrFingerprintValue on the values without a stable text, e.g. functions, channels and cyclic values.
+//
+// CacheKey 返回包含值的规范形式的哈希，等价的查询结果相同，例如用作缓存键
+// 值按其内容编码，指针按其指向的值编码。
+// 遇到没有稳定文本的值时返回 ErrFingerprintValue，例如函数、通道以及循环引用的值。
+func CacheKey(parts ...Fingerprintable) (string, error) {
+	stmt, args, err := canonicalParts(parts)
+	if err != nil {
+		return "", err
+	}
+	text, err := encodeFingerprintArgs(args)
+	if err != nil {
+		return "", err
+	}
+	return hashFingerprint(stmt + "\n" + text), nil
+}
+
+// hashFingerprint returns the first 128 bits of the SHA-256 of the text in hex
+// hashFingerprint 返回文本 SHA-256 的前 128 位的十六进制表示
+func hashFingerprint(text string) string {
+	sum := sha256.Sum256([]byte(text))
+	return hex.EncodeToString(sum[:16])
+}
+
+// canonicalParts returns the canonical form of the parts and the values in the order of the placeholders
+// canonicalParts 返回组成部分的规范形式，以及按占位符顺序排列的值
+func canonicalParts(items []Fingerprintable) (string, []interface{}, error) {
+	var parts fingerprintParts
+	for _, item := range items {
+		if item != nil {
+			item.fingerprintTo(&parts)
+		}
+	}
+	var clauses []string
+	var args []interface{}
+	if len(parts.selects) > 0 {
+		var columns = make([]string, 0, len(parts.selects))
+		for _, sx := range parts.selects {
+			stmt, values := canonicalStatement(sx.stmt, sx.args)
+			columns = append(columns, stmt)
+			args = append(args, values...)
+		}
+		clauses = append(clauses, "SELECT "+strings.Join(columns, ", "))
+	}
+	if qx := QxAND(parts.wheres...); !qx.IsEmpty() {
+		stmt, values, err := canonicalNode(qx.Node())
+		if err != nil {
+			return "", nil, err
+		}
+		clauses = append(clauses, "WHERE "+stmt)
+		args = append(args, values...)
+	}
+	if len(parts.orders) > 0 {
+		var items []string
+		for _, ob := range parts.orders {
+			for _, item := range strings.Split(string(ob), ",") {
+				if item = canonicalOrderItem(item); item != "" {
+					items = append(items, item)
+				}
+			}
+		}
+		clauses = append(clauses, "ORDER BY "+strings.Join(items, ", "))
+	}
+	return strings.Join(clauses, " "), args, nil
+}
+
+// canonicalOrderItem normalizes the whitespace of the ORDER BY item, with the modifiers after the column in upper case
+// canonicalOrderItem 规范化 ORDER BY 项的空白，并将列名后的修饰词转为大写
+func canonicalOrderItem(item string) string {
+	fields := strings.Fields(item)
+	if len(fields) > 1 && isOrderModifiers(fields[1:]) {
+		for idx := 1; idx < len(fields); idx++ {
+			fields[idx] = strings.ToUpper(fields[idx])
+		}
+		return strings.Join(fields, " ")
+	}
+	return normalizeWhitespace(item)
+}
+
+// canonicalNode returns the canonical form of the tree node, sorting the AND/OR children by their canonical forms and values
+// canonicalNode 返回条件树节点的规范形式，按子节点的规范形式和值对 AND/OR 子节点排序
+func canonicalNode(node *QxNode) (string, []interface{}, error) {
+	switch node.Kind {
+	case QxKindNOT:
+		stmt, args, err := canonicalNode(node.Children[0])
+		if err != nil {
+			return "", nil, err
+		}
+		return "NOT (" + stmt + ")", args, nil
+	case QxKindAND, QxKindOR:
+		type canonicalChild struct {
+			stmt string        // Canonical statement // 规范形式的语句
+			args []interface{} // Values // 参数值
+			key  string        // Sorting key // 排序键
+		}
+		var children = make([]canonicalChild, 0, len(node.Children))
+		for _, child := range node.Children {
+			stmt, args, err := canonicalNode(child)
+			if err != nil {
+				return "", nil, err
+			}
+			if child.needsParentheses(node.Kind) {
+				stmt = "(" + stmt + ")"
+			}
+			text, err := encodeFingerprintArgs(args)
+			if err != nil {
+				return "", nil, err
+			}
+			children = append(children, canonicalChild{stmt: stmt, args: args, key: stmt + "\n" + text})
+		}
+		sort.SliceStable(children, func(i, j int) bool {
+			return children[i].key < children[j].key
+		})
+		var stmts = make([]string, 0, len(children))
+		var args []interface{}
+		for _, child := range children {
+			stmts = append(stmts, child.stmt)
+			args = append(args, child.args...)
+		}
+		return strings.Join(stmts, " "+string(node.Kind)+" "), args, nil
+	}
+	stmt, args := canonicalStatement(node.Stmt, node.Args)
+	return stmt, args, nil
+}
+
+// canonicalStatement normalizes the statement, inlining the lazy dialect statements and the expression arguments,
+// the named arguments (sql.NamedArg and maps) go after the positional ones, sorted by name
+//
+// canonicalStatement 规范化语句，内联延迟渲染的方言语句和表达式参数，
+// 命名参数（sql.NamedArg 和 map）排在位置参数之后，并按名称排序
+func canonicalStatement(stmt string, args []interface{}) (string, []interface{}) {
+	var positional []interface{}
+	var named []sql.NamedArg
+	for _, arg := range args {
+		switch value := arg.(type) {
+		case sql.NamedArg:
+			named = append(named, value)
+		case map[string]interface{}:
+			for name, item := range value {
+				named = append(named, sql.Named(name, item))
+			}
+		default:
+			positional = append(positional, arg)
+		}
+	}
+	var builder = &fingerprintBuilder{}
+	var last, idx int
+	walkPlaceholders(stmt, func(start, end int) {
+		if stmt[start] != '?' || idx >= len(positional) {
+			return
+		}
+		builder.sb.WriteString(stmt[last:start])
+		builder.AddVar(&builder.sb, positional[idx])
+		last = end
+		idx++
+	})
+	builder.sb.WriteString(stmt[last:])
+	sort.SliceStable(named, func(i, j int) bool {
+		return named[i].Name < named[j].Name
+	})
+	for _, arg := range named {
+		builder.args = append(builder.args, arg)
+	}
+	return normalizeWhitespace(builder.sb.String()), builder.args
+}
+
+// fingerprintBuilder is the clause.Builder collecting the canonical statement, writing the values as "?"
+// fingerprintBuilder 是收集规范语句的 clause.Builder，将值写为 "?"
+type fingerprintBuilder struct {
+	sb   strings.Builder // Canonical statement // 规范形式的语句
+	args []interface{}   // Values in order // 按顺序排列的值
+}
+
+func (b *fingerprintBuilder) WriteByte(c byte) error {
+	return b.sb.WriteByte(c)
+}
+
+func (b *fingerprintBuilder) WriteString(s string) (int, error) {
+	return b.sb.WriteString(s)
+}
+
+func (b *fingerprintBuilder) WriteQuoted(field interface{}) {
+	switch value := field.(type) {
+	case clause.Column:
+		if value.Table != "" {
+			b.sb.WriteString(value.Table + ".")
+		}
+		b.sb.WriteString(value.Name)
+	case clause.Table:
+		b.sb.WriteString(value.Name)
+	default:
+		_, _ = fmt.Fprint(&b.sb, value)
+	}
+}
+
+// AddVar inlines the lazy dialect statements, the expressions and the subqueries, and writes the other values as "?"
+// AddVar 内联延迟渲染的方言语句、表达式和子查询，其他值写为 "?"
+func (b *fingerprintBuilder) AddVar(writer clause.Writer, vars ...interface{}) {
+	for idx, value := range vars {
+		if idx > 0 {
+			_ = writer.WriteByte(',')
+		}
+		switch x := value.(type) {
+		case *DialectExpression:
+			stmt, args := canonicalStatement(x.Render(Dialect("")))
+			_, _ = writer.WriteString(stmt)
+			b.args = append(b.args, args...)
+		case *gorm.DB:
+			stmt := &gorm.Statement{DB: x, Context: x.Statement.Context, Clauses: map[string]clause.Clause{}}
+			stmt.AddVar(stmt, x)
+			_, _ = writer.WriteString(stmt.SQL.String())
+			b.args = append(b.args, stmt.Vars...)
+		case clause.Expression:
+			x.Build(b)
+		default:
+			_ = writer.WriteByte('?')
+			b.args = append(b.args, value)
+		}
+	}
+}
+
+func (b *fingerprintBuilder) AddError(err error) error {
+	return err
+}
+
+// normalizeWhitespace collapses the whitespace outside the quoted parts into single spaces,
+// dropping it next to the operators and inside the parentheses, e.g. "name = ?" and "name=?" are the same
+//
+// normalizeWhitespace 将引号之外的连续空白合并为单个空格，
+// 并去掉运算符旁边和括号内侧的空白，例如 "name = ?" 与 "name=?" 相同
+func normalizeWhitespace(stmt string) string {
+	const tightNext, tightPrev = "=<>!(),", "=<>!(,"
+	var sb strings.Builder
+	var space bool
+	for idx := 0; idx < len(stmt); idx++ {
+		c := stmt[idx]
+		switch c {
+		case ' ', '\t', '\n', '\r':
+			space = true
+			continue
+		}
+		if space && sb.Len() > 0 && !strings.ContainsRune(tightNext, rune(c)) && !strings.ContainsRune(tightPrev, rune(sb.String()[sb.Len()-1])) {
+			sb.WriteByte(' ')
+		}
+		space = false
+		if c == '\'' || c == '"' || c == '`' {
+			end := skipQuoted(stmt, idx)
+			if end >= len(stmt) {
+				end = len(stmt) - 1
+			}
+			sb.WriteString(stmt[idx : end+1])
+			idx = end
+			continue
+		}
+		sb.WriteByte(c)
+	}
+	return sb.String()
+}
+
+// encodeFingerprintArgs encodes the values into a stable text, numbers by value whatever their Go types, pointers by their targets
+// encodeFingerprintArgs 将值编码为稳定的文本，数字按值编码而与 Go 类型无关，指针按其指向的值编码
+func encodeFingerprintArgs(args []interface{}) (string, error) {
+	var encoder = &fingerprintEncoder{visiting: map[fingerprintVisit]bool{}}
+	var items = make([]string, 0, len(args))
+	for _, arg := range args {
+		item, err := encoder.encodeValue(arg)
+		if err != nil {
+			return "", err
+		}
+		items = append(items, item)
+	}
+	return strings.Join(items, ","), nil
+}
+
+// fingerprintVisit identifies a pointer, map or slice being encoded
+// fingerprintVisit 标识正在编码的指针、map 或切片
+type fingerprintVisit struct {
+	typ reflect.Type // Type of the value // 值的类型
+	ptr uintptr      // Address of the target // 目标的地址
+}
+
+// fingerprintEncoder encodes the values, tracking the pointers, maps and slices on the way down to reject the cyclic values
+// fingerprintEncoder 编码值，并跟踪向下遍历途中的指针、map 和切片以拒绝循环引用的值
+type fingerprintEncoder struct {
+	visiting map[fingerprintVisit]bool // Values being encoded, the ancestors of the current one // 正在编码的值，即当前值的祖先
+}
+
+// encodeValue encodes the value into a stable text, see encodeFingerprintArgs
+// encodeValue 将值编码为稳定的文本，见 encodeFingerprintArgs
+func (encoder *fingerprintEncoder) encodeValue(value interface{}) (string, error) {
+	leave, err := encoder.enter(reflect.ValueOf(value))
+	if err != nil {
+		return "", err
+	}
+	defer leave()
+	value = normalizeMatchValue(value)
+	switch x := value.(type) {
+	case nil:
+		return "NULL", nil
+	case sql.NamedArg:
+		text, err := encoder.encodeValue(x.Value)
+		if err != nil {
+			return "", err
+		}
+		return "@" + x.Name + "=" + text, nil
+	case time.Time:
+		return "t" + strconv.Quote(x.UTC().Format(time.RFC3339Nano)), nil
+	case []byte:
+		return "x'" + hex.EncodeToString(x) + "'", nil
+	}
+	return encoder.encodeKind(reflect.ValueOf(value))
+}
+
+// encodeKind encodes the value by its kind, structs by their fields (the unexported ones too) and maps by their sorted entries.
+// Pointers are encoded by their targets, thus the text never holds an address.
+// Fails with ErrFingerprintValue on the kinds without a stable text, e.g. functions, and on the values holding themselves.
+//
+// encodeKind 按值的种类编码，结构体按其字段（包括未导出字段）编码，map 按排序后的键值对编码。
+// 指针按其指向的值编码，因此文本中不会包含地址。
+// 遇到没有稳定文本的种类（例如函数）以及包含自身的值时返回 ErrFingerprintValue。
+func (encoder *fingerprintEncoder) encodeKind(rv reflect.Value) (string, error) {
+	switch rv.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		if rv.IsNil() {
+			return "NULL", nil
+		}
+		return encoder.encodeElem(rv.Elem())
+	case reflect.Bool:
+		return strconv.FormatBool(rv.Bool()), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(rv.Int(), 10), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return strconv.FormatUint(rv.Uint(), 10), nil
+	case reflect.Float32, reflect.Float64:
+		return strconv.FormatFloat(rv.Float(), 'g', -1, 64), nil
+	case reflect.Complex64, reflect.Complex128:
+		return strconv.FormatComplex(rv.Complex(), 'g', -1, 128), nil
+	case reflect.String:
+		return strconv.Quote(rv.String()), nil
+	case reflect.Slice, reflect.Array:
+		var items = make([]string, 0, rv.Len())
+		for idx := 0; idx < rv.Len(); idx++ {
+			item, err := encoder.encodeElem(rv.Index(idx))
+			if err != nil {
+				return "", err
+			}
+			items = append(items, item)
+		}
+		return "(" + strings.Join(items, ",") + ")", nil
+	case reflect.Map:
+		var items = make([]string, 0, rv.Len())
+		for iter := rv.MapRange(); iter.Next(); {
+			key, err := encoder.encodeElem(iter.Key())
+			if err != nil {
+				return "", err
+			}
+			value, err := encoder.encodeElem(iter.Value())
+			if err != nil {
+				return "", err
+			}
+			items = append(items, key+":"+value)
+		}
+		sort.Strings(items)
+		return rv.Type().String() + "{" + strings.Join(items, ",") + "}", nil
+	case reflect.Struct:
+		var items = make([]string, 0, rv.NumField())
+		for idx := 0; idx < rv.NumField(); idx++ {
+			item, err := encoder.encodeElem(rv.Field(idx))
+			if err != nil {
+				return "", err
+			}
+			items = append(items, rv.Type().Field(idx).Name+":"+item)
+		}
+		return rv.Type().String() + "{" + strings.Join(items, ",") + "}", nil
+	}
+	return "", errors.WithMessagef(ErrFingerprintValue, "%s value", rv.Type())
+}
+
+// encodeElem encodes the nested value, the accessible ones through encodeValue to resolve their driver.Valuer
+// encodeElem 编码嵌套的值，可访问的值通过 encodeValue 编码以解析其 driver.Valuer
+func (encoder *fingerprintEncoder) encodeElem(rv reflect.Value) (string, error) {
+	if rv.CanInterface() {
+		return encoder.encodeValue(rv.Interface())
+	}
+	leave, err := encoder.enter(rv)
+	if err != nil {
+		return "", err
+	}
+	defer leave()
+	return encoder.encodeKind(rv)
+}
+
+// enter marks the pointer, map or slice as being encoded, failing when it is already, i.e. the value holds itself.
+// Called before normalizeMatchValue dereferences the pointers. The returned leave unmarks it.
+//
+// enter 将指针、map 或切片标记为正在编码，若已被标记（即值包含自身）则返回错误。
+// 在 normalizeMatchValue 解引用指针之前调用。返回的 leave 用于取消标记。
+func (encoder *fingerprintEncoder) enter(rv reflect.Value) (func(), error) {
+	switch rv.Kind() {
+	case reflect.Ptr, reflect.Map, reflect.Slice:
+		if rv.IsNil() {
+			return func() {}, nil
+		}
+		var visit = fingerprintVisit{typ: rv.Type(), ptr: rv.Pointer()}
+		if encoder.visiting[visit] {
+			return nil, errors.WithMessagef(ErrFingerprintValue, "cyclic %s value", rv.Type())
+		}
+		encoder.visiting[visit] = true
+		return func() { delete(encoder.visiting, visit) }, nil
+	}
+	return func() {}, nil
+}
@@ -0,0 +1,148 @@
+// Package gormcnm tests validate the canonical form and the fingerprints of the query parts
+// Auto verifies the sorted AND/OR children, the normalized whitespace and the stable values
+// Tests examine the shapes with and without the values, dialect statements and subqueries
+//
+// gormcnm 测试包验证查询组成部分的规范形式和指纹
+// 自动验证排序后的 AND/OR 子节点、规范化的空白以及稳定的值
+// 测试涵盖含值与不含值的形状、方言语句和子查询
+package gormcnm
+
+import (
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/yyle88/gormcnm/internal/tests"
+	"github.com/yyle88/rese"
+)
+
+func TestCanonical(t *testing.T) {
+	const (
+		columnName = ColumnName[string]("name")
+		columnType = ColumnName[string]("type")
+		columnRank = ColumnName[int]("rank")
+	)
+
+	qx1 := Qx(columnName.Eq("abc")).AND(Qx(columnType.In([]string{"x", "y"})).OR(Qx(columnRank.Gt(1))))
+	qx2 := Qx("rank  >  ?", 1).OR(Qx(columnType.In([]string{"x", "y"}))).AND(Qx("name = ?", "abc"))
+
+	require.Equal(t, "WHERE (rank>? OR type IN(?)) AND name=?", rese.V1(Canonical(qx1)))
+	require.Equal(t, rese.V1(Canonical(qx1)), rese.V1(Canonical(qx2)))
+	require.Equal(t, rese.V1(Fingerprint(qx1)), rese.V1(Fingerprint(qx2)))
+	require.Equal(t, rese.V1(CacheKey(qx1)), rese.V1(CacheKey(qx2)))
+	require.Len(t, rese.V1(Fingerprint(qx1)), 32)
+
+	sx := NewSelectStatement("name, COUNT(*) AS cnt")
+	ob := columnRank.Ob("desc").Ob(columnName.Ob("asc"))
+	require.Equal(t, "SELECT name,COUNT(*) AS cnt WHERE (rank>? OR type IN(?)) AND name=? ORDER BY rank DESC, name ASC", rese.V1(Canonical(sx, qx1, ob)))
+	require.Equal(t, rese.V1(CacheKey(sx, qx1, ob)), rese.V1(CacheKey(ob, qx2, sx)))
+	require.NotEqual(t, rese.V1(Fingerprint(sx, qx1)), rese.V1(Fingerprint(sx, qx1, ob)))
+	require.Equal(t, "", rese.V1(Canonical(NewEmptyQx())))
+}
+
+func TestFingerprint_Values(t *testing.T) {
+	const (
+		columnName = ColumnName[string]("name")
+		columnRank = ColumnName[int]("rank")
+	)
+
+	qx1 := Qx(columnName.Eq("abc")).AND(Qx(columnRank.Gt(1)))
+	qx2 := Qx(columnName.Eq("xyz")).AND(Qx(columnRank.Gt(2)))
+	require.Equal(t, rese.V1(Fingerprint(qx1)), rese.V1(Fingerprint(qx2)))
+	require.NotEqual(t, rese.V1(CacheKey(qx1)), rese.V1(CacheKey(qx2)))
+
+	// The sorting counts the values in, thus equal shapes with swapped values still match
+	// 排序时考虑值，因此交换了值的相同形状仍然一致
+	qx3 := Qx(columnName.Eq("abc")).OR(Qx(columnName.Eq("xyz")))
+	qx4 := Qx(columnName.Eq("xyz")).OR(Qx(columnName.Eq("abc")))
+	require.Equal(t, rese.V1(CacheKey(qx3)), rese.V1(CacheKey(qx4)))
+
+	// Numbers compare by value whatever their Go types, pointers by their targets
+	// 数字按值比较而与 Go 类型无关，指针按其指向的值比较
+	rank := int64(1)
+	require.Equal(t, rese.V1(CacheKey(Qx("rank=?", 1))), rese.V1(CacheKey(Qx("rank=?", &rank))))
+	require.NotEqual(t, rese.V1(CacheKey(Qx("rank=?", 1))), rese.V1(CacheKey(Qx("rank=?", "1"))))
+
+	moment := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	require.Equal(t, rese.V1(CacheKey(Qx("at=?", moment))), rese.V1(CacheKey(Qx("at=?", moment.In(time.FixedZone("UTC+8", 8*3600))))))
+
+	// The named arguments are sorted by name
+	// 命名参数按名称排序
+	qx5 := Qx("name=@name AND rank>@rank", sql.Named("rank", 1), sql.Named("name", "abc"))
+	qx6 := Qx("name=@name AND rank>@rank", map[string]interface{}{"name": "abc", "rank": 1})
+	require.Equal(t, rese.V1(CacheKey(qx5)), rese.V1(CacheKey(qx6)))
+
+	// The other values are encoded by their contents, never by their addresses
+	// 其他值按其内容编码，而不是按其地址编码
+	type Range struct {
+		Min *int
+		max *int
+	}
+	newRange := func(min, max int) Range { return Range{Min: &min, max: &max} }
+	require.Equal(t, rese.V1(CacheKey(Qx("rank=?", newRange(1, 2)))), rese.V1(CacheKey(Qx("rank=?", newRange(1, 2)))))
+	require.NotEqual(t, rese.V1(CacheKey(Qx("rank=?", newRange(1, 2)))), rese.V1(CacheKey(Qx("rank=?", newRange(1, 3)))))
+	require.Equal(t, rese.V1(CacheKey(Qx("rank=?", map[string]int{"a": 1, "b": 2}))), rese.V1(CacheKey(Qx("rank=?", map[string]int{"b": 2, "a": 1}))))
+
+	// The values without a stable text fail, never panic nor loop
+	// 没有稳定文本的值会返回错误，既不 panic 也不会无限递归
+	_, err := CacheKey(Qx("rank=?", func() {}))
+	require.ErrorIs(t, err, ErrFingerprintValue)
+	_, err = CacheKey(Qx("rank=?", make(chan int)))
+	require.ErrorIs(t, err, ErrFingerprintValue)
+	_, err = Canonical(Qx("rank=?", 1).OR(Qx("rank=?", func() {})))
+	require.ErrorIs(t, err, ErrFingerprintValue)
+
+	type Node struct {
+		Name string
+		Next *Node
+	}
+	cyclic := &Node{Name: "a"}
+	cyclic.Next = &Node{Name: "b", Next: cyclic}
+	_, err = CacheKey(Qx("rank=?", cyclic))
+	require.ErrorIs(t, err, ErrFingerprintValue)
+	type Link struct {
+		next *Link
+	}
+	loop := &Link{}
+	loop.next = loop
+	_, err = CacheKey(Qx("rank=?", loop))
+	require.ErrorIs(t, err, ErrFingerprintValue)
+	items := []interface{}{1}
+	items[0] = items
+	_, err = CacheKey(Qx("rank=?", items))
+	require.ErrorIs(t, err, ErrFingerprintValue)
+
+	// The same pointer twice is no cycle
+	// 同一指针出现两次并不是循环引用
+	one, shared := 1, &Node{Name: "s"}
+	require.Equal(t, rese.V1(CacheKey(Qx("rank=?", Range{Min: &one, max: &one}))), rese.V1(CacheKey(Qx("rank=?", newRange(1, 1)))))
+	require.NotEmpty(t, rese.V1(CacheKey(Qx("rank IN ?", []*Node{shared, shared}))))
+}
+
+func TestFingerprint_Expressions(t *testing.T) {
+	const columnName = ColumnName[string]("name")
+
+	qx1 := NewStringColumn(columnName).Contains("a_b")
+	qx2 := NewStringColumn(columnName).Contains("a_b")
+	require.Equal(t, rese.V1(CacheKey(qx1)), rese.V1(CacheKey(qx2)))
+	require.NotEqual(t, rese.V1(CacheKey(qx1)), rese.V1(CacheKey(NewStringColumn(columnName).Contains("a%b"))))
+	require.NotContains(t, rese.V1(Canonical(qx1)), "?,") // The lazy dialect statement is inlined
+
+	db := newSubqueryDB(t)
+	sub1 := subqueryOrderUserID.SubQuery(db.Model(&subqueryOrder{}).Select("user_id").Where("amount > ?", 100))
+	sub2 := subqueryOrderUserID.SubQuery(db.Model(&subqueryOrder{}).Select("user_id").Where("amount > ?", 200))
+	require.Equal(t, "WHERE id IN(SELECT `user_id` FROM `orders` WHERE amount>?)", rese.V1(Canonical(subqueryUserID.InSub(sub1))))
+	require.Equal(t, rese.V1(Fingerprint(subqueryUserID.InSub(sub1))), rese.V1(Fingerprint(subqueryUserID.InSub(sub2))))
+	require.NotEqual(t, rese.V1(CacheKey(subqueryUserID.InSub(sub1))), rese.V1(CacheKey(subqueryUserID.InSub(sub2))))
+
+	dryRun := tests.NewDryRunDB(t, "postgres")
+	sub3 := subqueryOrderUserID.SubQuery(dryRun.Model(&subqueryOrder{}).Select("user_id").Where("amount > ?", 100))
+	require.Equal(t, `WHERE id IN(SELECT "user_id" FROM "orders" WHERE amount>$1)`, rese.V1(Canonical(subqueryUserID.InSub(sub3))))
+}
+
+func TestNormalizeWhitespace(t *testing.T) {
+	require.Equal(t, "name=? AND rank IN(?,?)", normalizeWhitespace("  name =\t?  AND\nrank IN ( ?, ? ) "))
+	require.Equal(t, "name='a  b' OR type=\"x  y\"", normalizeWhitespace("name = 'a  b'  OR type = \"x  y\""))
+	require.Equal(t, "rank DESC, name ASC", canonicalOrderItem(" rank desc ")+", "+canonicalOrderItem("name  ASC"))
+}
@@ -1,9 +1,9 @@
 // Package gormcnmjson enables type-safe JSON column operations within GORM
-// Supports SQLite JSON functions with compile-time type checking
+// Renders the JSON functions lazily in the dialect of the DB: SQLite, MySQL, PostgreSQL and SQL Server
 // Works with both string and []byte JSON column types
 //
 // gormcnmjson 为 GORM 提供类型安全的 JSON 列操作
-// 支持 SQLite JSON 函数并提供编译时类型检查
+// 按数据库方言延迟渲染 JSON 函数：SQLite、MySQL、PostgreSQL 和 SQL Server
 // 同时支持 string 和 []byte 类型的 JSON 列
 package gormcnmjson
 
@@ -11,15 +11,19 @@ import (
 	"fmt"
 
 	"github.com/yyle88/gormcnm"
+	"gorm.io/gorm/clause"
 )
 
 // Column represents a JSON column with type-safe SQL operations
-// Provides methods to generate JSON-specific SQL expressions
+// The expressions are spelled when GORM builds the statement, in the dialect of the DB they are applied to
+// The JSON paths are written as escaped literals, and the values of Set are bound as query arguments
 //
 // Column 表示一个 JSON 列，提供类型安全的 SQL 操作
-// 提供生成 JSON 特定 SQL 表达式的方法
+// 表达式在 GORM 构建语句时按所应用的数据库方言生成
+// JSON 路径写为转义后的字面量，Set 的值以查询参数绑定
 type Column struct {
-	name string // Column name in database // 数据库中的列名
+	name   string                                                // Column name in database // 数据库中的列名
+	render func(dialect gormcnm.Dialect) (string, []interface{}) // Renders the JSON document in the dialect // 按方言渲染 JSON 文档
 }
 
 // New creates a Column from a ColumnName with generic type support
@@ -28,7 +32,7 @@ type Column struct {
 // New 从 ColumnName 创建 Column，支持泛型类型
 // 接受 string 和 []byte 列类型，提供多种选择
 func New[T ~string | ~[]byte](columnName gormcnm.ColumnName[T]) Column {
-	return Column{name: columnName.Name()}
+	return newColumn(columnName.Name())
 }
 
 // Raw creates a Column from a []byte-based ColumnName
@@ -37,49 +41,66 @@ func New[T ~string | ~[]byte](columnName gormcnm.ColumnName[T]) Column {
 // Raw 从基于 []byte 的 ColumnName 创建 Column
 // 专门用于 datatypes.JSON 和相关的 []byte 类型
 func Raw[T ~[]byte](columnName gormcnm.ColumnName[T]) Column {
-	return Column{name: columnName.Name()}
+	return newColumn(columnName.Name())
+}
+
+// newColumn creates a Column rendering the column name quoted in the dialect
+// newColumn 创建按方言给列名加引号的 Column
+func newColumn(name string) Column {
+	return Column{name: name, render: func(dialect gormcnm.Dialect) (string, []interface{}) {
+		return dialect.Quote(name), nil
+	}}
 }
 
 // Name returns the underlying column name as a string
-// Use this when you need the raw column name in SQL expressions
+// Use this when you need the raw column name in SQL expressions, e.g. as the column of Update
 //
 // Name 返回底层列名字符串
-// 当需要在 SQL 表达式中使用原始列名时使用此方法
+// 当需要在 SQL 表达式中使用原始列名时使用此方法，例如作为 Update 的列
 func (co Column) Name() string {
 	return co.name
 }
 
+// Build implements clause.Expression, rendering the JSON document in the dialect of the GORM statement
+// Thus the results of Set and Remove are passed to Update directly, e.g. Update(co.Name(), co.Set("price", 1099))
+//
+// Build 实现 clause.Expression 接口，按 GORM 语句的方言渲染 JSON 文档
+// 因此 Set 和 Remove 的结果可以直接传给 Update，例如 Update(co.Name(), co.Set("price", 1099))
+func (co Column) Build(builder clause.Builder) {
+	gormcnm.NewDialectExpression(co.render).Build(builder)
+}
+
 // Get extracts a JSON value as text using the ->> operation
-// Returns a type-safe string ColumnName to allow chaining conditions
+// Returns a typed string expression to allow chaining conditions, e.g. Get("brand").Eq("Apple")
 //
 // Get 使用 ->> 操作将 JSON 值提取为文本
-// 返回类型安全的字符串 ColumnName 用于链式条件
-func (co Column) Get(path string) gormcnm.ColumnName[string] {
-	return gormcnm.ColumnName[string](
-		fmt.Sprintf("%s ->> '$.%s'", co.name, path),
-	)
+// 返回类型化的字符串表达式用于链式条件，例如 Get("brand").Eq("Apple")
+func (co Column) Get(path string) *gormcnm.TypedExpr[string] {
+	return typedExpr[string](co, func(dialect gormcnm.Dialect, doc string) string {
+		return dialect.JSONGet(doc, path)
+	})
 }
 
 // Extract extracts a JSON sub-object using the -> operation
-// Returns a Column that supports nested operations
+// Returns a Column that supports nested operations, wrapped in parentheses thus the PostgreSQL "::jsonb" cast applies to the whole
 //
 // Extract 使用 -> 操作提取 JSON 子对象
-// 返回 Column 用于额外的嵌套操作
+// 返回 Column 用于额外的嵌套操作，使用括号包裹，使 PostgreSQL 的 "::jsonb" 转换作用于整体
 func (co Column) Extract(path string) Column {
-	return Column{
-		name: fmt.Sprintf("%s -> '$.%s'", co.name, path),
-	}
+	return co.derive(func(dialect gormcnm.Dialect, doc string) (string, []interface{}) {
+		return "(" + dialect.JSONExtract(doc, path) + ")", nil
+	})
 }
 
 // GetInt extracts a JSON value as an int with type casting
-// Returns a type-safe int ColumnName to use in numeric comparisons
+// Returns a typed int expression to use in numeric comparisons
 //
 // GetInt 将 JSON 值提取为整数并进行类型转换
-// 返回类型安全的 int ColumnName 用于数值比较
-func (co Column) GetInt(path string) gormcnm.ColumnName[int] {
-	return gormcnm.ColumnName[int](
-		fmt.Sprintf("CAST(%s ->> '$.%s' AS INTEGER)", co.name, path),
-	)
+// 返回类型化的 int 表达式用于数值比较
+func (co Column) GetInt(path string) *gormcnm.TypedExpr[int] {
+	return typedExpr[int](co, func(dialect gormcnm.Dialect, doc string) string {
+		return dialect.CastInteger(dialect.JSONGet(doc, path))
+	})
 }
 
 // Length returns the length of a JSON text/object using JSON_ARRAY_LENGTH
@@ -87,15 +108,19 @@ func (co Column) GetInt(path string) gormcnm.ColumnName[int] {
 //
 // Length 使用 JSON_ARRAY_LENGTH 返回 JSON 数组的长度
 // 如果 path 为空则测量根 JSON，否则测量嵌套路径
-func (co Column) Length(path string) gormcnm.ColumnName[int] {
-	if path == "" {
-		return gormcnm.ColumnName[int](
-			fmt.Sprintf("JSON_ARRAY_LENGTH(%s)", co.name),
-		)
-	}
-	return gormcnm.ColumnName[int](
-		fmt.Sprintf("JSON_ARRAY_LENGTH(%s, '$.%s')", co.name, path),
-	)
+func (co Column) Length(path string) *gormcnm.TypedExpr[int] {
+	return typedExpr[int](co, func(dialect gormcnm.Dialect, doc string) string {
+		switch dialect {
+		case gormcnm.DialectMySQL:
+			return function(dialect, "JSON_LENGTH", doc, path)
+		case gormcnm.DialectPostgres:
+			return fmt.Sprintf("jsonb_array_length(%s)", target(dialect, doc, path))
+		case gormcnm.DialectSQLServer:
+			return fmt.Sprintf("(SELECT COUNT(*) FROM %s)", function(dialect, "OPENJSON", doc, path))
+		default:
+			return function(dialect, "JSON_ARRAY_LENGTH", doc, path)
+		}
+	})
 }
 
 // Type returns the JSON type of a value using JSON_TYPE function
@@ -103,15 +128,17 @@ func (co Column) Length(path string) gormcnm.ColumnName[int] {
 //
 // Type 使用 JSON_TYPE 函数返回 JSON 值的类型
 // 如果 path 为空则检查根 JSON 类型，否则检查嵌套路径
-func (co Column) Type(path string) gormcnm.ColumnName[string] {
-	if path == "" {
-		return gormcnm.ColumnName[string](
-			fmt.Sprintf("JSON_TYPE(%s)", co.name),
-		)
-	}
-	return gormcnm.ColumnName[string](
-		fmt.Sprintf("JSON_TYPE(%s, '$.%s')", co.name, path),
-	)
+func (co Column) Type(path string) *gormcnm.TypedExpr[string] {
+	return typedExpr[string](co, func(dialect gormcnm.Dialect, doc string) string {
+		switch dialect {
+		case gormcnm.DialectMySQL:
+			return fmt.Sprintf("JSON_TYPE(%s)", target(dialect, doc, path))
+		case gormcnm.DialectPostgres:
+			return fmt.Sprintf("jsonb_typeof(%s)", target(dialect, doc, path))
+		default:
+			return function(dialect, "JSON_TYPE", doc, path)
+		}
+	})
 }
 
 // Valid checks if the JSON text has a valid format using JSON_VALID
@@ -119,21 +146,38 @@ func (co Column) Type(path string) gormcnm.ColumnName[string] {
 //
 // Valid 使用 JSON_VALID 检查 JSON 文本是否格式正确
 // 返回 1 表示有效的 JSON，返回 0 表示无效的 JSON
-func (co Column) Valid() gormcnm.ColumnName[int] {
-	return gormcnm.ColumnName[int](
-		fmt.Sprintf("JSON_VALID(%s)", co.name),
-	)
+func (co Column) Valid() *gormcnm.TypedExpr[int] {
+	return typedExpr[int](co, func(dialect gormcnm.Dialect, doc string) string {
+		switch dialect {
+		case gormcnm.DialectPostgres:
+			return fmt.Sprintf("(CASE WHEN %s IS JSON THEN 1 ELSE 0 END)", doc)
+		case gormcnm.DialectSQLServer:
+			return fmt.Sprintf("ISJSON(%s)", doc)
+		default:
+			return fmt.Sprintf("JSON_VALID(%s)", doc)
+		}
+	})
 }
 
 // Set updates a JSON value at the specified path using JSON_SET
+// The value is bound as a query argument, stored as the JSON string of its text
 // Returns a Column with the modified expression, intended to be used in UPDATE statements
 //
 // Set 使用 JSON_SET 在指定路径更新 JSON 值
+// 值以查询参数绑定，按其文本存储为 JSON 字符串
 // 返回包含修改表达式的 Column 用于 UPDATE 语句
 func (co Column) Set(path string, value interface{}) Column {
-	return Column{
-		name: fmt.Sprintf("JSON_SET(%s, '$.%s', '%v')", co.name, path, value),
-	}
+	return co.derive(func(dialect gormcnm.Dialect, doc string) (string, []interface{}) {
+		var text = fmt.Sprint(value)
+		switch dialect {
+		case gormcnm.DialectPostgres:
+			return fmt.Sprintf("jsonb_set(%s::jsonb, %s, to_jsonb(CAST(? AS text)))", doc, dialect.JSONPathLiteral(path)), []interface{}{text}
+		case gormcnm.DialectSQLServer:
+			return fmt.Sprintf("JSON_MODIFY(%s, %s, ?)", doc, dialect.JSONPathLiteral(path)), []interface{}{text}
+		default:
+			return fmt.Sprintf("JSON_SET(%s, %s, ?)", doc, dialect.JSONPathLiteral(path)), []interface{}{text}
+		}
+	})
 }
 
 // Remove deletes a value at the specified path using JSON_REMOVE
@@ -142,16 +186,69 @@ func (co Column) Set(path string, value interface{}) Column {
 // Remove 使用 JSON_REMOVE 删除指定路径的值
 // 返回包含删除表达式的 Column 用于 UPDATE 语句
 func (co Column) Remove(path string) Column {
-	return Column{
-		name: fmt.Sprintf("JSON_REMOVE(%s, '$.%s')", co.name, path),
-	}
+	return co.derive(func(dialect gormcnm.Dialect, doc string) (string, []interface{}) {
+		switch dialect {
+		case gormcnm.DialectPostgres:
+			return fmt.Sprintf("%s::jsonb #- %s", doc, dialect.JSONPathLiteral(path)), nil
+		case gormcnm.DialectSQLServer:
+			return fmt.Sprintf("JSON_MODIFY(%s, %s, NULL)", doc, dialect.JSONPathLiteral(path)), nil
+		default:
+			return fmt.Sprintf("JSON_REMOVE(%s, %s)", doc, dialect.JSONPathLiteral(path)), nil
+		}
+	})
 }
 
 // AsAlias creates a column alias, intended to be used in SELECT statements
-// Returns the column expression with the specified alias name
+// Returns the select statement of the JSON document with the specified alias name
 //
 // AsAlias 为 SELECT 语句创建列别名
-// 返回带有指定别名的列表达式
-func (co Column) AsAlias(alias string) string {
-	return co.name + " as " + alias
+// 返回带有指定别名的 JSON 文档选择语句
+func (co Column) AsAlias(alias string) *gormcnm.SelectStatement {
+	return gormcnm.NewDialectSx(func(dialect gormcnm.Dialect) (string, []interface{}) {
+		stmt, args := co.render(dialect)
+		return stmt + " as " + alias, args
+	})
+}
+
+// derive returns a Column of the same name, rendering the expression built on the JSON document of this Column
+// derive 返回同名的 Column，渲染基于本 Column 的 JSON 文档构建的表达式
+func (co Column) derive(spell func(dialect gormcnm.Dialect, doc string) (string, []interface{})) Column {
+	return Column{name: co.name, render: func(dialect gormcnm.Dialect) (string, []interface{}) {
+		doc, args := co.render(dialect)
+		stmt, more := spell(dialect, doc)
+		return stmt, append(append([]interface{}{}, args...), more...)
+	}}
+}
+
+// typedExpr returns the typed expression built on the JSON document of the Column, rendered in the dialect of the DB lazily
+// typedExpr 返回基于 Column 的 JSON 文档构建的类型化表达式，按数据库方言延迟渲染
+func typedExpr[TYPE any](co Column, spell func(dialect gormcnm.Dialect, doc string) string) *gormcnm.TypedExpr[TYPE] {
+	return gormcnm.NewTypedExpr[TYPE]("?", gormcnm.NewDialectExpression(func(dialect gormcnm.Dialect) (string, []interface{}) {
+		doc, args := co.render(dialect)
+		return spell(dialect, doc), args
+	}))
+}
+
+// function spells a JSON function call on the document, with the JSON path as the second argument when path is set
+// function 生成作用于文档的 JSON 函数调用，path 不为空时将 JSON 路径作为第二个参数
+func function(dialect gormcnm.Dialect, name string, doc string, path string) string {
+	if path == "" {
+		return fmt.Sprintf("%s(%s)", name, doc)
+	}
+	return fmt.Sprintf("%s(%s, %s)", name, doc, dialect.JSONPathLiteral(path))
+}
+
+// target returns the JSON document at the path, or the document itself when path is blank
+// target 返回路径处的 JSON 文档，path 为空时返回文档本身
+func target(dialect gormcnm.Dialect, doc string, path string) string {
+	if path == "" {
+		if dialect == gormcnm.DialectPostgres {
+			return doc + "::jsonb"
+		}
+		return doc
+	}
+	if dialect == gormcnm.DialectMySQL {
+		return fmt.Sprintf("JSON_EXTRACT(%s, %s)", doc, dialect.JSONPathLiteral(path))
+	}
+	return dialect.JSONExtract(doc, path)
 }
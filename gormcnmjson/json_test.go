@@ -168,7 +168,7 @@ func TestColumn_Update(t *testing.T) {
 
 		require.NoError(t, db.Model(&Product{}).
 			Where(columnCode.Eq("P001")).
-			Update(string(columnMeta), gorm.Expr(gormcnmjson.Raw(columnMeta).Set("price", 1099).Name())).Error)
+			Update(columnMeta.Name(), gormcnmjson.Raw(columnMeta).Set("price", 1099)).Error)
 
 		var updated Product
 		require.NoError(t, db.Where(columnCode.Eq("P001")).First(&updated).Error)
@@ -205,3 +205,54 @@ func TestColumn_New(t *testing.T) {
 		require.Equal(t, "iPhone", results[0].Name)
 	})
 }
+
+func TestColumn_Dialect(t *testing.T) {
+	column := gormcnmjson.Raw(columnMeta)
+
+	t.Run("mysql", func(t *testing.T) {
+		db := tests.NewDryRunDB(t, "mysql")
+		stmt := db.Where(column.Get("brand").Eq("Apple")).Where(column.GetInt("price").Gt(1000)).Find(&[]*Product{}).Statement
+		require.Equal(t, "SELECT * FROM `products` WHERE (`meta` ->> '$.brand' = ?) AND (CAST(`meta` ->> '$.price' AS SIGNED) > ?)", stmt.SQL.String())
+		stmt = db.Where(column.Length("tags").Gt(2)).Where(column.Type("tags").Eq("ARRAY")).Find(&[]*Product{}).Statement
+		require.Equal(t, "SELECT * FROM `products` WHERE (JSON_LENGTH(`meta`, '$.tags') > ?) AND (JSON_TYPE(JSON_EXTRACT(`meta`, '$.tags')) = ?)", stmt.SQL.String())
+	})
+	t.Run("postgres", func(t *testing.T) {
+		db := tests.NewDryRunDB(t, "postgres")
+		stmt := db.Where(column.Extract("specs").Get("cpu").Eq("A17")).Where(column.Length("").Gt(2)).Find(&[]*Product{}).Statement
+		require.Equal(t, `SELECT * FROM "products" WHERE (("meta"::jsonb #> '{specs}')::jsonb #>> '{cpu}' = $1) AND (jsonb_array_length("meta"::jsonb) > $2)`, stmt.SQL.String())
+		stmt = db.Model(&Product{}).Where(columnCode.Eq("P001")).Update(columnMeta.Name(), column.Remove("brand")).Statement
+		require.Equal(t, `UPDATE "products" SET "meta"="meta"::jsonb #- '{brand}' WHERE code=$1`, stmt.SQL.String())
+	})
+	t.Run("sqlserver", func(t *testing.T) {
+		db := tests.NewDryRunDB(t, "sqlserver")
+		stmt := db.Where(column.Get("brand").Eq("Apple")).Where(column.Valid().Eq(1)).Where(column.Length("tags").Gt(2)).Find(&[]*Product{}).Statement
+		require.Equal(t, "SELECT * FROM [products] WHERE (JSON_VALUE([meta], '$.brand') = ?) AND (ISJSON([meta]) = ?) AND ((SELECT COUNT(*) FROM OPENJSON([meta], '$.tags')) > ?)", stmt.SQL.String())
+		stmt = db.Model(&Product{}).Where(columnCode.Eq("P001")).Update(columnMeta.Name(), column.Set("price", 1099)).Statement
+		require.Equal(t, "UPDATE [products] SET [meta]=JSON_MODIFY([meta], '$.price', ?) WHERE code=?", stmt.SQL.String())
+		require.Equal(t, []interface{}{"1099", "P001"}, stmt.Vars)
+	})
+	t.Run("escaped", func(t *testing.T) {
+		tests.NewDBRun(t, func(db *gorm.DB) {
+			must.Done(db.AutoMigrate(&Product{}))
+			must.Done(db.Create(&[]Product{
+				{Code: "P001", Name: "iPhone", Meta: datatypes.JSON([]byte(`{"brand":"Apple"}`))},
+				{Code: "P002", Name: "Mate60", Meta: datatypes.JSON([]byte(`{"brand":"HuaWei"}`))},
+			}).Error)
+
+			// The quote in the path stays inside the literal, thus the condition matches nothing
+			// 路径中的引号仍在字面量内，因此条件不会匹配任何行
+			var results []Product
+			require.NoError(t, db.Where(column.Get("brand' OR '1'='1").Eq("x")).Find(&results).Error)
+			require.Empty(t, results)
+
+			type Result struct {
+				Code  string
+				Brand string
+			}
+			var brands []*Result
+			require.NoError(t, db.Model(&Product{}).Clauses(gormcnm.NewSx(columnCode.Name()).Combine(column.Extract("brand").AsAlias("brand"))).Order(columnCode.Name()).Find(&brands).Error)
+			require.Len(t, brands, 2)
+			require.Equal(t, `"Apple"`, brands[0].Brand)
+		})
+	})
+}
@@ -32,6 +32,12 @@ func NewSx(stmt string, args ...interface{}) *gormcnm.SelectStatement {
 func Sx(stmt string, args ...interface{}) *gormcnm.SelectStatement {
 	return stub.Sx(stmt, args...)
 }
+func Exists(sub *gorm.DB) *gormcnm.QxConjunction {
+	return stub.Exists(sub)
+}
+func NotExists(sub *gorm.DB) *gormcnm.QxConjunction {
+	return stub.NotExists(sub)
+}
 func NewColumnValueMap() gormcnm.ColumnValueMap {
 	return stub.NewColumnValueMap()
 }
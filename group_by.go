@@ -0,0 +1,88 @@
+// Package gormcnm provides a GROUP BY / HAVING builder with typed dimensions and aggregate measures
+// Auto selects the dimensions and aliased measures, groups by the dimensions and filters the groups with HAVING
+// Supports HAVING conditions as QxConjunction over aggregates, producing a scope used with db.Scopes()
+//
+// gormcnm 提供 GROUP BY / HAVING 构建器，使用类型化的维度列和聚合度量
+// 自动选择维度列和带别名的度量，按维度分组，并使用 HAVING 过滤分组
+// 支持以 QxConjunction 表达基于聚合的 HAVING 条件，生成可用于 db.Scopes() 的作用域
+package gormcnm
+
+import (
+	"github.com/yyle88/gormcnm/internal/utils"
+	"gorm.io/gorm"
+)
+
+// GroupBy represents a grouping, with the dimensions to group by, the measures to select and the HAVING conditions
+// It is immutable, each method returns a new GroupBy
+//
+// GroupBy 表示一个分组，包含分组的维度列、要选择的度量以及 HAVING 条件
+// 它是不可变的，每个方法都返回新的 GroupBy
+type GroupBy struct {
+	dimensions []string           // GROUP BY columns // GROUP BY 的列
+	measures   []*SelectStatement // Selected aggregate measures with aliases // 选择的带别名的聚合度量
+	having     *QxConjunction     // HAVING conditions, nil or empty means none // HAVING 条件，nil 或空表示没有
+}
+
+// NewGroupBy creates a GroupBy grouping by the dimension columns
+// NewGroupBy 创建按给定维度列分组的 GroupBy
+func NewGroupBy(dimensions ...utils.ColumnNameInterface) *GroupBy {
+	var names = make([]string, 0, len(dimensions))
+	for _, column := range dimensions {
+		names = append(names, column.Name())
+	}
+	return &GroupBy{dimensions: names}
+}
+
+// Measure returns a new GroupBy selecting the aggregate column (e.g. SUM(amount)) with the alias
+// Measure 返回以别名选择聚合列（例如 SUM(amount)）的新 GroupBy
+func (g *GroupBy) Measure(column utils.ColumnNameInterface, alias string) *GroupBy {
+	return g.MeasureSx(NewSelectStatement(utils.ApplyAliasToColumn(column.Name(), alias)))
+}
+
+// MeasureSx returns a new GroupBy selecting the measure with arguments, e.g. Case[int]().When(...).Sum("cnt")
+// MeasureSx 返回选择带参数度量的新 GroupBy，例如 Case[int]().When(...).Sum("cnt")
+func (g *GroupBy) MeasureSx(measure *SelectStatement) *GroupBy {
+	return &GroupBy{
+		dimensions: g.dimensions,
+		measures:   append(g.measures[:len(g.measures):len(g.measures)], measure),
+		having:     g.having,
+	}
+}
+
+// Having returns a new GroupBy with the HAVING condition, combined by AND with the existing ones
+// Having 返回带有 HAVING 条件的新 GroupBy，与已有条件按 AND 组合
+func (g *GroupBy) Having(qx *QxConjunction) *GroupBy {
+	return &GroupBy{dimensions: g.dimensions, measures: g.measures, having: g.having.AND(qx)}
+}
+
+// Sx returns the SelectStatement selecting the dimensions and the measures
+// Sx 返回选择维度列和度量的 SelectStatement
+func (g *GroupBy) Sx() *SelectStatement {
+	var sxs = make([]*SelectStatement, 0, len(g.dimensions)+len(g.measures))
+	for _, name := range g.dimensions {
+		sxs = append(sxs, NewSelectStatement(name))
+	}
+	sxs = append(sxs, g.measures...)
+	if len(sxs) == 0 {
+		return NewSelectStatement("*")
+	}
+	return sxs[0].Combine(sxs[1:]...)
+}
+
+// Scope converts the GroupBy to a GORM ScopeFunction used with db.Scopes(),
+// applying the SELECT, GROUP BY and HAVING clauses
+//
+// Scope 将 GroupBy 转换为 GORM 的 ScopeFunction，以便被 db.Scopes() 调用，
+// 应用 SELECT、GROUP BY 和 HAVING 子句
+func (g *GroupBy) Scope() ScopeFunction {
+	return func(db *gorm.DB) *gorm.DB {
+		db = db.Clauses(g.Sx())
+		for _, name := range g.dimensions {
+			db = db.Group(name)
+		}
+		if !g.having.IsEmpty() {
+			db = db.Having(g.having)
+		}
+		return db
+	}
+}
@@ -0,0 +1,85 @@
+// Package gormcnm tests validate the GROUP BY / HAVING builder
+// Auto verifies the dimensions, measures and HAVING conditions applied through db.Scopes()
+// Tests examine SQLite execution with an orders table
+//
+// gormcnm 测试包验证 GROUP BY / HAVING 构建器
+// 自动验证通过 db.Scopes() 应用的维度列、度量和 HAVING 条件
+// 测试涵盖使用 orders 表的 SQLite 执行
+package gormcnm
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/yyle88/gormcnm/internal/tests"
+	"github.com/yyle88/neatjson/neatjsons"
+	"gorm.io/gorm"
+)
+
+func TestGroupBy_Scope(t *testing.T) {
+	type Order struct {
+		ID     uint    `gorm:"primaryKey"`
+		UserID uint    `gorm:"column:user_id;"`
+		Status string  `gorm:"column:status;"`
+		Amount float64 `gorm:"column:amount;"`
+	}
+
+	const (
+		columnUserID = ColumnName[uint]("user_id")
+		columnStatus = ColumnName[string]("status")
+		columnAmount = ColumnName[float64]("amount")
+	)
+
+	var (
+		sumAmount = Sum(columnAmount)
+		countRows = Count(columnAmount)
+	)
+
+	tests.NewDBRun(t, func(db *gorm.DB) {
+		require.NoError(t, db.AutoMigrate(&Order{}))
+		require.NoError(t, db.Create(&[]*Order{
+			{ID: 1, UserID: 1, Status: "paid", Amount: 60},
+			{ID: 2, UserID: 1, Status: "paid", Amount: 70},
+			{ID: 3, UserID: 2, Status: "paid", Amount: 30},
+			{ID: 4, UserID: 2, Status: "refund", Amount: 90},
+			{ID: 5, UserID: 3, Status: "paid", Amount: 200},
+		}).Error)
+
+		type Result struct {
+			UserID uint
+			Total  float64
+			Cnt    int64
+			Paid   int64
+		}
+
+		group := NewGroupBy(columnUserID).
+			Measure(sumAmount, "total").
+			Measure(countRows, "cnt").
+			MeasureSx(Case[int]().When(Qx(columnStatus.Eq("paid")), 1).Else(0).Sum("paid"))
+
+		t.Run("having", func(t *testing.T) {
+			var results []*Result
+			require.NoError(t, db.Model(&Order{}).Scopes(group.Having(Qx(sumAmount.Gt(100))).Scope()).Order(columnUserID.Name()).Find(&results).Error)
+			t.Log(neatjsons.S(results))
+			require.Len(t, results, 3)
+			require.Equal(t, 130.0, results[0].Total)
+			require.Equal(t, int64(2), results[1].Cnt)
+			require.Equal(t, int64(1), results[1].Paid)
+		})
+
+		t.Run("having-and", func(t *testing.T) {
+			var results []*Result
+			require.NoError(t, db.Model(&Order{}).Scopes(group.Having(Qx(sumAmount.Gt(100))).Having(Qx(countRows.Gte(2))).Scope()).Order(columnUserID.Name()).Find(&results).Error)
+			require.Len(t, results, 2)
+			require.Equal(t, uint(2), results[1].UserID)
+		})
+
+		t.Run("where", func(t *testing.T) {
+			var results []*Result
+			require.NoError(t, db.Model(&Order{}).Where(columnStatus.Eq("paid")).Scopes(NewGroupBy(columnUserID).Measure(sumAmount, "total").Having(Qx(sumAmount.Lt(100))).Scope()).Find(&results).Error)
+			require.Len(t, results, 1)
+			require.Equal(t, uint(2), results[0].UserID)
+			require.Equal(t, 30.0, results[0].Total)
+		})
+	})
+}
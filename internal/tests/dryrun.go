@@ -0,0 +1,92 @@
+package tests
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/yyle88/rese"
+	"gorm.io/gorm"
+	"gorm.io/gorm/callbacks"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/logger"
+	"gorm.io/gorm/schema"
+)
+
+// NewDryRunDB creates a DryRun database with a fake dialector named as the given dialect
+// Used to check the SQL rendered for MySQL, PostgreSQL and SQL Server without real servers
+// NewDryRunDB 创建一个 DryRun 数据库，使用以给定方言命名的模拟 dialector
+// 用于在没有真实数据库服务的情况下检查 MySQL、PostgreSQL 和 SQL Server 的 SQL 渲染
+func NewDryRunDB(t *testing.T, dialectName string) *gorm.DB {
+	db := rese.P1(gorm.Open(&dryRunDialector{name: dialectName}, &gorm.Config{
+		DryRun: true,
+		Logger: logger.Discard,
+	}))
+	t.Logf("--- DRY RUN DB (%s) ---", dialectName)
+	return db
+}
+
+// dryRunDialector is a fake dialector which renders SQL without connecting to any database
+// dryRunDialector 是一个模拟的 dialector，只渲染 SQL 而不连接数据库
+type dryRunDialector struct {
+	name string
+}
+
+func (d *dryRunDialector) Name() string {
+	return d.name
+}
+
+func (d *dryRunDialector) Initialize(db *gorm.DB) error {
+	callbacks.RegisterDefaultCallbacks(db, &callbacks.Config{})
+	return nil
+}
+
+func (d *dryRunDialector) Migrator(db *gorm.DB) gorm.Migrator {
+	return nil
+}
+
+func (d *dryRunDialector) DataTypeOf(field *schema.Field) string {
+	return string(field.DataType)
+}
+
+func (d *dryRunDialector) DefaultValueOf(field *schema.Field) clause.Expression {
+	return clause.Expr{SQL: "DEFAULT"}
+}
+
+func (d *dryRunDialector) BindVarTo(writer clause.Writer, stmt *gorm.Statement, v interface{}) {
+	if d.name == "postgres" {
+		writer.WriteByte('$')
+		writer.WriteString(strconv.Itoa(len(stmt.Vars)))
+		return
+	}
+	writer.WriteByte('?')
+}
+
+func (d *dryRunDialector) QuoteTo(writer clause.Writer, str string) {
+	var quoteL, quoteR = "`", "`"
+	switch d.name {
+	case "postgres":
+		quoteL, quoteR = `"`, `"`
+	case "sqlserver":
+		quoteL, quoteR = "[", "]"
+	}
+	parts := strings.Split(str, ".")
+	for idx, part := range parts {
+		if idx > 0 {
+			writer.WriteByte('.')
+		}
+		writer.WriteString(quoteL + part + quoteR)
+	}
+}
+
+func (d *dryRunDialector) Explain(sql string, vars ...interface{}) string {
+	if d.name == "postgres" {
+		return logger.ExplainSQL(sql, regexpNumericPlaceholder, `'`, vars...)
+	}
+	return logger.ExplainSQL(sql, nil, `'`, vars...)
+}
+
+// regexpNumericPlaceholder matches the "$1" placeholders of PostgreSQL, the same as the postgres driver
+// regexpNumericPlaceholder 匹配 PostgreSQL 的 "$1" 占位符，与 postgres 驱动相同
+var regexpNumericPlaceholder = regexp.MustCompile(`\$(\d+)`)
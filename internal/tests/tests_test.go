@@ -25,3 +25,15 @@ func TestNewMemDB(t *testing.T) {
 	require.NoError(t, db.Raw("SELECT 1").Scan(&result).Error)
 	require.Equal(t, 1, result)
 }
+
+func TestNewDryRunDB(t *testing.T) {
+	type Example struct {
+		Name string
+	}
+
+	db := tests.NewDryRunDB(t, "postgres")
+
+	stmt := db.Where("name = ?", "abc").Find(&[]*Example{}).Statement
+	require.Equal(t, `SELECT * FROM "examples" WHERE name = $1`, stmt.SQL.String())
+	require.Equal(t, []interface{}{"abc"}, stmt.Vars)
+}
@@ -0,0 +1,259 @@
+// Package gormcnm provides the keyset (cursor) pagination, seeking past the last row instead of skipping with OFFSET
+// Auto builds the seek condition "(a > ?) OR (a = ? AND b > ?)" from the ordered typed columns and their directions
+// Supports backward paging by reversing the order, and opaque cursor tokens decoding back into the typed values
+//
+// gormcnm 提供键集（游标）分页，越过上一页的最后一行进行查找，而不是使用 OFFSET 跳过
+// 自动根据有序的类型化列及其方向构建查找条件 "(a > ?) OR (a = ? AND b > ?)"
+// 支持通过反转顺序向前翻页，以及可解码回类型化值的不透明游标令牌
+package gormcnm
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+
+	"github.com/pkg/errors"
+	"github.com/yyle88/must"
+	"gorm.io/gorm"
+)
+
+// ErrKeysetCursor means the cursor token is malformed or does not fit the keyset, or the row has a NULL key
+// ErrKeysetCursor 表示游标令牌格式错误或与键集不匹配，或行的键为 NULL
+var ErrKeysetCursor = errors.New("invalid keyset cursor")
+
+// KeysetKey represents one ordered typed column of the keyset
+// KeysetKey 表示键集中一个有序的类型化列
+type KeysetKey struct {
+	item   OrderItem                                      // Column and direction // 列和方向
+	decode func(raw json.RawMessage) (interface{}, error) // Decodes the value into the column type // 将值解码为列的类型
+}
+
+// NewKeysetKey creates a KeysetKey on the typed column with the direction. The column must be NOT NULL.
+// NewKeysetKey 使用类型化列和方向创建 KeysetKey。该列必须是 NOT NULL。
+func NewKeysetKey[TYPE any](column ColumnName[TYPE], direction OrderDirection) *KeysetKey {
+	return &KeysetKey{
+		item: NewOrderItem(column, direction),
+		decode: func(raw json.RawMessage) (interface{}, error) {
+			var value TYPE
+			if err := json.Unmarshal(raw, &value); err != nil {
+				return nil, err
+			}
+			return value, nil
+		},
+	}
+}
+
+// Keyset represents the keyset pagination over the ordered keys, the last key should be unique (e.g. the primary key) as the tiebreaker
+// Keyset 表示基于有序键的键集分页，最后一个键应当唯一（例如主键）以区分顺序相同的行
+type Keyset struct {
+	keys []*KeysetKey // Ordered keys // 有序的键
+}
+
+// NewKeyset creates a Keyset with the ordered keys, e.g. NewKeyset(NewKeysetKey(columnRank, OrderDesc), NewKeysetKey(columnID, OrderAsc))
+// NewKeyset 使用有序的键创建 Keyset，例如 NewKeyset(NewKeysetKey(columnRank, OrderDesc), NewKeysetKey(columnID, OrderAsc))
+func NewKeyset(keys ...*KeysetKey) *Keyset {
+	must.True(len(keys) > 0)
+	var seen = make(map[string]bool, len(keys))
+	for _, key := range keys {
+		must.True(!seen[key.item.Column])
+		seen[key.item.Column] = true
+	}
+	return &Keyset{keys: keys}
+}
+
+// KeysetCursor represents the position of the page, the key values of a row in the order of the keys
+// KeysetCursor 表示分页的位置，即某一行按键顺序排列的键值
+type KeysetCursor struct {
+	Values   []interface{} // Key values of the row // 行的键值
+	Backward bool          // Reads the rows before the row, else the rows after it // 读取该行之前的行，否则读取之后的行
+}
+
+// Ordering returns the Ordering of the keys, reversed when reading backward
+// Ordering 返回键的排序，向前读取时反转
+func (ks *Keyset) Ordering(backward bool) *Ordering {
+	var items = make([]OrderItem, 0, len(ks.keys))
+	for _, key := range ks.keys {
+		items = append(items, key.item)
+	}
+	if backward {
+		return NewOrdering(items...).Reverse()
+	}
+	return NewOrdering(items...)
+}
+
+// Seek returns the condition selecting the rows after (or before, when backward) the cursor, the empty condition when the cursor is nil
+// Seek 返回选择游标之后（向前读取时为之前）各行的条件，游标为 nil 时返回空条件
+func (ks *Keyset) Seek(cursor *KeysetCursor) *QxConjunction {
+	if cursor == nil {
+		return NewEmptyQx()
+	}
+	must.Len(cursor.Values, len(ks.keys))
+	var names = make([]string, 0, len(ks.keys))
+	var ops = make([]string, 0, len(ks.keys))
+	for _, item := range ks.Ordering(cursor.Backward).Items() {
+		names = append(names, item.Column)
+		if item.Direction == OrderDesc {
+			ops = append(ops, "<")
+		} else {
+			ops = append(ops, ">")
+		}
+	}
+	return NewDialectQx(func(dialect Dialect) (string, []interface{}) {
+		return expandCompare(dialect, names, ops, cursor.Values)
+	})
+}
+
+// Scope returns the ScopeFunction applying the seek condition, the order and the limit of the page
+// The rows of a backward page come in the reversed order, KeysetPaginate puts them back
+//
+// Scope 返回应用查找条件、排序和分页大小的 ScopeFunction
+// 向前读取的页中各行顺序相反，KeysetPaginate 会将其恢复
+func (ks *Keyset) Scope(cursor *KeysetCursor, limit int) ScopeFunction {
+	return func(db *gorm.DB) *gorm.DB {
+		var backward = cursor != nil && cursor.Backward
+		return db.Scopes(ks.Seek(cursor).Scope(), ks.Ordering(backward).Scope()).Limit(limit)
+	}
+}
+
+// CursorOf returns the cursor at the row, a model (struct or pointer to struct) or a map[string]interface{} row
+// CursorOf 返回位于该行的游标，行可以是模型（结构体或结构体指针）或 map[string]interface{}
+func (ks *Keyset) CursorOf(row interface{}, backward bool) (*KeysetCursor, error) {
+	lookup, err := newMatchLookup(row, parseMatchSchema)
+	if err != nil {
+		return nil, errors.WithMessagef(ErrKeysetCursor, "row: %s", err.Error())
+	}
+	var values = make([]interface{}, 0, len(ks.keys))
+	for _, key := range ks.keys {
+		value, err := lookup(unqualifiedColumn(key.item.Column))
+		if err != nil {
+			return nil, errors.WithMessagef(ErrKeysetCursor, "row: %s", err.Error())
+		}
+		if normalizeMatchValue(value) == nil {
+			return nil, errors.WithMessagef(ErrKeysetCursor, "column %q is NULL", key.item.Column)
+		}
+		values = append(values, value)
+	}
+	return &KeysetCursor{Values: values, Backward: backward}, nil
+}
+
+// keysetToken is the JSON content of the cursor token
+// keysetToken 是游标令牌的 JSON 内容
+type keysetToken struct {
+	Values   []json.RawMessage `json:"v"`           // Key values // 键值
+	Backward bool              `json:"b,omitempty"` // Reads backward // 向前读取
+}
+
+// Encode encodes the cursor into an opaque URL-safe token
+// Encode 将游标编码为不透明的 URL 安全令牌
+func (ks *Keyset) Encode(cursor *KeysetCursor) (string, error) {
+	if len(cursor.Values) != len(ks.keys) {
+		return "", errors.WithMessagef(ErrKeysetCursor, "%d values for %d keys", len(cursor.Values), len(ks.keys))
+	}
+	var token = keysetToken{Values: make([]json.RawMessage, 0, len(cursor.Values)), Backward: cursor.Backward}
+	for idx, value := range cursor.Values {
+		raw, err := json.Marshal(value)
+		if err != nil {
+			return "", errors.WithMessagef(ErrKeysetCursor, "column %q: %s", ks.keys[idx].item.Column, err.Error())
+		}
+		token.Values = append(token.Values, raw)
+	}
+	data, err := json.Marshal(&token)
+	if err != nil {
+		return "", errors.WithMessage(ErrKeysetCursor, err.Error())
+	}
+	return base64.RawURLEncoding.EncodeToString(data), nil
+}
+
+// Decode decodes the token into the cursor with the values of the column types, the empty token gives nil (the first page)
+// Decode 将令牌解码为带有列类型值的游标，空令牌返回 nil（第一页）
+func (ks *Keyset) Decode(token string) (*KeysetCursor, error) {
+	if token == "" {
+		return nil, nil
+	}
+	data, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return nil, errors.WithMessage(ErrKeysetCursor, "token not decodable")
+	}
+	var content keysetToken
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&content); err != nil || decoder.More() {
+		return nil, errors.WithMessage(ErrKeysetCursor, "token not decodable")
+	}
+	if len(content.Values) != len(ks.keys) {
+		return nil, errors.WithMessagef(ErrKeysetCursor, "%d values for %d keys", len(content.Values), len(ks.keys))
+	}
+	var values = make([]interface{}, 0, len(ks.keys))
+	for idx, key := range ks.keys {
+		raw := content.Values[idx]
+		if string(raw) == "null" {
+			return nil, errors.WithMessagef(ErrKeysetCursor, "column %q is NULL", key.item.Column)
+		}
+		value, err := key.decode(raw)
+		if err != nil {
+			return nil, errors.WithMessagef(ErrKeysetCursor, "column %q: %s", key.item.Column, err.Error())
+		}
+		values = append(values, value)
+	}
+	return &KeysetCursor{Values: values, Backward: content.Backward}, nil
+}
+
+// KeysetPage represents a page of the keyset pagination, with the tokens of the neighbouring pages
+// KeysetPage 表示键集分页中的一页，包含相邻页的令牌
+type KeysetPage[MOD any] struct {
+	Rows []*MOD // Rows in the order of the keyset // 按键集顺序排列的行
+	Next string // Token of the next page, empty when no more rows // 下一页的令牌，没有更多行时为空
+	Prev string // Token of the previous page, empty on the first page // 上一页的令牌，在第一页时为空
+}
+
+// KeysetPaginate reads the page at the token (empty for the first page) with at most limit rows
+// It reads one more row to tell whether the page has a next (or previous, when backward) page
+//
+// KeysetPaginate 读取令牌（第一页为空）所在的页，最多 limit 行
+// 它会多读取一行，以判断是否存在下一页（向前读取时为上一页）
+func KeysetPaginate[MOD any](db *gorm.DB, ks *Keyset, token string, limit int) (*KeysetPage[MOD], error) {
+	must.True(limit > 0)
+	cursor, err := ks.Decode(token)
+	if err != nil {
+		return nil, err
+	}
+	var rows []*MOD
+	if err := db.Scopes(ks.Scope(cursor, limit+1)).Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	var more = len(rows) > limit
+	if more {
+		rows = rows[:limit]
+	}
+	var backward = cursor != nil && cursor.Backward
+	if backward {
+		for i, j := 0, len(rows)-1; i < j; i, j = i+1, j-1 {
+			rows[i], rows[j] = rows[j], rows[i]
+		}
+	}
+	var page = &KeysetPage[MOD]{Rows: rows}
+	if len(rows) == 0 {
+		return page, nil
+	}
+	if more || backward {
+		if page.Next, err = ks.encodeRow(rows[len(rows)-1], false); err != nil {
+			return nil, err
+		}
+	}
+	if (more && backward) || (cursor != nil && !backward) {
+		if page.Prev, err = ks.encodeRow(rows[0], true); err != nil {
+			return nil, err
+		}
+	}
+	return page, nil
+}
+
+// encodeRow returns the token of the cursor at the row
+// encodeRow 返回位于该行的游标令牌
+func (ks *Keyset) encodeRow(row interface{}, backward bool) (string, error) {
+	cursor, err := ks.CursorOf(row, backward)
+	if err != nil {
+		return "", err
+	}
+	return ks.Encode(cursor)
+}
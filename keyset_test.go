@@ -0,0 +1,146 @@
+// Package gormcnm tests validate the keyset pagination with the seek condition and the cursor tokens
+// Auto verifies the expanded comparison of mixed directions and the typed values decoded from the tokens
+// Tests examine SQLite paging forward to the end and backward to the start
+//
+// gormcnm 测试包验证带有查找条件和游标令牌的键集分页
+// 自动验证混合方向的展开比较以及从令牌解码的类型化值
+// 测试涵盖在 SQLite 上向后翻到末尾再向前翻回开头
+package gormcnm
+
+import (
+	"encoding/base64"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/yyle88/gormcnm/internal/tests"
+	"gorm.io/gorm"
+)
+
+type keysetExample struct {
+	ID        uint      `gorm:"primaryKey"`
+	Name      string    `gorm:"column:name;"`
+	CreatedAt time.Time `gorm:"column:created_at;"`
+}
+
+const (
+	keysetExampleID        = ColumnName[uint]("id")
+	keysetExampleName      = ColumnName[string]("name")
+	keysetExampleCreatedAt = ColumnName[time.Time]("created_at")
+)
+
+func TestKeyset_Seek(t *testing.T) {
+	keyset := NewKeyset(NewKeysetKey(keysetExampleCreatedAt, OrderDesc), NewKeysetKey(keysetExampleID, OrderAsc))
+	moment := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	db := tests.NewDryRunDB(t, "mysql")
+	stmt := db.Table("examples").Where(keyset.Seek(&KeysetCursor{Values: []interface{}{moment, uint(7)}})).Find(&[]map[string]interface{}{}).Statement
+	require.Equal(t, "SELECT * FROM `examples` WHERE (((`created_at` < ?) OR (`created_at` = ? AND `id` > ?)))", stmt.SQL.String())
+	require.Equal(t, []interface{}{moment, moment, uint(7)}, stmt.Vars)
+
+	stmt = db.Table("examples").Where(keyset.Seek(&KeysetCursor{Values: []interface{}{moment, uint(7)}, Backward: true})).Find(&[]map[string]interface{}{}).Statement
+	require.Equal(t, "SELECT * FROM `examples` WHERE (((`created_at` > ?) OR (`created_at` = ? AND `id` < ?)))", stmt.SQL.String())
+
+	require.True(t, keyset.Seek(nil).IsEmpty())
+	require.Equal(t, "created_at ASC, id DESC", string(keyset.Ordering(true).Ob()))
+
+	require.Panics(t, func() {
+		keyset.Seek(&KeysetCursor{Values: []interface{}{moment}})
+	})
+	require.Panics(t, func() {
+		NewKeyset(NewKeysetKey(keysetExampleID, OrderAsc), NewKeysetKey(keysetExampleID, OrderDesc))
+	})
+}
+
+func TestKeyset_Token(t *testing.T) {
+	keyset := NewKeyset(NewKeysetKey(keysetExampleCreatedAt, OrderDesc), NewKeysetKey(keysetExampleID, OrderAsc))
+	moment := time.Date(2024, 1, 2, 3, 4, 5, 6, time.UTC)
+
+	cursor, err := keyset.CursorOf(&keysetExample{ID: 7, CreatedAt: moment}, true)
+	require.NoError(t, err)
+	token, err := keyset.Encode(cursor)
+	require.NoError(t, err)
+
+	decoded, err := keyset.Decode(token)
+	require.NoError(t, err)
+	require.Equal(t, &KeysetCursor{Values: []interface{}{moment, uint(7)}, Backward: true}, decoded)
+
+	decoded, err = keyset.Decode("")
+	require.NoError(t, err)
+	require.Nil(t, decoded)
+
+	for _, token := range []string{
+		"not a token!",
+		base64.RawURLEncoding.EncodeToString([]byte(`{"v":[1]}`)),
+		base64.RawURLEncoding.EncodeToString([]byte(`{"v":["2024-01-02T03:04:05Z","x"]}`)),
+		base64.RawURLEncoding.EncodeToString([]byte(`{"v":["2024-01-02T03:04:05Z",null]}`)),
+		base64.RawURLEncoding.EncodeToString([]byte(`{"v":["2024-01-02T03:04:05Z",1],"x":1}`)),
+	} {
+		_, err := keyset.Decode(token)
+		require.ErrorIs(t, err, ErrKeysetCursor, token)
+	}
+
+	_, err = NewKeyset(NewKeysetKey(ColumnName[*string]("remark"), OrderAsc)).CursorOf(map[string]interface{}{"remark": (*string)(nil)}, false)
+	require.ErrorIs(t, err, ErrKeysetCursor)
+}
+
+func TestKeysetPaginate(t *testing.T) {
+	keyset := NewKeyset(NewKeysetKey(keysetExampleCreatedAt, OrderDesc), NewKeysetKey(keysetExampleID, OrderAsc))
+
+	tests.NewDBRun(t, func(db *gorm.DB) {
+		require.NoError(t, db.AutoMigrate(&keysetExample{}))
+		moment := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+		var rows []*keysetExample
+		for idx, name := range []string{"a", "b", "c", "d", "e", "f", "g"} {
+			// Two rows on each moment, the id breaks the ties
+			// 每个时间点两行，由 id 区分顺序
+			rows = append(rows, &keysetExample{ID: uint(idx + 1), Name: name, CreatedAt: moment.Add(-time.Duration(idx/2) * time.Hour)})
+		}
+		require.NoError(t, db.Create(&rows).Error)
+
+		namesOf := func(page *KeysetPage[keysetExample]) []string {
+			var names []string
+			for _, row := range page.Rows {
+				names = append(names, row.Name)
+			}
+			return names
+		}
+
+		page1, err := KeysetPaginate[keysetExample](db, keyset, "", 3)
+		require.NoError(t, err)
+		require.Equal(t, []string{"a", "b", "c"}, namesOf(page1))
+		require.Empty(t, page1.Prev)
+
+		page2, err := KeysetPaginate[keysetExample](db, keyset, page1.Next, 3)
+		require.NoError(t, err)
+		require.Equal(t, []string{"d", "e", "f"}, namesOf(page2))
+
+		page3, err := KeysetPaginate[keysetExample](db, keyset, page2.Next, 3)
+		require.NoError(t, err)
+		require.Equal(t, []string{"g"}, namesOf(page3))
+		require.Empty(t, page3.Next)
+
+		back2, err := KeysetPaginate[keysetExample](db, keyset, page3.Prev, 3)
+		require.NoError(t, err)
+		require.Equal(t, []string{"d", "e", "f"}, namesOf(back2))
+		require.Equal(t, page2.Next, back2.Next)
+
+		back1, err := KeysetPaginate[keysetExample](db, keyset, back2.Prev, 3)
+		require.NoError(t, err)
+		require.Equal(t, []string{"a", "b", "c"}, namesOf(back1))
+		require.Empty(t, back1.Prev)
+		require.Equal(t, page1.Next, back1.Next)
+
+		// The seek condition works with the other conditions
+		// 查找条件可以与其他条件一起使用
+		var names []string
+		require.NoError(t, db.Model(&keysetExample{}).
+			Where(keysetExampleName.Ne("b")).
+			Scopes(keyset.Scope(&KeysetCursor{Values: []interface{}{moment, uint(1)}}, 2)).
+			Pluck(keysetExampleName.Name(), &names).Error)
+		require.Equal(t, []string{"c", "d"}, names)
+
+		_, err = KeysetPaginate[keysetExample](db, keyset, "bad", 3)
+		require.ErrorIs(t, err, ErrKeysetCursor)
+	})
+}
@@ -65,6 +65,18 @@ func (common *ColumnOperationClass) Sx(stmt string, args ...interface{}) *Select
 	return NewSelectStatement(stmt, args...)
 }
 
+// Exists creates a condition checking the subquery returns any rows.
+// Exists 创建判断子查询返回结果行的条件。
+func (common *ColumnOperationClass) Exists(sub *gorm.DB) *QxConjunction {
+	return Exists(sub)
+}
+
+// NotExists creates a condition checking the subquery returns no rows.
+// NotExists 创建判断子查询不返回任何行的条件。
+func (common *ColumnOperationClass) NotExists(sub *gorm.DB) *QxConjunction {
+	return NotExists(sub)
+}
+
 // NewColumnValueMap creates a new ColumnValueMap using the NewKw function.
 // NewColumnValueMap 使用 NewKw 函数创建一个新的 ColumnValueMap。
 func (common *ColumnOperationClass) NewColumnValueMap() ColumnValueMap {
@@ -89,11 +101,14 @@ func (common *ColumnOperationClass) Kw(columnName string, value interface{}) Col
 	return Kw(columnName, value)
 }
 
-// Where applies the provided QxConjunctions to the given gorm.DB statement.
-// Where 将提供的 QxConjunction 应用到给定的 gorm.DB 语句。
+// Where applies the provided QxConjunctions to the given gorm.DB statement, skipping nil and empty ones.
+// Where 将提供的 QxConjunction 应用到给定的 gorm.DB 语句，跳过 nil 和空的条件。
 func (common *ColumnOperationClass) Where(db *gorm.DB, qxs ...*QxConjunction) *gorm.DB {
 	for _, qx := range qxs {
-		db = db.Where(qx.Qs(), qx.args...)
+		if qx.IsEmpty() {
+			continue
+		}
+		db = db.Where(qx.Qs(), qx.Args()...)
 	}
 	return db
 }
@@ -207,7 +222,7 @@ func (common *ColumnOperationClass) CombineSxs(cs ...SelectStatement) *SelectSta
 // Select 将提供的 SelectStatement 应用到给定的 gorm.DB 语句。
 func (common *ColumnOperationClass) Select(db *gorm.DB, qxs ...*SelectStatement) *gorm.DB {
 	for _, qx := range qxs {
-		db = db.Select(qx.Qs(), qx.args...)
+		db = db.Select(qx.Qs(), qx.Args()...)
 	}
 	return db
 }
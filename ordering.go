@@ -0,0 +1,305 @@
+// Package gormcnm provides the structured ordering, with the direction, the NULLS placement and the collation of each column
+// Auto renders NULLS FIRST/LAST natively on PostgreSQL and SQLite, and emulates it with CASE WHEN on MySQL and SQL Server
+// Supports reversing, deduplicating and inspecting the ordering, and converting it into clause.OrderBy
+//
+// gormcnm 提供结构化的排序，包含每个列的方向、NULLS 位置和排序规则
+// 自动在 PostgreSQL 和 SQLite 上原生渲染 NULLS FIRST/LAST，在 MySQL 和 SQL Server 上使用 CASE WHEN 模拟
+// 支持反转、去重和检查排序，并转换为 clause.OrderBy
+package gormcnm
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/yyle88/gormcnm/internal/utils"
+	"github.com/yyle88/must"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ErrOrderDirection means the direction is neither ASC nor DESC
+// ErrOrderDirection 表示方向既不是 ASC 也不是 DESC
+var ErrOrderDirection = errors.New("invalid order direction")
+
+// OrderDirection represents the direction of an ordering column
+// OrderDirection 表示排序列的方向
+type OrderDirection string
+
+const (
+	OrderAsc  OrderDirection = "ASC"  // Ascending // 升序
+	OrderDesc OrderDirection = "DESC" // Descending // 降序
+)
+
+// NewOrderDirection parses the direction case-insensitively, e.g. "asc" and "DESC"
+// NewOrderDirection 不区分大小写地解析方向，例如 "asc" 和 "DESC"
+func NewOrderDirection(direction string) (OrderDirection, error) {
+	switch OrderDirection(strings.ToUpper(strings.TrimSpace(direction))) {
+	case OrderAsc:
+		return OrderAsc, nil
+	case OrderDesc:
+		return OrderDesc, nil
+	default:
+		return "", errors.WithMessagef(ErrOrderDirection, "direction %q", direction)
+	}
+}
+
+// checkOrderDirection checks the direction is ASC or DESC case-insensitively, see NewOrderDirection
+// checkOrderDirection 不区分大小写地检查方向是否为 ASC 或 DESC，见 NewOrderDirection
+func checkOrderDirection(direction string) error {
+	_, err := NewOrderDirection(direction)
+	return err
+}
+
+// Reverse returns the opposite direction
+// Reverse 返回相反的方向
+func (direction OrderDirection) Reverse() OrderDirection {
+	if direction == OrderDesc {
+		return OrderAsc
+	}
+	return OrderDesc
+}
+
+// NullsPlacement represents where the NULL values go, the zero value keeps the default of the database
+// NullsPlacement 表示 NULL 值的位置，零值保持数据库的默认行为
+type NullsPlacement string
+
+const (
+	NullsDefault NullsPlacement = ""      // Default of the database // 数据库的默认行为
+	NullsFirst   NullsPlacement = "FIRST" // NULL values go first // NULL 值排在最前
+	NullsLast    NullsPlacement = "LAST"  // NULL values go last // NULL 值排在最后
+)
+
+// Reverse returns the opposite placement, the default stays the default since it follows the direction
+// Reverse 返回相反的位置，默认行为随方向变化，因此保持默认
+func (nulls NullsPlacement) Reverse() NullsPlacement {
+	switch nulls {
+	case NullsFirst:
+		return NullsLast
+	case NullsLast:
+		return NullsFirst
+	default:
+		return NullsDefault
+	}
+}
+
+// regexpCollation matches the collation names, e.g. "NOCASE", "utf8mb4_bin" and "en_US.utf8"
+// regexpCollation 匹配排序规则名称，例如 "NOCASE"、"utf8mb4_bin" 和 "en_US.utf8"
+var regexpCollation = regexp.MustCompile(`^[A-Za-z0-9_][A-Za-z0-9_.\-]*$`)
+
+// OrderItem represents one column of the ordering. It is immutable, each method returns a new OrderItem
+// OrderItem 表示排序中的一个列。它是不可变的，每个方法都返回新的 OrderItem
+type OrderItem struct {
+	Column    string         // Column name or expression // 列名或表达式
+	Direction OrderDirection // ASC or DESC // ASC 或 DESC
+	Nulls     NullsPlacement // NULLS placement // NULLS 位置
+	Collation string         // Collation, empty means the column default // 排序规则，空表示列的默认规则
+}
+
+// NewOrderItem creates an OrderItem on the column with the direction
+// NewOrderItem 使用列和方向创建 OrderItem
+func NewOrderItem(column utils.ColumnNameInterface, direction OrderDirection) OrderItem {
+	must.In(direction, []OrderDirection{OrderAsc, OrderDesc})
+	return OrderItem{Column: must.Nice(column.Name()), Direction: direction}
+}
+
+// Asc creates an ascending OrderItem on this column
+// Asc 在该列上创建升序的 OrderItem
+func (columnName ColumnName[TYPE]) Asc() OrderItem {
+	return NewOrderItem(columnName, OrderAsc)
+}
+
+// Desc creates a descending OrderItem on this column
+// Desc 在该列上创建降序的 OrderItem
+func (columnName ColumnName[TYPE]) Desc() OrderItem {
+	return NewOrderItem(columnName, OrderDesc)
+}
+
+// WithNulls returns a new OrderItem with the NULLS placement
+// WithNulls 返回带有 NULLS 位置的新 OrderItem
+func (item OrderItem) WithNulls(nulls NullsPlacement) OrderItem {
+	must.In(nulls, []NullsPlacement{NullsDefault, NullsFirst, NullsLast})
+	item.Nulls = nulls
+	return item
+}
+
+// WithCollation returns a new OrderItem with the collation, e.g. "NOCASE" on SQLite
+// WithCollation 返回带有排序规则的新 OrderItem，例如 SQLite 上的 "NOCASE"
+func (item OrderItem) WithCollation(collation string) OrderItem {
+	must.True(collation == "" || regexpCollation.MatchString(collation))
+	item.Collation = collation
+	return item
+}
+
+// Reverse returns the OrderItem giving the opposite order, reversing both the direction and the NULLS placement
+// Reverse 返回顺序相反的 OrderItem，同时反转方向和 NULLS 位置
+func (item OrderItem) Reverse() OrderItem {
+	item.Direction = item.Direction.Reverse()
+	item.Nulls = item.Nulls.Reverse()
+	return item
+}
+
+// columns returns the ORDER BY items of the column in the dialect, two items when the NULLS placement is emulated
+// The plain column names are quoted in the known dialects, the zero dialect keeps the classic unquoted spelling
+//
+// columns 返回该列在方言中的 ORDER BY 项，模拟 NULLS 位置时返回两项
+// 已知方言中普通列名会加引号，零值方言保持经典的不加引号写法
+func (item OrderItem) columns(dialect Dialect) []string {
+	var name = dialect.quoteColumn(item.Column)
+	var results []string
+	var suffix string
+	switch {
+	case item.Nulls == NullsDefault:
+	case dialect == DialectMySQL || dialect == DialectSQLServer:
+		if item.Nulls == NullsFirst {
+			results = append(results, "CASE WHEN "+name+" IS NULL THEN 0 ELSE 1 END")
+		} else {
+			results = append(results, "CASE WHEN "+name+" IS NULL THEN 1 ELSE 0 END")
+		}
+	default:
+		suffix = " NULLS " + string(item.Nulls)
+	}
+	if item.Collation != "" {
+		name += " COLLATE " + item.Collation
+	}
+	return append(results, name+" "+string(item.Direction)+suffix)
+}
+
+// Ordering represents the structured ORDER BY, a list of OrderItem
+// It is immutable, each method returns a new Ordering
+//
+// Ordering 表示结构化的 ORDER BY，即 OrderItem 的列表
+// 它是不可变的，每个方法都返回新的 Ordering
+type Ordering struct {
+	items []OrderItem // Columns in order // 按顺序排列的列
+}
+
+// NewOrdering creates an Ordering with the items, e.g. NewOrdering(columnRank.Desc().WithNulls(NullsLast), columnID.Asc())
+// NewOrdering 使用给定项创建 Ordering，例如 NewOrdering(columnRank.Desc().WithNulls(NullsLast), columnID.Asc())
+func NewOrdering(items ...OrderItem) *Ordering {
+	for _, item := range items {
+		must.Nice(item.Column)
+		must.In(item.Direction, []OrderDirection{OrderAsc, OrderDesc})
+	}
+	return &Ordering{items: append([]OrderItem{}, items...)}
+}
+
+// Then returns a new Ordering with the items appended
+// Then 返回追加了给定项的新 Ordering
+func (o *Ordering) Then(items ...OrderItem) *Ordering {
+	return NewOrdering(append(o.Items(), items...)...)
+}
+
+// Items returns a copy of the items in order
+// Items 按顺序返回各项的副本
+func (o *Ordering) Items() []OrderItem {
+	return append([]OrderItem{}, o.items...)
+}
+
+// Len returns the count of the items
+// Len 返回项的数量
+func (o *Ordering) Len() int {
+	return len(o.items)
+}
+
+// Columns returns the column names in order
+// Columns 按顺序返回列名
+func (o *Ordering) Columns() []string {
+	var columns = make([]string, 0, len(o.items))
+	for _, item := range o.items {
+		columns = append(columns, item.Column)
+	}
+	return columns
+}
+
+// Lookup returns the first item on the column
+// Lookup 返回该列上的第一个项
+func (o *Ordering) Lookup(column utils.ColumnNameInterface) (OrderItem, bool) {
+	for _, item := range o.items {
+		if item.Column == column.Name() {
+			return item, true
+		}
+	}
+	return OrderItem{}, false
+}
+
+// Reverse returns the Ordering giving the opposite order, e.g. to read the previous page
+// Reverse 返回顺序相反的 Ordering，例如用于读取上一页
+func (o *Ordering) Reverse() *Ordering {
+	var items = make([]OrderItem, 0, len(o.items))
+	for _, item := range o.items {
+		items = append(items, item.Reverse())
+	}
+	return &Ordering{items: items}
+}
+
+// Dedupe returns the Ordering keeping the first item on each column, the later ones never change the order
+// Dedupe 返回每个列只保留第一个项的 Ordering，后面的项不会改变顺序
+func (o *Ordering) Dedupe() *Ordering {
+	var items = make([]OrderItem, 0, len(o.items))
+	var seen = make(map[string]bool, len(o.items))
+	for _, item := range o.items {
+		if !seen[item.Column] {
+			seen[item.Column] = true
+			items = append(items, item)
+		}
+	}
+	return &Ordering{items: items}
+}
+
+// Render returns the ORDER BY items in the dialect without the "ORDER BY" keyword, e.g. "`rank` DESC, `name` ASC"
+// Render 返回方言中不含 "ORDER BY" 关键字的排序项，例如 "`rank` DESC, `name` ASC"
+func (o *Ordering) Render(dialect Dialect) string {
+	var columns []string
+	for _, item := range o.items {
+		columns = append(columns, item.columns(dialect)...)
+	}
+	return strings.Join(columns, ", ")
+}
+
+// Ob converts the Ordering to an OrderByBottle, e.g. "rank DESC, name ASC"
+// Panics when an item has a NULLS placement, since the native NULLS breaks on MySQL and SQL Server, use Scope instead
+//
+// Ob 将 Ordering 转换为 OrderByBottle，例如 "rank DESC, name ASC"
+// 当某项带有 NULLS 位置时 panic，因为原生的 NULLS 在 MySQL 和 SQL Server 上会出错，请改用 Scope
+func (o *Ordering) Ob() OrderByBottle {
+	for _, item := range o.items {
+		must.Equals(NullsDefault, item.Nulls)
+	}
+	return OrderByBottle(o.Render(""))
+}
+
+// Clause converts the Ordering to clause.OrderBy in the dialect of the DB, e.g. db.Order(ordering.Clause(db))
+// Clause 将 Ordering 转换为数据库方言中的 clause.OrderBy，例如 db.Order(ordering.Clause(db))
+func (o *Ordering) Clause(db *gorm.DB) clause.OrderBy {
+	return o.clauseIn(DialectOf(db))
+}
+
+// clauseIn converts the Ordering to clause.OrderBy in the dialect
+// clauseIn 将 Ordering 转换为方言中的 clause.OrderBy
+func (o *Ordering) clauseIn(dialect Dialect) clause.OrderBy {
+	var columns []clause.OrderByColumn
+	for _, item := range o.items {
+		for _, column := range item.columns(dialect) {
+			columns = append(columns, clause.OrderByColumn{Column: clause.Column{Name: column, Raw: true}})
+		}
+	}
+	return clause.OrderBy{Columns: columns}
+}
+
+// Scope converts the Ordering to a GORM ScopeFunction, rendering in the dialect of the DB
+// Scope 将 Ordering 转换为 GORM 的 ScopeFunction，按数据库方言渲染
+func (o *Ordering) Scope() ScopeFunction {
+	return func(db *gorm.DB) *gorm.DB {
+		if len(o.items) == 0 {
+			return db
+		}
+		return db.Order(o.Clause(db))
+	}
+}
+
+func (o *Ordering) fingerprintTo(parts *fingerprintParts) {
+	if len(o.items) > 0 {
+		parts.orders = append(parts.orders, OrderByBottle(o.Render("")))
+	}
+}
@@ -0,0 +1,108 @@
+// Package gormcnm tests validate the structured ordering with the NULLS placement and the collation
+// Auto verifies the native NULLS spelling and the CASE WHEN emulation in each dialect
+// Tests examine SQLite execution, reversing, deduplicating and the conversion into clause.OrderBy
+//
+// gormcnm 测试包验证带有 NULLS 位置和排序规则的结构化排序
+// 自动验证各方言中的原生 NULLS 写法以及 CASE WHEN 模拟
+// 测试涵盖 SQLite 执行、反转、去重以及转换为 clause.OrderBy
+package gormcnm
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/yyle88/gormcnm/internal/tests"
+	"github.com/yyle88/rese"
+	"gorm.io/gorm"
+)
+
+func TestOrdering(t *testing.T) {
+	type Example struct {
+		Name string `gorm:"primary_key;type:varchar(100);"`
+		Rank *int   `gorm:"column:rank;"`
+	}
+
+	const (
+		columnName = ColumnName[string]("name")
+		columnRank = ColumnName[*int]("rank")
+	)
+
+	ordering := NewOrdering(columnRank.Desc().WithNulls(NullsLast), columnName.Asc())
+	require.Equal(t, "rank DESC NULLS LAST, name ASC", ordering.Render(""))
+	require.Panics(t, func() { ordering.Ob() }) // The native NULLS breaks on MySQL and SQL Server
+	require.Equal(t, "`rank` DESC NULLS LAST, `name` ASC", ordering.Render(DialectSQLite))
+	require.Equal(t, `"rank" DESC NULLS LAST, "name" ASC`, ordering.Render(DialectPostgres))
+	require.Equal(t, "CASE WHEN `rank` IS NULL THEN 1 ELSE 0 END, `rank` DESC, `name` ASC", ordering.Render(DialectMySQL))
+	require.Equal(t, "CASE WHEN [rank] IS NULL THEN 0 ELSE 1 END, [rank] ASC, [name] DESC", ordering.Reverse().Render(DialectSQLServer))
+
+	tests.NewDBRun(t, func(db *gorm.DB) {
+		require.NoError(t, db.AutoMigrate(&Example{}))
+		rank1, rank2 := 1, 2
+		require.NoError(t, db.Create(&[]*Example{
+			{Name: "a", Rank: nil},
+			{Name: "b", Rank: &rank1},
+			{Name: "c", Rank: &rank2},
+			{Name: "d", Rank: &rank1},
+		}).Error)
+
+		selectNames := func(ordering *Ordering) []string {
+			var names []string
+			require.NoError(t, db.Model(&Example{}).Scopes(ordering.Scope()).Pluck(columnName.Name(), &names).Error)
+			return names
+		}
+
+		require.Equal(t, []string{"c", "b", "d", "a"}, selectNames(ordering))
+		require.Equal(t, []string{"a", "d", "b", "c"}, selectNames(ordering.Reverse()))
+		require.Equal(t, []string{"b", "d", "c", "a"}, selectNames(NewOrdering(columnRank.Asc().WithNulls(NullsLast), columnName.Asc())))
+		require.Equal(t, []string{"a", "b", "d", "c"}, selectNames(NewOrdering(columnRank.Asc(), columnName.Asc())))
+	})
+
+	t.Run("mysql", func(t *testing.T) {
+		db := tests.NewDryRunDB(t, "mysql")
+		stmt := db.Scopes(ordering.Scope()).Find(&[]*Example{}).Statement
+		require.Equal(t, "SELECT * FROM `examples` ORDER BY CASE WHEN `rank` IS NULL THEN 1 ELSE 0 END,`rank` DESC,`name` ASC", stmt.SQL.String())
+		stmt = db.Order(ordering.Clause(db)).Find(&[]*Example{}).Statement
+		require.Equal(t, "SELECT * FROM `examples` ORDER BY CASE WHEN `rank` IS NULL THEN 1 ELSE 0 END,`rank` DESC,`name` ASC", stmt.SQL.String())
+	})
+	t.Run("postgres", func(t *testing.T) {
+		db := tests.NewDryRunDB(t, "postgres")
+		stmt := db.Order("id").Scopes(NewOrdering(columnName.Asc().WithCollation("C")).Scope()).Find(&[]*Example{}).Statement
+		require.Equal(t, `SELECT * FROM "examples" ORDER BY id,"name" COLLATE C ASC`, stmt.SQL.String())
+	})
+}
+
+func TestOrdering_Inspect(t *testing.T) {
+	const (
+		columnName = ColumnName[string]("name")
+		columnRank = ColumnName[int]("rank")
+		columnID   = ColumnName[uint]("id")
+	)
+
+	ordering := NewOrdering(columnRank.Desc(), columnName.Asc()).Then(columnRank.Asc(), columnID.Asc())
+	require.Equal(t, 4, ordering.Len())
+	require.Equal(t, []string{"rank", "name", "id"}, ordering.Dedupe().Columns())
+	require.Equal(t, "rank DESC, name ASC, id ASC", string(ordering.Dedupe().Ob()))
+
+	item, ok := ordering.Lookup(columnRank)
+	require.True(t, ok)
+	require.Equal(t, OrderItem{Column: "rank", Direction: OrderDesc}, item)
+	_, ok = NewOrdering(columnName.Asc()).Lookup(columnID)
+	require.False(t, ok)
+
+	require.Equal(t, columnName.Desc().WithNulls(NullsFirst), columnName.Asc().WithNulls(NullsLast).Reverse())
+	require.Equal(t, columnName.Desc(), columnName.Asc().Reverse())
+	require.Equal(t, rese.V1(Fingerprint(ordering.Ob())), rese.V1(Fingerprint(ordering)))
+
+	require.Equal(t, OrderByBottle("rank desc"), columnRank.Ob("desc"))
+	require.Panics(t, func() { columnRank.Ob("DESC; DROP TABLE examples") })
+
+	direction, err := NewOrderDirection(" desc ")
+	require.NoError(t, err)
+	require.Equal(t, OrderDesc, direction)
+	_, err = NewOrderDirection("DESC; DROP TABLE examples")
+	require.ErrorIs(t, err, ErrOrderDirection)
+
+	require.Panics(t, func() {
+		columnName.Asc().WithCollation("C; DROP TABLE examples")
+	})
+}
@@ -1,77 +1,224 @@
 // Package gormcnm provides COALESCE and IFNULL operations for NULL-safe SQL queries
 // Auto handles NULL values in aggregate functions using COALESCE (standard) or IFNULL (MySQL)
 // Supports SUM, COUNT, AVG, MAX, MIN with automatic NULL value protection
+// Supports typed default values bound as query arguments, plain column and multi-column COALESCE
 //
 // gormcnm 提供 COALESCE 和 IFNULL 操作，实现 NULL 安全的 SQL 查询
 // 自动使用 COALESCE（标准）或 IFNULL（MySQL）处理聚合函数中的 NULL 值
 // 支持 SUM、COUNT、AVG、MAX、MIN，具备自动 NULL 值保护
+// 支持以查询参数绑定的类型化默认值，以及单列和多列的 COALESCE
 package gormcnm
 
 import (
+	"reflect"
+	"strings"
+
 	"github.com/yyle88/gormcnm/internal/utils"
+	"github.com/yyle88/must"
 	"github.com/yyle88/tern/zerotern"
 )
 
 // COALESCE creates a COALESCE function wrapper for handling NULL values in SQL queries
 // Auto uses SQL standard COALESCE function, supported by most database systems
+// Prefer the lazily rendered Sx to the string Stmt family when the query runs on several databases
+// Use TypedCOALESCE to get the typed Value, ValueSx, MaxSx and MinSx
 // COALESCE 为处理 SQL 查询中的 NULL 值创建 COALESCE 函数包装器
 // 自动使用 SQL 标准的 COALESCE 函数，被大多数数据库系统支持
+// 查询运行在多种数据库上时，优先使用延迟渲染的 Sx，而不是字符串形式的 Stmt 系列
+// 使用 TypedCOALESCE 获取类型化的 Value、ValueSx、MaxSx 和 MinSx
 func (columnName ColumnName[TYPE]) COALESCE() *CoalesceNonNullGuardian {
-	return NewCoalesceNonNullGuardian("COALESCE", string(columnName))
+	var qs = NewCoalesceNonNullGuardian("COALESCE", string(columnName))
+	qs.zero = zeroLiteral[TYPE]()
+	return qs
 }
 
 // IFNULLFN creates an IFNULL function wrapper for MySQL-specific NULL handling
-// MySQL-specific function, may not be supported in other database systems
+// The string Stmt family spells IFNULL as is, MySQL and SQLite only
+// The lazily rendered Sx spells it in the dialect of the DB, ISNULL on SQL Server and COALESCE on PostgreSQL
+// Use TypedIFNULLFN to get the typed Value, ValueSx, MaxSx and MinSx
 // IFNULLFN 为 MySQL 特定的 NULL 处理创建 IFNULL 函数包装器
-// MySQL 特定函数，在其他数据库系统中可能不受支持
+// 字符串形式的 Stmt 系列原样生成 IFNULL，仅适用于 MySQL 和 SQLite
+// 延迟渲染的 Sx 按数据库方言生成，SQL Server 中为 ISNULL，PostgreSQL 中为 COALESCE
+// 使用 TypedIFNULLFN 获取类型化的 Value、ValueSx、MaxSx 和 MinSx
 func (columnName ColumnName[TYPE]) IFNULLFN() *CoalesceNonNullGuardian {
-	return NewCoalesceNonNullGuardian("IFNULL", string(columnName))
+	var qs = NewCoalesceNonNullGuardian("IFNULL", string(columnName))
+	qs.zero = zeroLiteral[TYPE]()
+	return qs
+}
+
+// TypedCOALESCE creates a COALESCE function wrapper typed with the column type, whose default values are bound as arguments
+// e.g. columnName.TypedCOALESCE().MaxSx("none", "max_name") gives COALESCE(MAX(name), ?) as max_name
+// TypedCOALESCE 创建以列类型约束的 COALESCE 函数包装器，其默认值以参数绑定
+// 例如 columnName.TypedCOALESCE().MaxSx("none", "max_name") 生成 COALESCE(MAX(name), ?) as max_name
+func (columnName ColumnName[TYPE]) TypedCOALESCE() *CoalesceGuardian[TYPE] {
+	return NewCoalesceGuardian[TYPE]("COALESCE", string(columnName))
+}
+
+// TypedIFNULLFN creates an IFNULL function wrapper typed with the column type, whose default values are bound as arguments
+// The lazily rendered Value, ValueSx and Sx spell IFNULL in the dialect of the DB, ISNULL on SQL Server and COALESCE on PostgreSQL
+// TypedIFNULLFN 创建以列类型约束的 IFNULL 函数包装器，其默认值以参数绑定
+// 延迟渲染的 Value、ValueSx 和 Sx 按数据库方言生成 IFNULL，SQL Server 中为 ISNULL，PostgreSQL 中为 COALESCE
+func (columnName ColumnName[TYPE]) TypedIFNULLFN() *CoalesceGuardian[TYPE] {
+	return NewCoalesceGuardian[TYPE]("IFNULL", string(columnName))
+}
+
+// Coalesce creates a COALESCE function wrapper over several columns, giving the first non-NULL value of them
+// e.g. Coalesce(columnNickname, columnUsername).Value("anonymous") gives COALESCE(nickname, username, ?)
+//
+// Coalesce 创建基于多列的 COALESCE 函数包装器，返回其中第一个非 NULL 的值
+// 例如 Coalesce(columnNickname, columnUsername).Value("anonymous") 生成 COALESCE(nickname, username, ?)
+func Coalesce[TYPE any](columns ...ColumnName[TYPE]) *CoalesceGuardian[TYPE] {
+	must.Have(columns)
+	var names = make([]string, 0, len(columns))
+	for _, column := range columns {
+		names = append(names, column.Name())
+	}
+	return NewCoalesceGuardian[TYPE]("COALESCE", names...)
 }
 
-// CoalesceNonNullGuardian provides SQL aggregate functions with NULL value protection
+// CoalesceGuardian provides SQL aggregate functions with NULL value protection
 // Auto handles NULL values using COALESCE or IFNULL functions to provide default values
-// CoalesceNonNullGuardian 提供带有 NULL 值保护的 SQL 聚合函数
+// The TYPE is the column type, which types the default value of Value
+//
+// CoalesceGuardian 提供带有 NULL 值保护的 SQL 聚合函数
 // 自动使用 COALESCE 或 IFNULL 函数处理 NULL 值以提供默认值
-type CoalesceNonNullGuardian struct {
-	method string // SQL function name (COALESCE or IFNULL) // SQL 函数名（COALESCE 或 IFNULL）
-	column string // Column name to apply the function to // 要应用函数的列名
+// TYPE 是列的类型，用于约束 Value 的默认值类型
+type CoalesceGuardian[TYPE any] struct {
+	method  string   // SQL function name (COALESCE or IFNULL) // SQL 函数名（COALESCE 或 IFNULL）
+	columns []string // Column names to apply the function to // 要应用函数的列名
+	zero    string   // SQL literal of the zero value of the column type, the default of MaxStmt and MinStmt // 列类型零值的 SQL 字面量，作为 MaxStmt 和 MinStmt 的默认值
+}
+
+// NewCoalesceGuardian creates a new CoalesceGuardian with specified method and columns
+// NewCoalesceGuardian 使用指定的方法和列名创建新的 CoalesceGuardian
+func NewCoalesceGuardian[TYPE any](methodName string, columnNames ...string) *CoalesceGuardian[TYPE] {
+	return &CoalesceGuardian[TYPE]{
+		method:  methodName,
+		columns: columnNames,
+		zero:    zeroLiteral[TYPE](),
+	}
 }
 
+// CoalesceNonNullGuardian is the untyped CoalesceGuardian, given by COALESCE() and IFNULLFN() to build the string Stmt family
+// Use TypedCOALESCE() and TypedIFNULLFN() to type the default value of Value with the column type
+//
+// CoalesceNonNullGuardian 是非类型化的 CoalesceGuardian，由 COALESCE() 和 IFNULLFN() 返回，用于构建字符串形式的 Stmt 系列
+// 使用 TypedCOALESCE() 和 TypedIFNULLFN() 使 Value 的默认值类型与列类型一致
+type CoalesceNonNullGuardian = CoalesceGuardian[any]
+
 // NewCoalesceNonNullGuardian creates a new CoalesceNonNullGuardian with specified method and column
 // NewCoalesceNonNullGuardian 使用指定的方法和列名创建新的 CoalesceNonNullGuardian
 func NewCoalesceNonNullGuardian(methodName string, columnName string) *CoalesceNonNullGuardian {
-	return &CoalesceNonNullGuardian{
-		method: methodName,
-		column: columnName,
+	return NewCoalesceGuardian[any](methodName, columnName)
+}
+
+// zeroLiteral returns the SQL literal of the zero value of TYPE, ” on string types and 0 on the others
+// zeroLiteral 返回 TYPE 零值的 SQL 字面量，字符串类型为 ”，其他类型为 0
+func zeroLiteral[TYPE any]() string {
+	var rt = reflect.TypeOf((*TYPE)(nil)).Elem()
+	for rt.Kind() == reflect.Pointer {
+		rt = rt.Elem()
 	}
+	if rt.Kind() == reflect.String {
+		return "''"
+	}
+	return "0"
+}
+
+// column returns the columns joined with commas
+// column 返回以逗号连接的列名
+func (qs *CoalesceGuardian[TYPE]) column() string {
+	return strings.Join(qs.columns, ", ")
+}
+
+// Value returns the typed expression "COALESCE(columns, ?)" with the default value bound as a query argument.
+// The NULL function is rendered in the dialect of the DB lazily, IFNULL becomes COALESCE when there are several columns.
+// Use it on typed aggregates to guard them, e.g. Max(columnName).TypedCOALESCE().Value("") gives COALESCE(MAX(name), ?).
+//
+// Value 返回类型化的表达式 "COALESCE(columns, ?)"，默认值以查询参数绑定。
+// NULL 函数按数据库方言延迟渲染，有多列时 IFNULL 会变为 COALESCE。
+// 可用于保护类型化的聚合，例如 Max(columnName).TypedCOALESCE().Value("") 生成 COALESCE(MAX(name), ?)。
+func (qs *CoalesceGuardian[TYPE]) Value(dfv TYPE) *TypedExpr[TYPE] {
+	return NewTypedExpr[TYPE]("?", NewDialectExpression(func(dialect Dialect) (string, []interface{}) {
+		var method = dialect.NullFunc(qs.method)
+		if len(qs.columns) > 1 {
+			method = "COALESCE"
+		}
+		var columns = make([]string, 0, len(qs.columns))
+		for _, column := range qs.columns {
+			columns = append(columns, dialect.quoteColumn(column))
+		}
+		return method + "(" + strings.Join(columns, ", ") + ", ?)", []interface{}{dfv}
+	}))
+}
+
+// Column returns "COALESCE(columns)" as a typed column, giving the first non-NULL value without a default.
+// Column 返回类型化的列 "COALESCE(columns)"，返回第一个非 NULL 的值，不带默认值。
+func (qs *CoalesceGuardian[TYPE]) Column() ColumnName[TYPE] {
+	return scalarFunction[TYPE]("COALESCE", qs.columns...)
+}
+
+// ValueSx returns a SelectStatement selecting Value(dfv) with the alias.
+// ValueSx 返回以别名选择 Value(dfv) 的 SelectStatement。
+func (qs *CoalesceGuardian[TYPE]) ValueSx(dfv TYPE, alias string) *SelectStatement {
+	return qs.Value(dfv).Sx(alias)
+}
+
+// MaxSx returns a SelectStatement selecting MAX of the column guarded with the typed default value.
+// Unlike MaxStmt, the default is bound as an argument, thus it works on string columns too.
+//
+// MaxSx 返回选择列 MAX 值的 SelectStatement，并使用类型化的默认值保护。
+// 与 MaxStmt 不同，默认值以参数绑定，因此字符串列也适用。
+func (qs *CoalesceGuardian[TYPE]) MaxSx(dfv TYPE, alias string) *SelectStatement {
+	return NewCoalesceGuardian[TYPE](qs.method, "MAX("+qs.column()+")").ValueSx(dfv, alias)
+}
+
+// MinSx returns a SelectStatement selecting MIN of the column guarded with the typed default value.
+// MinSx 返回选择列 MIN 值的 SelectStatement，并使用类型化的默认值保护。
+func (qs *CoalesceGuardian[TYPE]) MinSx(dfv TYPE, alias string) *SelectStatement {
+	return NewCoalesceGuardian[TYPE](qs.method, "MIN("+qs.column()+")").ValueSx(dfv, alias)
 }
 
 // Stmt generates an SQL statement for the COALESCE or IFNULL function with the given function and default value.
 // Stmt 生成一个 SQL 语句，包含 COALESCE 或 IFNULL 函数，并指定默认值。
-func (qs *CoalesceNonNullGuardian) Stmt(sfn string, dfv string, alias string) string {
-	return utils.ApplyAliasToColumn(qs.method+"("+sfn+"("+string(qs.column)+"), "+zerotern.VV(dfv, "0")+")", alias)
+func (qs *CoalesceGuardian[TYPE]) Stmt(sfn string, dfv string, alias string) string {
+	return utils.ApplyAliasToColumn(qs.method+"("+sfn+"("+qs.column()+"), "+zerotern.VV(dfv, "0")+")", alias)
+}
+
+// Sx generates a SelectStatement like Stmt, rendering the NULL function in the dialect of the DB lazily.
+// IFNULL becomes ISNULL on SQL Server and COALESCE on PostgreSQL, COALESCE is kept on every dialect.
+// Sx 生成与 Stmt 相同的 SelectStatement，按数据库方言延迟渲染 NULL 函数。
+// IFNULL 在 SQL Server 中变为 ISNULL，在 PostgreSQL 中变为 COALESCE，COALESCE 在各方言中保持不变。
+func (qs *CoalesceGuardian[TYPE]) Sx(sfn string, dfv string, alias string) *SelectStatement {
+	return NewDialectSx(func(dialect Dialect) (string, []interface{}) {
+		return NewCoalesceGuardian[TYPE](dialect.NullFunc(qs.method), qs.columns...).Stmt(sfn, dfv, alias), nil
+	})
 }
 
 // SumStmt generates an SQL statement to calculate the sum of the column, using 0 as the default value.
 // SumStmt 生成一个 SQL 语句，计算列的总和，默认值为 0。
-func (qs *CoalesceNonNullGuardian) SumStmt(alias string) string {
+func (qs *CoalesceGuardian[TYPE]) SumStmt(alias string) string {
 	return qs.Stmt("SUM", "0", alias)
 }
 
-// MaxStmt generates an SQL statement to retrieve the maximum value of the column, using 0 as the default value.
-// MaxStmt 生成一个 SQL 语句，检索列的最大值，默认值为 0。
-func (qs *CoalesceNonNullGuardian) MaxStmt(alias string) string {
-	return qs.Stmt("MAX", "0", alias)
+// MaxStmt generates an SQL statement to retrieve the maximum value of the column, using the zero value of the column type as the default value.
+// The default is ” on string columns and 0 on the others, use TypedCOALESCE().MaxSx with a typed default to choose another one.
+// MaxStmt 生成一个 SQL 语句，检索列的最大值，默认值为列类型的零值。
+// 字符串列的默认值为 ”，其他列为 0，需要其他默认值时请使用带类型化默认值的 TypedCOALESCE().MaxSx。
+func (qs *CoalesceGuardian[TYPE]) MaxStmt(alias string) string {
+	return qs.Stmt("MAX", qs.zero, alias)
 }
 
-// MinStmt generates an SQL statement to retrieve the minimum value of the column, using 0 as the default value.
-// MinStmt 生成一个 SQL 语句，检索列的最小值，默认值为 0。
-func (qs *CoalesceNonNullGuardian) MinStmt(alias string) string {
-	return qs.Stmt("MIN", "0", alias)
+// MinStmt generates an SQL statement to retrieve the minimum value of the column, using the zero value of the column type as the default value.
+// The default is ” on string columns and 0 on the others, use TypedCOALESCE().MinSx with a typed default to choose another one.
+// MinStmt 生成一个 SQL 语句，检索列的最小值，默认值为列类型的零值。
+// 字符串列的默认值为 ”，其他列为 0，需要其他默认值时请使用带类型化默认值的 TypedCOALESCE().MinSx。
+func (qs *CoalesceGuardian[TYPE]) MinStmt(alias string) string {
+	return qs.Stmt("MIN", qs.zero, alias)
 }
 
 // AvgStmt generates an SQL statement to calculate the average value of the column, using 0 as the default value.
 // AvgStmt 生成一个 SQL 语句，计算列的平均值，默认值为 0。
-func (qs *CoalesceNonNullGuardian) AvgStmt(alias string) string {
+func (qs *CoalesceGuardian[TYPE]) AvgStmt(alias string) string {
 	return qs.Stmt("AVG", "0", alias)
 }
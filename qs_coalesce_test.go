@@ -1,9 +1,9 @@
 // Package gormcnm tests validate COALESCE and IFNULL operations for NULL-safe aggregates
-// Auto verifies CoalesceNonNullGuardian functionality with SUM, AVG, MAX, MIN operations
+// Auto verifies CoalesceGuardian functionality with SUM, AVG, MAX, MIN operations
 // Tests cover NULL value protection, default value handling, and MySQL/standard SQL compatibility
 //
 // gormcnm 测试包验证 COALESCE 和 IFNULL 操作，实现 NULL 安全的聚合函数
-// 自动验证 CoalesceNonNullGuardian 功能，包含 SUM、AVG、MAX、MIN 操作
+// 自动验证 CoalesceGuardian 功能，包含 SUM、AVG、MAX、MIN 操作
 // 测试涵盖 NULL 值保护、默认值处理和 MySQL/标准 SQL 兼容性
 package gormcnm
 
@@ -73,4 +73,125 @@ func TestCoalesceStmt(t *testing.T) {
 		require.NoError(t, err)
 		require.Equal(t, 289.5, value)
 	})
+	t.Run("case-6", func(t *testing.T) {
+		var guardian *CoalesceNonNullGuardian = NewCoalesceNonNullGuardian("IFNULL", columnRank.Name())
+		var value int
+		err := db.Model(&Example{}).Select(guardian.SumStmt("total")).First(&value).Error
+		require.NoError(t, err)
+		require.Equal(t, 579, value)
+	})
+}
+
+func TestCoalesceGuardian_Sx(t *testing.T) {
+	type Example struct {
+		Name string `gorm:"primary_key;type:varchar(100);"`
+		Rank int    `gorm:"column:rank;"`
+	}
+
+	const columnRank = ColumnName[int]("rank")
+
+	t.Run("sqlite", func(t *testing.T) {
+		db := tests.NewMemDB(t)
+		require.NoError(t, db.AutoMigrate(&Example{}))
+		require.NoError(t, db.Save(&Example{Name: "aaa", Rank: 123}).Error)
+
+		type Result struct {
+			Total int
+		}
+		var result Result
+		require.NoError(t, db.Model(&Example{}).Clauses(columnRank.IFNULLFN().Sx("SUM", "0", "total")).Take(&result).Error)
+		require.Equal(t, 123, result.Total)
+	})
+	t.Run("sqlserver", func(t *testing.T) {
+		db := tests.NewDryRunDB(t, "sqlserver")
+		stmt := db.Model(&Example{}).Clauses(columnRank.IFNULLFN().Sx("SUM", "0", "total")).Find(&[]map[string]interface{}{}).Statement
+		require.Equal(t, "SELECT ISNULL(SUM(rank), 0) as total FROM [examples]", stmt.SQL.String())
+	})
+	t.Run("postgres", func(t *testing.T) {
+		db := tests.NewDryRunDB(t, "postgres")
+		stmt := db.Model(&Example{}).Clauses(columnRank.IFNULLFN().Sx("MAX", "0", "top")).Find(&[]map[string]interface{}{}).Statement
+		require.Equal(t, `SELECT COALESCE(MAX(rank), 0) as top FROM "examples"`, stmt.SQL.String())
+	})
+}
+
+func TestCoalesceGuardian_Value(t *testing.T) {
+	type Example struct {
+		Name     string  `gorm:"primary_key;type:varchar(100);"`
+		Nickname *string `gorm:"column:nickname;"`
+		Username *string `gorm:"column:username;"`
+		Rank     *int    `gorm:"column:rank;"`
+	}
+
+	const (
+		columnName     = ColumnName[string]("name")
+		columnNickname = ColumnName[*string]("nickname")
+		columnUsername = ColumnName[*string]("username")
+		columnRank     = ColumnName[*int]("rank")
+	)
+
+	var nickname, username = "nick", "user"
+
+	db := tests.NewMemDB(t)
+	require.NoError(t, db.AutoMigrate(&Example{}))
+
+	t.Run("empty-table", func(t *testing.T) {
+		type Result struct {
+			MaxName string
+			MinName string
+			Total   float64
+		}
+		var result Result
+		sx := columnName.TypedCOALESCE().MaxSx("none", "max_name").Combine(
+			columnName.TypedIFNULLFN().MinSx("", "min_name"),
+			Sum(columnRank).TypedCOALESCE().ValueSx(-1, "total"),
+		)
+		require.NoError(t, db.Model(&Example{}).Clauses(sx).Take(&result).Error)
+		require.Equal(t, "none", result.MaxName)
+		require.Equal(t, "", result.MinName)
+		require.Equal(t, -1.0, result.Total)
+	})
+
+	t.Run("zero-default", func(t *testing.T) {
+		// The string Stmt family defaults to the zero value of the column type
+		// 字符串形式的 Stmt 系列默认使用列类型的零值
+		var guardian *CoalesceNonNullGuardian = columnName.COALESCE()
+		require.Equal(t, "COALESCE(MAX(name), '') as max_name", guardian.MaxStmt("max_name"))
+		require.Equal(t, "IFNULL(MIN(name), '') as min_name", columnName.IFNULLFN().MinStmt("min_name"))
+		require.Equal(t, "COALESCE(MAX(rank), 0) as max_rank", columnRank.TypedCOALESCE().MaxStmt("max_rank"))
+		require.Equal(t, "COALESCE(MAX(nickname), '')", columnNickname.COALESCE().MaxStmt(""))
+
+		var maxName string
+		require.NoError(t, db.Model(&Example{}).Select(guardian.MaxStmt("max_name")).Take(&maxName).Error)
+		require.Equal(t, "", maxName)
+	})
+
+	require.NoError(t, db.Save(&Example{Name: "a", Nickname: &nickname, Username: &username}).Error)
+	require.NoError(t, db.Save(&Example{Name: "b", Username: &username}).Error)
+	require.NoError(t, db.Save(&Example{Name: "c"}).Error)
+
+	t.Run("multi-column", func(t *testing.T) {
+		type Result struct {
+			Name    string
+			Display string
+		}
+		var results []*Result
+		display := Coalesce(columnNickname, columnUsername).Value(new(string))
+		require.NoError(t, db.Model(&Example{}).Clauses(NewSx(columnName.Name()).Combine(display.Sx("display"))).Order(columnName.Name()).Find(&results).Error)
+		require.Len(t, results, 3)
+		require.Equal(t, "nick", results[0].Display)
+		require.Equal(t, "user", results[1].Display)
+		require.Equal(t, "", results[2].Display)
+
+		var names []string
+		require.NoError(t, db.Model(&Example{}).Where(display.Eq(&username)).Pluck(columnName.Name(), &names).Error)
+		require.Equal(t, []string{"b"}, names)
+	})
+
+	t.Run("column", func(t *testing.T) {
+		require.Equal(t, "COALESCE(nickname, username)", Coalesce(columnNickname, columnUsername).Column().Name())
+
+		var names []string
+		require.NoError(t, db.Model(&Example{}).Where(Coalesce(columnNickname, columnUsername).Column().IsNotNull()).Order(columnName.Name()).Pluck(columnName.Name(), &names).Error)
+		require.Equal(t, []string{"a", "b"}, names)
+	})
 }
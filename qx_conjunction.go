@@ -7,7 +7,12 @@
 // 支持构建动态查询，具备类型安全的参数管理和 GORM 集成
 package gormcnm
 
-import "gorm.io/gorm"
+import (
+	"strings"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
 
 // QxType is an alias when using QxConjunction as a short type name
 // QxType 是 QxConjunction 的别名，用作简短的类型名称
@@ -16,24 +21,92 @@ type QxType = QxConjunction
 // NewQx creates a new QxConjunction instance with the provided statement and arguments
 // NewQx 使用提供的语句和参数创建一个新的 QxConjunction 实例
 func NewQx(stmt string, args ...interface{}) *QxType {
-	return &QxType{
-		statementArgumentsTuple: newStatementArgumentsTuple(stmt, args),
-	}
+	return NewQxConjunction(stmt, args...)
 }
 
 // QxConjunction is used to construct WHERE queries (AND, OR, NOT) and associated arguments.
 // QxConjunction 用于构造关系查询语句（AND、OR、NOT）以及其对应的参数，以供 db.Where 使用。
 // Example: When combining conditions like a.Eq("xyz") and b.Eq("uvw"), this class concatenates statements with (---) AND (---) and merges arguments into a new list.
 // 示例：当需要组合条件如 a.Eq("xyz") 和 b.Eq("uvw") 时，该工具类将语句用 (---) AND (---) 连接，并将参数列表合并为新的列表。
+// The conditions are kept as a tree (see QxNode), flattening AND/OR chains and rendering only the parentheses in need.
+// 条件以树的形式保存（见 QxNode），展平 AND/OR 链，渲染时只添加必要的括号。
 type QxConjunction struct {
 	*statementArgumentsTuple
+	node *QxNode // Condition tree, rendered into the statement and arguments // 条件树，渲染为语句和参数
 }
 
 // NewQxConjunction creates a new instance of QxConjunction with the provided statement and arguments.
 // NewQxConjunction 使用提供的语句和参数创建一个新的 QxConjunction 实例。
+// A blank statement without arguments gives the empty QxConjunction, see NewEmptyQx.
+// 没有参数的空白语句会得到空的 QxConjunction，见 NewEmptyQx。
+// The nil pointer argument of "column IS NULL", given by Eq(nil) on pointer columns, has no placeholder.
+// It stays in Args(), keeping the arity of Qx1() and the like, but the condition tree drops it, thus Build and Scope bind none.
+// "column IS NULL" 的 nil 指针参数（由指针列上的 Eq(nil) 产生）没有对应的占位符。
+// 它保留在 Args() 中，保持 Qx1() 等方法的参数个数，但条件树会将其丢弃，因此 Build 和 Scope 不会绑定它。
 func NewQxConjunction(stmt string, args ...interface{}) *QxConjunction {
+	if strings.TrimSpace(stmt) == "" && len(args) == 0 {
+		return NewEmptyQx()
+	}
+	var nodeArgs = args
+	if len(args) == 1 && isNilPointer(args[0]) {
+		if predicate := inferPredicate(stmt, nil); predicate != nil && predicate.Op == "IS NULL" {
+			nodeArgs = nil
+		}
+	}
 	return &QxConjunction{
 		statementArgumentsTuple: newStatementArgumentsTuple(stmt, args),
+		node:                    newLeafNode(stmt, nodeArgs, inferPredicate(stmt, nodeArgs)),
+	}
+}
+
+// NewEmptyQx creates the empty QxConjunction, meaning no condition at all.
+// It is the neutral element of AND/OR: combining with it gives the other side, NOT of it stays empty,
+// and Scope() of it adds no WHERE clause. Used to build conditions from optional filters, e.g. EqIfSet.
+//
+// NewEmptyQx 创建空的 QxConjunction，表示没有任何条件。
+// 它是 AND/OR 的中性元素：与其组合得到另一侧，对其 NOT 仍然为空，
+// 其 Scope() 不会添加 WHERE 子句。用于根据可选的过滤条件构建查询，例如 EqIfSet。
+func NewEmptyQx() *QxConjunction {
+	return newQxFromNode(newEmptyNode(), nil)
+}
+
+// QxAND combines the conditions using "AND", skipping nil and empty ones, giving the empty QxConjunction when none is left.
+// QxAND 使用 "AND" 组合条件，跳过 nil 和空条件，没有剩余条件时返回空的 QxConjunction。
+func QxAND(qxs ...*QxConjunction) *QxConjunction {
+	return NewEmptyQx().AND(qxs...)
+}
+
+// QxOR combines the conditions using "OR", skipping nil and empty ones, giving the empty QxConjunction when none is left.
+// QxOR 使用 "OR" 组合条件，跳过 nil 和空条件，没有剩余条件时返回空的 QxConjunction。
+func QxOR(qxs ...*QxConjunction) *QxConjunction {
+	return NewEmptyQx().OR(qxs...)
+}
+
+// newQxFromNode creates a QxConjunction with the condition tree, rendering the statement and arguments on first use
+// Thus chaining AND/OR does not render the growing tree again and again, only Qs(), Args(), Build and Scope render it
+// The err is the mismatch found in the combined parts, kept to be reported when applied
+//
+// newQxFromNode 使用条件树创建 QxConjunction，在首次使用时才渲染出语句和参数
+// 因此链式调用 AND/OR 不会反复渲染不断增长的条件树，只有 Qs()、Args()、Build 和 Scope 才会渲染
+// err 是被组合的各部分中发现的不一致，保存下来以便在应用时报告
+func newQxFromNode(node *QxNode, err error) *QxConjunction {
+	var tuple = newLazyStatementArgumentsTuple(node.Render)
+	tuple.err = err
+	return &QxConjunction{
+		statementArgumentsTuple: tuple,
+		node:                    node,
+	}
+}
+
+// withPredicate returns a QxConjunction with the same statement, describing the leaf with the predicate
+// Used by the lazily rendered conditions, whose statement "?" tells nothing about the column
+//
+// withPredicate 返回语句相同的 QxConjunction，并用谓词描述该叶子节点
+// 用于延迟渲染的条件，其语句 "?" 无法体现列的信息
+func (qx *QxConjunction) withPredicate(predicate *QxPredicate) *QxConjunction {
+	return &QxConjunction{
+		statementArgumentsTuple: qx.statementArgumentsTuple,
+		node:                    newLeafNode(qx.Qs(), qx.Args(), predicate),
 	}
 }
 
@@ -44,40 +117,89 @@ func Qx(stmt string, args ...interface{}) *QxConjunction {
 }
 
 // AND combines the current QxConjunction instance with multiple QxConjunction instances using "AND".
+// Nil and empty instances are skipped, see NewEmptyQx.
 // AND 使用 "AND" 将当前 QxConjunction 实例与多个 QxConjunction 实例组合在一起。
+// nil 和空的实例会被跳过，见 NewEmptyQx。
 func (qx *QxConjunction) AND(cs ...*QxConjunction) *QxConjunction {
-	var qss []QsConjunction
-	var qas []*statementArgumentsTuple
-	for _, c := range cs {
-		qss = append(qss, QsConjunction(c.stmt))
-		qas = append(qas, c.statementArgumentsTuple)
-	}
-
-	return &QxConjunction{
-		statementArgumentsTuple: newStatementArgumentsTuple(string(QsConjunction(qx.stmt).AND(qss...)), qx.safeCombineArguments(qas)),
-	}
+	return newQxFromNode(newJunctionNode(QxKindAND, qx.nodes(cs)), checkTuples(qx.tuples(cs)))
 }
 
 // OR combines the current QxConjunction instance with multiple QxConjunction instances using "OR".
+// Nil and empty instances are skipped, see NewEmptyQx.
 // OR 使用 "OR" 将当前 QxConjunction 实例与多个 QxConjunction 实例组合在一起。
+// nil 和空的实例会被跳过，见 NewEmptyQx。
 func (qx *QxConjunction) OR(cs ...*QxConjunction) *QxConjunction {
-	var qss []QsConjunction
-	var qas []*statementArgumentsTuple
-	for _, c := range cs {
-		qss = append(qss, QsConjunction(c.stmt))
-		qas = append(qas, c.statementArgumentsTuple)
-	}
-	return &QxConjunction{
-		statementArgumentsTuple: newStatementArgumentsTuple(string(QsConjunction(qx.stmt).OR(qss...)), qx.safeCombineArguments(qas)),
-	}
+	return newQxFromNode(newJunctionNode(QxKindOR, qx.nodes(cs)), checkTuples(qx.tuples(cs)))
 }
 
 // NOT negates the current QxConjunction instance by wrapping the statement with "NOT".
 // NOT 通过在语句外包裹 "NOT" 来对当前 QxConjunction 实例进行逻辑取反。
 func (qx *QxConjunction) NOT() *QxConjunction {
-	return &QxConjunction{
-		statementArgumentsTuple: newStatementArgumentsTuple(string(QsConjunction(qx.stmt).NOT()), qx.args),
+	return newQxFromNode(newNotNode(qx.Node()), checkTuples(qx.tuples(nil)))
+}
+
+// nodes returns the condition trees of the current instance and the provided instances
+// nodes 返回当前实例与提供的实例的条件树
+func (qx *QxConjunction) nodes(cs []*QxConjunction) []*QxNode {
+	var nodes = make([]*QxNode, 0, 1+len(cs))
+	nodes = append(nodes, qx.Node())
+	for _, c := range cs {
+		nodes = append(nodes, c.Node())
+	}
+	return nodes
+}
+
+// tuples returns the tuples checked when combining the current instance and the provided instances, skipping nil ones
+// A leaf gives its own statement and arguments, while a combined instance gives only the error found when it was combined,
+// since its parts were checked at that time, thus combining does not render the whole tree
+//
+// tuples 返回组合当前实例与提供的实例时需要校验的元组，跳过 nil
+// 叶子节点给出其自身的语句和参数，而组合得到的实例只给出组合时发现的错误，
+// 因为其各部分在当时已经校验过，因此组合时不会渲染整棵条件树
+func (qx *QxConjunction) tuples(cs []*QxConjunction) []*statementArgumentsTuple {
+	var tuples = make([]*statementArgumentsTuple, 0, 1+len(cs))
+	for _, c := range append([]*QxConjunction{qx}, cs...) {
+		if c == nil {
+			continue
+		}
+		var tuple = newStatementArgumentsTuple("", nil)
+		if node := c.Node(); node.Kind == QxKindLeaf {
+			tuple = newStatementArgumentsTuple(node.Stmt, node.Args)
+		}
+		tuple.err = c.err
+		tuples = append(tuples, tuple)
+	}
+	return tuples
+}
+
+// Node returns the condition tree of the QxConjunction, e.g. to inspect or walk the conditions
+// A nil QxConjunction gives the empty node, thus a nil condition works as no condition
+//
+// Node 返回 QxConjunction 的条件树，例如用于检查或遍历条件
+// nil 的 QxConjunction 返回空节点，因此 nil 条件等同于没有条件
+func (qx *QxConjunction) Node() *QxNode {
+	if qx == nil {
+		return newEmptyNode()
 	}
+	return qx.node
+}
+
+// IsEmpty tells whether the QxConjunction holds no condition, true on nil and NewEmptyQx()
+// IsEmpty 判断 QxConjunction 是否不包含任何条件，nil 和 NewEmptyQx() 均返回 true
+func (qx *QxConjunction) IsEmpty() bool {
+	return qx.Node().IsEmpty()
+}
+
+// Walk visits the nodes of the condition tree in pre-order, skipping the children when fn returns false
+// Walk 按先序遍历条件树的节点，当 fn 返回 false 时跳过其子节点
+func (qx *QxConjunction) Walk(fn func(node *QxNode) bool) {
+	qx.Node().Walk(fn)
+}
+
+// Columns returns the distinct columns the conditions touch, in the order of appearance
+// Columns 按出现顺序返回条件涉及的去重列名
+func (qx *QxConjunction) Columns() []string {
+	return qx.Node().Columns()
 }
 
 // AND1 creates a new QxConjunction instance with the given statement and arguments, then combines it with the current instance using "AND".
@@ -94,10 +216,81 @@ func (qx *QxConjunction) OR1(stmt string, args ...interface{}) *QxConjunction {
 
 // Scope converts the QxConjunction to a GORM ScopeFunction used with db.Scopes().
 // It applies the SELECT conditions defined by QxConjunction to the GORM select.
+// When the QxConjunction is empty, no WHERE clause is added.
+// When the placeholders mismatch the arguments, the error is added to the DB, see ValidationMode and WithValidationMode.
+// The columns of the typed conditions are quoted in the dialect of the DB, e.g. `type` in MySQL, see QxNode.RenderIn.
 // Scope 将 QxConjunction 转换为 GORM 的 ScopeFunction，以便于被 db.Scopes() 调用。
 // 它将 QxConjunction 定义的查询条件应用于 GORM 查询。
+// 当 QxConjunction 为空时，不会添加 WHERE 子句。
+// 当占位符与参数不一致时，错误会被添加到 DB 中，见 ValidationMode 和 WithValidationMode。
+// 类型化条件的列名按数据库方言加引号，例如 MySQL 中为 `type`，见 QxNode.RenderIn。
 func (qx *QxConjunction) Scope() ScopeFunction {
 	return func(db *gorm.DB) *gorm.DB {
-		return db.Where(qx.Qs(), qx.args...)
+		if qx.IsEmpty() {
+			return db
+		}
+		var tuple = qx.renderIn(DialectOf(db))
+		if err := tuple.check(validationModeOf(db.Statement)); err != nil {
+			_ = db.AddError(err)
+			return db
+		}
+		return db.Where(tuple.expression())
+	}
+}
+
+// Build implements clause.Expression, so a QxConjunction can be passed to GORM directly.
+// The statement is wrapped in parentheses, keeping the logic intact inside db.Not and db.Or.
+// Works with any number of arguments, no need to choose Qx1() ... Qx12() by the argument count.
+//
+// With Qx2:
+//
+//	db.Where(columnName.Qx("=?", "abc").AND(columnType.Qx("=?", "xyz")).Qx2())
+//
+// With Build:
+//
+//	db.Where(columnName.Qx("=?", "abc").AND(columnType.Qx("=?", "xyz")))
+//
+// Build 实现 clause.Expression 接口，使 QxConjunction 能直接传给 GORM 使用。
+// 语句会被包裹在括号中，确保在 db.Not 和 db.Or 中逻辑正确。
+// 支持任意数量的参数，不再需要根据参数个数选择 Qx1() ... Qx12()。
+// The empty QxConjunction is written as "(1=1)", matching all the rows.
+// 空的 QxConjunction 写为 "(1=1)"，匹配所有行。
+// The columns of the typed conditions are quoted in the dialect of the GORM statement, like Scope.
+// 类型化条件的列名按 GORM 语句的方言加引号，与 Scope 相同。
+func (qx *QxConjunction) Build(builder clause.Builder) {
+	if qx.IsEmpty() {
+		builder.WriteString("(1=1)")
+		return
+	}
+	var tuple = qx.renderIn(dialectOfBuilder(builder))
+	tuple.checkTo(builder)
+	builder.WriteByte('(')
+	tuple.expression().Build(builder)
+	builder.WriteByte(')')
+}
+
+// renderIn renders the condition tree in the dialect into the tuple applied to GORM, keeping the mismatch found in the parts
+// The columns of the typed conditions are quoted in the dialect, the zero Dialect keeps the classic spelling of Qs()
+// The leaves are checked one by one, since the combinators do not check them in ValidationDisabled mode of the process
+//
+// renderIn 将条件树按方言渲染为应用到 GORM 的元组，并保留各部分中发现的不一致
+// 类型化条件的列名按方言加引号，零值方言保持 Qs() 的经典写法
+// 叶子节点会被逐个校验，因为在进程的 ValidationDisabled 模式下组合方法不会校验它们
+func (qx *QxConjunction) renderIn(dialect Dialect) *statementArgumentsTuple {
+	stmt, args := qx.node.RenderIn(dialect)
+	var tuple = newStatementArgumentsTuple(stmt, args)
+	tuple.err = qx.err
+	if tuple.err == nil {
+		tuple.err = qx.node.validate()
 	}
+	return tuple
+}
+
+// Validate checks the placeholders and the arguments of the condition tree, the one applied by Build and Scope.
+// Returns nil when the ValidationMode of the process is ValidationDisabled.
+//
+// Validate 检查条件树（即 Build 和 Scope 应用的条件）的占位符与参数。
+// 当进程的 ValidationMode 为 ValidationDisabled 时返回 nil。
+func (qx *QxConjunction) Validate() error {
+	return qx.renderIn("").Validate()
 }
@@ -206,3 +206,71 @@ func TestColumnQx_AND_5(t *testing.T) {
 		t.Log(neatjsons.S(one))
 	})
 }
+
+func TestQxConjunction_AND_chain(t *testing.T) {
+	const columnRank = ColumnName[int]("rank")
+
+	// The chained conditions render once when used, not on each AND
+	// 链式组合的条件在使用时才渲染一次，而不是在每次 AND 时渲染
+	qx := NewEmptyQx()
+	for idx := 0; idx < 3000; idx++ {
+		qx = qx.AND(Qx(columnRank.Gt(idx)))
+	}
+	require.Len(t, qx.Node().Children, 3000)
+	require.Len(t, qx.Args(), 3000)
+	require.Equal(t, "rank>? AND rank>? AND rank>?", Qx(columnRank.Gt(1)).AND(Qx(columnRank.Gt(2))).AND(Qx(columnRank.Gt(3))).Qs())
+	require.NoError(t, qx.Validate())
+}
+
+func TestQxConjunction_Build(t *testing.T) {
+	type Example struct {
+		Name string `gorm:"primary_key;type:varchar(100);"`
+		Type string `gorm:"column:type;"`
+		Rank int    `gorm:"column:rank;"`
+	}
+
+	const (
+		columnName = ColumnName[string]("name")
+		columnType = ColumnName[string]("type")
+		columnRank = ColumnName[int]("rank")
+	)
+
+	db := tests.NewMemDB(t)
+	defer rese.F0(rese.P1(db.DB()).Close)
+
+	require.NoError(t, db.AutoMigrate(&Example{}))
+	require.NoError(t, db.Save(&Example{Name: "abc", Type: "xyz", Rank: 1}).Error)
+	require.NoError(t, db.Save(&Example{Name: "aaa", Type: "xxx", Rank: 2}).Error)
+	require.NoError(t, db.Save(&Example{Name: "bbb", Type: "yyy", Rank: 3}).Error)
+
+	t.Run("where", func(t *testing.T) {
+		var one Example
+		require.NoError(t, db.Where(Qx(columnName.Eq("abc")).AND(Qx(columnType.Eq("xyz")))).First(&one).Error)
+		require.Equal(t, "abc", one.Name)
+	})
+	t.Run("not", func(t *testing.T) {
+		var res []*Example
+		require.NoError(t, db.Not(Qx(columnName.Eq("abc")).OR(Qx(columnName.Eq("aaa")))).Find(&res).Error)
+		require.Len(t, res, 1)
+		require.Equal(t, "bbb", res[0].Name)
+	})
+	t.Run("or", func(t *testing.T) {
+		var res []*Example
+		require.NoError(t, db.Where(Qx(columnName.Eq("abc"))).Or(Qx(columnType.Eq("xxx")).AND(Qx(columnRank.Eq(2)))).Order(columnName.Ob("asc").Ox()).Find(&res).Error)
+		require.Len(t, res, 2)
+		require.Equal(t, "aaa", res[0].Name)
+		require.Equal(t, "abc", res[1].Name)
+	})
+	t.Run("many-args", func(t *testing.T) {
+		qx := Qx(columnRank.In([]int{1, 2}))
+		for idx := 0; idx < 12; idx++ {
+			qx = qx.AND(Qx(columnRank.Gte(-idx)))
+		}
+		require.Len(t, qx.Args(), 13)
+
+		var res []*Example
+		require.NoError(t, db.Where(qx).Find(&res).Error)
+		require.Len(t, res, 2)
+		t.Log(neatjsons.S(res))
+	})
+}
@@ -0,0 +1,491 @@
+// Package gormcnm provides the in-memory evaluation of conditions against loaded models, e.g. to filter cached rows
+// Auto follows the SQL semantics: three-valued NULL logic, LIKE wildcards, and the WHERE rejecting the unknown result
+// Supports the conditions built with the typed column operations, and cross-checking the results against a database
+//
+// gormcnm 提供在内存中针对已加载模型求值条件的功能，例如过滤缓存的行
+// 自动遵循 SQL 语义：三值 NULL 逻辑、LIKE 通配符，以及 WHERE 拒绝未知结果
+// 支持由类型化列操作构建的条件，并可与数据库的结果交叉核对
+package gormcnm
+
+import (
+	"cmp"
+	"context"
+	"database/sql/driver"
+	"math"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/pkg/errors"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+)
+
+// ErrMatchUnsupported means the condition can not be evaluated in memory, e.g. a raw statement or a missing column
+// ErrMatchUnsupported 表示条件无法在内存中求值，例如原始语句或缺失的列
+var ErrMatchUnsupported = errors.New("condition not evaluable in memory")
+
+// matchTruth is the three-valued result of SQL conditions
+// matchTruth 是 SQL 条件的三值结果
+type matchTruth int8
+
+const (
+	matchFalse   matchTruth = iota // FALSE
+	matchTrue                      // TRUE
+	matchUnknown                   // UNKNOWN, comparing with NULL // 与 NULL 比较的结果
+)
+
+// truthOf converts the bool into the matchTruth
+// truthOf 将布尔值转换为 matchTruth
+func truthOf(value bool) matchTruth {
+	if value {
+		return matchTrue
+	}
+	return matchFalse
+}
+
+// not negates the truth, UNKNOWN stays UNKNOWN
+// not 对结果取反，UNKNOWN 保持为 UNKNOWN
+func (truth matchTruth) not() matchTruth {
+	switch truth {
+	case matchTrue:
+		return matchFalse
+	case matchFalse:
+		return matchTrue
+	}
+	return matchUnknown
+}
+
+// matchSchemas caches the parsed schemas of the evaluated models
+// matchSchemas 缓存被求值模型的解析结果
+var matchSchemas sync.Map
+
+// Match tells whether the model (struct or pointer to struct) or the map[string]interface{} row satisfies the condition.
+// The columns resolve by the GORM column names of the model (default naming strategy) or by the keys of the map.
+// Comparing with NULL gives UNKNOWN, which does not match, as in a WHERE clause. LIKE and the text patterns match
+// case-sensitively as the SQL standard and PostgreSQL do, only the FOLD variants (e.g. ContainsFold) ignore the case of ASCII letters.
+// Note that SQLite LIKE and the default MySQL collations ignore the case anyway, thus Match may be stricter than those databases.
+// Only the leaves built by typed column operations (see QxPredicate) are supported, others fail with ErrMatchUnsupported.
+//
+// Match 判断模型（结构体或结构体指针）或 map[string]interface{} 行是否满足条件。
+// 列按模型的 GORM 列名（默认命名策略）或 map 的键查找。
+// 与 NULL 比较得到 UNKNOWN，与 WHERE 子句一样视为不匹配。LIKE 和文本匹配与 SQL 标准及 PostgreSQL 一致区分大小写，
+// 只有 FOLD 变体（例如 ContainsFold）忽略 ASCII 字母的大小写。
+// 注意 SQLite 的 LIKE 和 MySQL 默认排序规则总是忽略大小写，因此 Match 可能比这些数据库更严格。
+// 仅支持由类型化列操作构建的叶子节点（见 QxPredicate），其他情况返回 ErrMatchUnsupported。
+func (qx *QxConjunction) Match(object interface{}) (bool, error) {
+	return qx.match(object, parseMatchSchema)
+}
+
+// parseMatchSchema parses the model with the default naming strategy, caching the schemas in matchSchemas
+// parseMatchSchema 使用默认命名策略解析模型，并将结果缓存在 matchSchemas 中
+func parseMatchSchema(object interface{}) (*schema.Schema, error) {
+	return schema.Parse(object, &matchSchemas, schema.NamingStrategy{})
+}
+
+// match evaluates the condition on the object, resolving the columns of the models with the parse function
+// match 在对象上对条件求值，使用 parse 函数解析模型的列
+func (qx *QxConjunction) match(object interface{}, parse func(object interface{}) (*schema.Schema, error)) (bool, error) {
+	lookup, err := newMatchLookup(object, parse)
+	if err != nil {
+		return false, err
+	}
+	truth, err := evaluateNode(qx.Node(), lookup)
+	if err != nil {
+		return false, err
+	}
+	return truth == matchTrue, nil
+}
+
+// newMatchLookup returns the function reading the column values of the object, the models being parsed with the parse function
+// newMatchLookup 返回读取对象列值的函数，模型使用 parse 函数解析
+func newMatchLookup(object interface{}, parse func(object interface{}) (*schema.Schema, error)) (func(column string) (interface{}, error), error) {
+	if row, ok := object.(map[string]interface{}); ok {
+		return func(column string) (interface{}, error) {
+			value, ok := row[column]
+			if !ok {
+				return nil, errors.WithMessagef(ErrMatchUnsupported, "column %q missing in the map", column)
+			}
+			return value, nil
+		}, nil
+	}
+	rv := reflect.ValueOf(object)
+	for rv.Kind() == reflect.Ptr && !rv.IsNil() {
+		rv = rv.Elem()
+	}
+	if rv.Kind() != reflect.Struct {
+		return nil, errors.WithMessagef(ErrMatchUnsupported, "object of %T is not a model or a map", object)
+	}
+	sch, err := parse(object)
+	if err != nil {
+		return nil, errors.WithMessagef(ErrMatchUnsupported, "object of %T: %s", object, err.Error())
+	}
+	return func(column string) (interface{}, error) {
+		field := sch.LookUpField(column)
+		if field == nil {
+			return nil, errors.WithMessagef(ErrMatchUnsupported, "column %q missing in %T", column, object)
+		}
+		value, _ := field.ValueOf(context.Background(), rv)
+		return value, nil
+	}, nil
+}
+
+// evaluateNode evaluates the tree node, evaluating all the children thus the errors do not depend on the values
+// evaluateNode 对条件树节点求值，所有子节点都会被求值，因此错误不依赖于具体的值
+func evaluateNode(node *QxNode, lookup func(column string) (interface{}, error)) (matchTruth, error) {
+	switch node.Kind {
+	case QxKindAND, QxKindOR:
+		var res = truthOf(node.Kind == QxKindAND) // The empty AND holds // 空的 AND 成立
+		for _, child := range node.Children {
+			truth, err := evaluateNode(child, lookup)
+			if err != nil {
+				return matchFalse, err
+			}
+			switch {
+			case node.Kind == QxKindAND && (truth == matchFalse || res == matchFalse):
+				res = matchFalse
+			case node.Kind == QxKindOR && (truth == matchTrue || res == matchTrue):
+				res = matchTrue
+			case truth == matchUnknown:
+				res = matchUnknown
+			}
+		}
+		return res, nil
+	case QxKindNOT:
+		truth, err := evaluateNode(node.Children[0], lookup)
+		return truth.not(), err
+	}
+	var predicate = node.Predicate
+	if predicate == nil {
+		return matchFalse, errors.WithMessagef(ErrMatchUnsupported, "raw statement %q", node.Stmt)
+	}
+	for _, value := range predicate.Values {
+		switch value.(type) {
+		case clause.Expression, *gorm.DB:
+			return matchFalse, errors.WithMessagef(ErrMatchUnsupported, "expression value on column %q", predicate.Column)
+		}
+	}
+	value, err := lookup(unqualifiedColumn(predicate.Column)) // The table name does not matter with a single row // 单行求值时表名无关紧要
+	if err != nil {
+		return matchFalse, err
+	}
+	truth, err := evaluatePredicate(predicate, normalizeMatchValue(value))
+	if err != nil {
+		return matchFalse, errors.WithMessagef(err, "column %q", predicate.Column)
+	}
+	return truth, nil
+}
+
+// evaluatePredicate evaluates the predicate on the column value, nil being NULL
+// evaluatePredicate 在列值上对谓词求值，nil 表示 NULL
+func evaluatePredicate(predicate *QxPredicate, value interface{}) (matchTruth, error) {
+	var op = predicate.Op
+	var not = strings.HasPrefix(op, "NOT ")
+	switch strings.TrimPrefix(op, "NOT ") {
+	case "IS NULL":
+		return truthOf(value == nil), nil
+	case "IS NOT NULL":
+		return truthOf(value != nil), nil
+	case "IS TRUE", "IS FALSE":
+		if value == nil {
+			return matchFalse, nil
+		}
+		number, ok := toMatchNumber(value)
+		if !ok {
+			return matchFalse, errors.WithMessagef(ErrMatchUnsupported, "%s on %T", op, value)
+		}
+		return truthOf((number != 0) == (op == "IS TRUE")), nil
+	case "IS DISTINCT FROM", "IS NOT DISTINCT FROM":
+		var other = normalizeMatchValue(predicate.Values[0])
+		if value == nil || other == nil {
+			return truthOf((value == nil) == (other == nil) == (op == "IS NOT DISTINCT FROM")), nil
+		}
+		sign, err := compareMatchValues(value, other)
+		return truthOf((sign == 0) == (op == "IS NOT DISTINCT FROM")), err
+	case "IN":
+		truth, err := evaluateIn(value, predicate.Values[0])
+		return negateIf(not, truth), err
+	case "BETWEEN":
+		low, err := compareWithNull(value, predicate.Values[0], func(sign int) bool { return sign >= 0 })
+		if err != nil {
+			return matchFalse, err
+		}
+		high, err := compareWithNull(value, predicate.Values[1], func(sign int) bool { return sign <= 0 })
+		if err != nil {
+			return matchFalse, err
+		}
+		return negateIf(not, andTruth(low, high)), nil
+	case "LIKE":
+		truth, err := evaluateLike(value, predicate.Values[0], false, false)
+		return negateIf(not, truth), err
+	case "CONTAINS", "HAS PREFIX", "HAS SUFFIX", "CONTAINS FOLD", "HAS PREFIX FOLD", "HAS SUFFIX FOLD":
+		text, ok := predicate.Values[0].(string)
+		if !ok {
+			return matchFalse, errors.WithMessagef(ErrMatchUnsupported, "%s with %T", op, predicate.Values[0])
+		}
+		var pattern = Dialect("").EscapeLike(text)
+		switch strings.TrimSuffix(strings.TrimPrefix(op, "NOT "), " FOLD") {
+		case "CONTAINS":
+			pattern = "%" + pattern + "%"
+		case "HAS PREFIX":
+			pattern = pattern + "%"
+		default:
+			pattern = "%" + pattern
+		}
+		truth, err := evaluateLike(value, pattern, true, strings.HasSuffix(op, " FOLD"))
+		return negateIf(not, truth), err
+	}
+	var compare = map[string]func(sign int) bool{
+		"=":  func(sign int) bool { return sign == 0 },
+		"!=": func(sign int) bool { return sign != 0 },
+		"<>": func(sign int) bool { return sign != 0 },
+		">":  func(sign int) bool { return sign > 0 },
+		">=": func(sign int) bool { return sign >= 0 },
+		"<":  func(sign int) bool { return sign < 0 },
+		"<=": func(sign int) bool { return sign <= 0 },
+	}[op]
+	if compare == nil {
+		return matchFalse, errors.WithMessagef(ErrMatchUnsupported, "operator %q", op)
+	}
+	return compareWithNull(value, predicate.Values[0], compare)
+}
+
+// negateIf negates the truth when not is set
+// negateIf 当 not 为真时对结果取反
+func negateIf(not bool, truth matchTruth) matchTruth {
+	if not {
+		return truth.not()
+	}
+	return truth
+}
+
+// andTruth combines the truths with the three-valued AND
+// andTruth 使用三值 AND 组合结果
+func andTruth(a, b matchTruth) matchTruth {
+	switch {
+	case a == matchFalse || b == matchFalse:
+		return matchFalse
+	case a == matchUnknown || b == matchUnknown:
+		return matchUnknown
+	}
+	return matchTrue
+}
+
+// compareWithNull compares the value with the argument, UNKNOWN when either is NULL
+// compareWithNull 比较值与参数，任一为 NULL 时返回 UNKNOWN
+func compareWithNull(value interface{}, arg interface{}, compare func(sign int) bool) (matchTruth, error) {
+	var other = normalizeMatchValue(arg)
+	if value == nil || other == nil {
+		return matchUnknown, nil
+	}
+	sign, err := compareMatchValues(value, other)
+	if err != nil {
+		return matchFalse, err
+	}
+	return truthOf(compare(sign)), nil
+}
+
+// evaluateIn evaluates IN: TRUE on any equal item, otherwise UNKNOWN when the value or any item is NULL
+// evaluateIn 对 IN 求值：任一项相等时为 TRUE，否则当值或任一项为 NULL 时为 UNKNOWN
+func evaluateIn(value interface{}, list interface{}) (matchTruth, error) {
+	var items []interface{}
+	if rv := reflect.ValueOf(list); rv.Kind() == reflect.Slice || rv.Kind() == reflect.Array {
+		for idx := 0; idx < rv.Len(); idx++ {
+			items = append(items, rv.Index(idx).Interface())
+		}
+	} else {
+		items = append(items, list)
+	}
+	var res = matchFalse
+	for _, item := range items {
+		truth, err := compareWithNull(value, item, func(sign int) bool { return sign == 0 })
+		if err != nil {
+			return matchFalse, err
+		}
+		if truth == matchTrue {
+			return matchTrue, nil
+		}
+		if truth == matchUnknown {
+			res = matchUnknown
+		}
+	}
+	return res, nil
+}
+
+// evaluateLike matches the value with the LIKE pattern, the backslash escaping the wildcards when escape is set
+// The ASCII letters match case-insensitively when fold is set
+// evaluateLike 使用 LIKE 模式匹配值，escape 为真时反斜杠转义通配符
+// fold 为真时 ASCII 字母不区分大小写
+func evaluateLike(value interface{}, pattern interface{}, escape bool, fold bool) (matchTruth, error) {
+	var other = normalizeMatchValue(pattern)
+	if value == nil || other == nil {
+		return matchUnknown, nil
+	}
+	text, ok := toMatchComparable(value).(string)
+	if !ok {
+		return matchFalse, errors.WithMessagef(ErrMatchUnsupported, "LIKE on %T", value)
+	}
+	patternText, ok := toMatchComparable(other).(string)
+	if !ok {
+		return matchFalse, errors.WithMessagef(ErrMatchUnsupported, "LIKE with %T", other)
+	}
+	return truthOf(matchLike([]rune(text), []rune(patternText), escape, fold)), nil
+}
+
+// matchLike matches the text with the pattern, "%" matching any runes and "_" a single rune, ASCII letters case-insensitively when fold is set
+// matchLike 使用模式匹配文本，"%" 匹配任意字符，"_" 匹配单个字符，fold 为真时 ASCII 字母不区分大小写
+func matchLike(text []rune, pattern []rune, escape bool, fold bool) bool {
+	var same = func(a, b rune) bool {
+		if fold {
+			return foldASCII(a) == foldASCII(b)
+		}
+		return a == b
+	}
+	var ti, pi = 0, 0
+	var starP, starT = -1, 0
+	for ti < len(text) {
+		if pi < len(pattern) {
+			switch c := pattern[pi]; {
+			case c == '%':
+				starP, starT = pi, ti
+				pi++
+				continue
+			case c == '_':
+				ti++
+				pi++
+				continue
+			case escape && c == '\\' && pi+1 < len(pattern):
+				if same(pattern[pi+1], text[ti]) {
+					ti++
+					pi += 2
+					continue
+				}
+			default:
+				if same(c, text[ti]) {
+					ti++
+					pi++
+					continue
+				}
+			}
+		}
+		if starP < 0 {
+			return false
+		}
+		starT++
+		ti, pi = starT, starP+1
+	}
+	for pi < len(pattern) && pattern[pi] == '%' {
+		pi++
+	}
+	return pi == len(pattern)
+}
+
+// foldASCII lowers the ASCII letters
+// foldASCII 将 ASCII 字母转为小写
+func foldASCII(c rune) rune {
+	if 'A' <= c && c <= 'Z' {
+		return c + ('a' - 'A')
+	}
+	return c
+}
+
+// normalizeMatchValue dereferences the pointers and resolves the driver.Valuer values, nil being NULL
+// normalizeMatchValue 解引用指针并解析 driver.Valuer 的值，nil 表示 NULL
+func normalizeMatchValue(value interface{}) interface{} {
+	for value != nil {
+		rv := reflect.ValueOf(value)
+		if rv.Kind() == reflect.Ptr && rv.IsNil() {
+			return nil
+		}
+		if valuer, ok := value.(driver.Valuer); ok {
+			res, err := valuer.Value()
+			if err != nil {
+				return nil
+			}
+			if _, again := res.(driver.Valuer); !again {
+				return res
+			}
+			value = res
+			continue
+		}
+		if rv.Kind() != reflect.Ptr {
+			return value
+		}
+		value = rv.Elem().Interface()
+	}
+	return nil
+}
+
+// toMatchComparable converts the value into int64, float64, string or time.Time, bools being 0 and 1 as SQL stores them
+// toMatchComparable 将值转换为 int64、float64、string 或 time.Time，布尔值按 SQL 的存储方式转换为 0 和 1
+func toMatchComparable(value interface{}) interface{} {
+	switch x := value.(type) {
+	case time.Time:
+		return x
+	case []byte:
+		if utf8.Valid(x) {
+			return string(x)
+		}
+		return x
+	}
+	rv := reflect.ValueOf(value)
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return rv.Int()
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		if rv.Uint() <= math.MaxInt64 {
+			return int64(rv.Uint())
+		}
+		return float64(rv.Uint())
+	case reflect.Float32, reflect.Float64:
+		return rv.Float()
+	case reflect.Bool:
+		if rv.Bool() {
+			return int64(1)
+		}
+		return int64(0)
+	case reflect.String:
+		return rv.String()
+	}
+	return value
+}
+
+// toMatchNumber converts the value into float64 when it is a number or a bool
+// toMatchNumber 当值为数字或布尔值时将其转换为 float64
+func toMatchNumber(value interface{}) (float64, bool) {
+	switch x := toMatchComparable(value).(type) {
+	case int64:
+		return float64(x), true
+	case float64:
+		return x, true
+	}
+	return 0, false
+}
+
+// compareMatchValues compares the non-NULL values, integers exactly, failing when their kinds differ
+// compareMatchValues 比较非 NULL 的值，整数精确比较，类型不同时返回错误
+func compareMatchValues(a, b interface{}) (int, error) {
+	switch x := toMatchComparable(a).(type) {
+	case int64:
+		if y, ok := toMatchComparable(b).(int64); ok {
+			return cmp.Compare(x, y), nil
+		}
+	case string:
+		if y, ok := toMatchComparable(b).(string); ok {
+			return strings.Compare(x, y), nil
+		}
+	case time.Time:
+		if y, ok := toMatchComparable(b).(time.Time); ok {
+			return x.Compare(y), nil
+		}
+	}
+	if x, ok := toMatchNumber(a); ok {
+		if y, ok := toMatchNumber(b); ok {
+			return cmp.Compare(x, y), nil
+		}
+	}
+	return 0, errors.WithMessagef(ErrMatchUnsupported, "comparing %T with %T", a, b)
+}
@@ -0,0 +1,208 @@
+// Package gormcnm tests validate the in-memory evaluation of conditions
+// Auto verifies the three-valued NULL logic, the LIKE wildcards and the text patterns
+// Tests examine models and maps, cross-checked against SQLite row by row
+//
+// gormcnm 测试包验证条件的内存求值
+// 自动验证三值 NULL 逻辑、LIKE 通配符以及文本匹配
+// 测试涵盖模型和 map，并逐行与 SQLite 的结果交叉核对
+package gormcnm
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+	"github.com/yyle88/gormcnm/internal/tests"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+)
+
+// errMatchMismatch means the in-memory evaluation differs from the database result
+// errMatchMismatch 表示内存求值与数据库的结果不一致
+var errMatchMismatch = errors.New("in-memory match differs from the database")
+
+// errCrossCheckRollback rolls back the rows inserted by crossCheckMatch
+// errCrossCheckRollback 用于回滚 crossCheckMatch 插入的行
+var errCrossCheckRollback = errors.New("cross check rollback")
+
+// crossCheckMatch compares Match with the database on each row, designed for a scratch DB such as in-memory SQLite.
+// It migrates the table of MOD, then inserts a shallow copy of each row in a rolled back transaction,
+// and counts the inserted row by its primary key with the condition. Thus MOD needs a primary key, and the rows are left untouched.
+// The columns resolve by the naming strategy of the DB on both sides. Returns errMatchMismatch naming the first differing row.
+//
+// crossCheckMatch 逐行比较 Match 与数据库的结果，适用于临时数据库，例如内存 SQLite。
+// 它会迁移 MOD 的表，然后在会回滚的事务中插入每一行的浅拷贝，并按主键使用条件统计插入的行。
+// 因此 MOD 需要有主键，且传入的行不会被修改。
+// 两边的列都按 DB 的命名策略解析。返回 errMatchMismatch 并指出第一个不一致的行。
+func crossCheckMatch[MOD any](db *gorm.DB, qx *QxConjunction, rows []*MOD) error {
+	if err := db.AutoMigrate(new(MOD)); err != nil {
+		return err
+	}
+	var parse = func(object interface{}) (*schema.Schema, error) {
+		var stmt = &gorm.Statement{DB: db}
+		if err := stmt.Parse(object); err != nil {
+			return nil, err
+		}
+		return stmt.Schema, nil
+	}
+	sch, err := parse(new(MOD))
+	if err != nil {
+		return err
+	}
+	if len(sch.PrimaryFields) == 0 {
+		return errors.WithMessagef(ErrMatchUnsupported, "model %s without primary key", sch.Name)
+	}
+	for idx, row := range rows {
+		matched, err := qx.match(row, parse)
+		if err != nil {
+			return errors.WithMessagef(err, "row %d", idx)
+		}
+		var clone = *row // Keeps the auto increment keys off the row // 避免自增主键写回到行中
+		var count int64
+		err = db.Transaction(func(tx *gorm.DB) error {
+			if err := tx.Create(&clone).Error; err != nil {
+				return err
+			}
+			var conditions = make([]clause.Expression, 0, len(sch.PrimaryFields))
+			for _, field := range sch.PrimaryFields {
+				value, _ := field.ValueOf(tx.Statement.Context, reflect.ValueOf(&clone).Elem())
+				conditions = append(conditions, clause.Eq{Column: clause.Column{Table: clause.CurrentTable, Name: field.DBName}, Value: value})
+			}
+			if err := tx.Model(new(MOD)).Where(clause.And(conditions...)).Scopes(qx.Scope()).Count(&count).Error; err != nil {
+				return err
+			}
+			return errCrossCheckRollback
+		})
+		if !errors.Is(err, errCrossCheckRollback) {
+			return errors.WithMessagef(err, "row %d", idx)
+		}
+		if matched != (count > 0) {
+			return errors.WithMessagef(errMatchMismatch, "row %d matches %v in memory but %v in the database", idx, matched, count > 0)
+		}
+	}
+	return nil
+}
+
+func TestQxConjunction_Match(t *testing.T) {
+	type Example struct {
+		ID     uint    `gorm:"primaryKey"`
+		Name   string  `gorm:"column:name;"`
+		Type   *string `gorm:"column:type;"`
+		Rank   int     `gorm:"column:rank;"`
+		Active bool    `gorm:"column:active;"`
+	}
+
+	const (
+		columnName   = ColumnName[string]("name")
+		columnType   = ColumnName[*string]("type")
+		columnRank   = ColumnName[int]("rank")
+		columnActive = ColumnName[bool]("active")
+	)
+
+	var xyz, uvw = "xyz", "uvw"
+	rows := []*Example{
+		{Name: "abc", Type: &xyz, Rank: 1, Active: true},
+		{Name: "ABD", Type: nil, Rank: 2},
+		{Name: "a_b", Type: &uvw, Rank: 3, Active: true},
+		{Name: "x%y", Type: nil, Rank: 4},
+	}
+
+	conditions := []*QxConjunction{
+		Qx(columnType.Eq(&xyz)),
+		Qx(columnType.Eq(&xyz)).NOT(),
+		Qx(columnType.NotIn([]*string{&xyz})),
+		Qx(columnType.In([]*string{&uvw, nil})).NOT(),
+		Qx(columnType.Ne(&xyz)).OR(Qx(columnRank.Gt(3))),
+		Qx(columnType.Ne(&xyz)).AND(Qx(columnRank.Gt(3))).NOT(),
+		Qx(columnType.IsNULL()),
+		Qx(columnRank.Between(2, 3)),
+		Qx(columnRank.NotBetween(2, 3)),
+		Qx(columnName.Like("ab%")),
+		Qx(columnName.NotLike("a_b")),
+		NewStringColumn(columnName).Contains("_"),
+		NewStringColumn(columnName).Contains("%"),
+		NewStringColumn(columnName).Contains("B"),
+		NewStringColumn(columnName).HasPrefixFold("AB"),
+		NewStringColumn(columnName).NotHasSuffix("b"),
+		Qx(columnActive.IsTRUE()),
+		Qx(columnActive.IsFALSE()).OR(Qx(columnRank.Eq(1))),
+		columnType.DistinctFrom(&xyz),
+		columnType.NotDistinctFrom(nil),
+		NewEmptyQx(),
+	}
+
+	tests.NewDBRun(t, func(db *gorm.DB) {
+		// SQLite LIKE ignores the case by default, while Match follows the SQL standard and PostgreSQL
+		// SQLite 的 LIKE 默认忽略大小写，而 Match 遵循 SQL 标准和 PostgreSQL
+		require.NoError(t, db.Exec("PRAGMA case_sensitive_like = ON").Error)
+		for _, qx := range conditions {
+			require.NoError(t, crossCheckMatch(db, qx, rows), qx.Qs())
+		}
+		for _, row := range rows {
+			require.Zero(t, row.ID) // The rows are inserted as copies // 插入的是行的副本
+		}
+
+		// A row in the table already does not count for the checked rows
+		// 表中已有的行不会计入被检查的行
+		require.NoError(t, db.Create(&Example{Name: "abc", Type: &xyz, Rank: 5}).Error)
+		require.NoError(t, crossCheckMatch(db, Qx(columnName.Eq("abc")), rows))
+	})
+
+	// The text patterns are case-sensitive unless folded
+	// 文本匹配区分大小写，FOLD 变体除外
+	matched, err := NewStringColumn(columnName).Contains("b").Match(rows[1])
+	require.NoError(t, err)
+	require.False(t, matched)
+
+	matched, err = NewStringColumn(columnName).ContainsFold("b").Match(rows[1])
+	require.NoError(t, err)
+	require.True(t, matched)
+
+	matched, err = Qx(columnType.Eq(&xyz)).NOT().Match(rows[1])
+	require.NoError(t, err)
+	require.False(t, matched) // NOT of UNKNOWN is still UNKNOWN
+
+	matched, err = Qx(columnType.Eq(&xyz)).NOT().Match(*rows[2])
+	require.NoError(t, err)
+	require.True(t, matched)
+}
+
+func TestQxConjunction_Match_Map(t *testing.T) {
+	const (
+		columnName = ColumnName[string]("name")
+		columnRank = ColumnName[int]("rank")
+	)
+
+	qx := Qx(columnName.In([]string{"abc", "xyz"})).AND(Qx(columnRank.TN("examples").Cnm().Gte(2)))
+
+	matched, err := qx.Match(map[string]interface{}{"name": "abc", "rank": int64(2)})
+	require.NoError(t, err)
+	require.True(t, matched)
+
+	matched, err = qx.Match(map[string]interface{}{"name": "abc", "rank": nil})
+	require.NoError(t, err)
+	require.False(t, matched)
+
+	_, err = qx.Match(map[string]interface{}{"name": "abc"})
+	require.ErrorIs(t, err, ErrMatchUnsupported)
+
+	_, err = Qx("LENGTH(name) > ?", 3).Match(map[string]interface{}{"name": "abc"})
+	require.ErrorIs(t, err, ErrMatchUnsupported)
+
+	_, err = Qx(columnName.Eq("abc")).Match("abc")
+	require.ErrorIs(t, err, ErrMatchUnsupported)
+}
+
+func TestMatchLike(t *testing.T) {
+	require.True(t, matchLike([]rune("Hello"), []rune("h%O"), false, true))
+	require.False(t, matchLike([]rune("Hello"), []rune("h%O"), false, false))
+	require.True(t, matchLike([]rune("Hello"), []rune("H%o"), false, false))
+	require.True(t, matchLike([]rune("a_b"), []rune(`a\_b`), true, false))
+	require.False(t, matchLike([]rune("axb"), []rune(`a\_b`), true, false))
+	require.True(t, matchLike([]rune(`a\b`), []rune(`a\b`), false, false))
+	require.True(t, matchLike([]rune("名字"), []rune("_字"), false, false))
+	require.False(t, matchLike([]rune("abc"), []rune("%d%"), false, false))
+	require.True(t, matchLike([]rune(""), []rune("%"), false, false))
+}
@@ -0,0 +1,271 @@
+// Package gormcnm provides the condition tree behind QxConjunction
+// Auto flattens associative AND/OR chains and only adds the parentheses the SQL needs
+// Supports walking the tree in user code, e.g. to list the columns a filter touches
+//
+// gormcnm 提供 QxConjunction 背后的条件树
+// 自动展平可结合的 AND/OR 链，只添加 SQL 需要的括号
+// 支持在用户代码中遍历条件树，例如列出过滤条件涉及的列
+package gormcnm
+
+import (
+	"regexp"
+	"strings"
+)
+
+// QxKind represents the kind of a condition tree node
+// QxKind 表示条件树节点的类型
+type QxKind string
+
+const (
+	QxKindLeaf QxKind = "LEAF" // A statement with its arguments // 带参数的语句
+	QxKindAND  QxKind = "AND"  // All the children hold // 所有子节点都成立
+	QxKindOR   QxKind = "OR"   // Any of the children holds // 任一子节点成立
+	QxKindNOT  QxKind = "NOT"  // The single child does not hold // 唯一的子节点不成立
+)
+
+// QxPredicate describes a leaf comparing a single column, e.g. "name = ?" with the value
+// It is set by the typed column operations, raw statements have no predicate
+//
+// QxPredicate 描述对单个列进行比较的叶子节点，例如 "name = ?" 及其值
+// 由类型化的列操作设置，原始语句没有谓词
+type QxPredicate struct {
+	Column string        // Column name // 列名
+	Op     string        // Operator, e.g. "=", "IN", "LIKE", "IS NULL", "BETWEEN", "CONTAINS" // 运算符，例如 "="、"IN"、"LIKE"、"IS NULL"、"BETWEEN"、"CONTAINS"
+	Values []interface{} // Compared values // 比较的值
+}
+
+// QxNode is a node of the condition tree, a leaf statement or an AND/OR/NOT of the children
+// QxNode 是条件树的节点，可以是叶子语句，也可以是子节点的 AND/OR/NOT
+type QxNode struct {
+	Kind      QxKind        // Node kind // 节点类型
+	Stmt      string        // Leaf statement // 叶子节点的语句
+	Args      []interface{} // Leaf arguments // 叶子节点的参数
+	Predicate *QxPredicate  // Leaf predicate, nil on raw statements // 叶子节点的谓词，原始语句为 nil
+	Children  []*QxNode     // Children of AND/OR/NOT // AND/OR/NOT 的子节点
+}
+
+// newLeafNode creates a leaf node with the statement and arguments
+// newLeafNode 使用语句和参数创建叶子节点
+func newLeafNode(stmt string, args []interface{}, predicate *QxPredicate) *QxNode {
+	return &QxNode{Kind: QxKindLeaf, Stmt: stmt, Args: args, Predicate: predicate}
+}
+
+// newEmptyNode creates the empty node, an AND of no children, meaning no condition at all
+// newEmptyNode 创建空节点，即没有子节点的 AND，表示没有任何条件
+func newEmptyNode() *QxNode {
+	return &QxNode{Kind: QxKindAND}
+}
+
+// newJunctionNode creates an AND/OR node, flattening children of the same kind into it
+// Empty children are dropped, and a single remaining child is returned as it is
+//
+// newJunctionNode 创建 AND/OR 节点，将相同类型的子节点展平合并
+// 空的子节点会被丢弃，只剩一个子节点时直接返回该子节点
+func newJunctionNode(kind QxKind, nodes []*QxNode) *QxNode {
+	var children = make([]*QxNode, 0, len(nodes))
+	for _, node := range nodes {
+		if node.IsEmpty() {
+			continue
+		}
+		if node.Kind == kind {
+			children = append(children, node.Children...)
+		} else {
+			children = append(children, node)
+		}
+	}
+	if len(children) == 1 {
+		return children[0]
+	}
+	return &QxNode{Kind: kind, Children: children}
+}
+
+// newNotNode creates a NOT node, a double negation gives back the inner node, the empty node stays empty
+// newNotNode 创建 NOT 节点，双重否定时返回内部节点，空节点仍然为空
+func newNotNode(node *QxNode) *QxNode {
+	if node.IsEmpty() {
+		return node
+	}
+	if node.Kind == QxKindNOT {
+		return node.Children[0]
+	}
+	return &QxNode{Kind: QxKindNOT, Children: []*QxNode{node}}
+}
+
+// IsEmpty tells whether the node holds no condition, i.e. an AND/OR without children
+// IsEmpty 判断节点是否不包含任何条件，即没有子节点的 AND/OR
+func (node *QxNode) IsEmpty() bool {
+	return (node.Kind == QxKindAND || node.Kind == QxKindOR) && len(node.Children) == 0
+}
+
+// Walk visits the node and its descendants in pre-order, skipping the children when fn returns false
+// Walk 按先序遍历节点及其后代，当 fn 返回 false 时跳过其子节点
+func (node *QxNode) Walk(fn func(node *QxNode) bool) {
+	if !fn(node) {
+		return
+	}
+	for _, child := range node.Children {
+		child.Walk(fn)
+	}
+}
+
+// validate checks the placeholders and the arguments of the leaves one by one, catching the mismatches offsetting each other in the whole statement
+// validate 逐个检查叶子节点的占位符与参数，发现在整条语句中相互抵消的不一致
+func (node *QxNode) validate() error {
+	var err error
+	node.Walk(func(node *QxNode) bool {
+		if err == nil && node.Kind == QxKindLeaf {
+			err = ValidateStatement(node.Stmt, node.Args)
+		}
+		return err == nil
+	})
+	return err
+}
+
+// Columns returns the distinct columns of the predicates in the tree, in the order of appearance
+// Columns 按出现顺序返回条件树中谓词涉及的去重列名
+func (node *QxNode) Columns() []string {
+	var columns []string
+	var seen = map[string]bool{}
+	node.Walk(func(node *QxNode) bool {
+		if node.Predicate != nil && !seen[node.Predicate.Column] {
+			seen[node.Predicate.Column] = true
+			columns = append(columns, node.Predicate.Column)
+		}
+		return true
+	})
+	return columns
+}
+
+// Render returns the statement and arguments of the tree
+// Colliding named arguments of the leaves are renamed, and the named arguments are placed after the positional ones
+//
+// Render 返回条件树的语句和参数
+// 叶子节点中冲突的命名参数会被重命名，命名参数排在位置参数之后
+func (node *QxNode) Render() (string, []interface{}) {
+	return node.RenderIn("")
+}
+
+// RenderIn returns the statement and arguments of the tree spelled in the dialect
+// The columns of the predicate leaves are quoted, and IS TRUE/IS FALSE are spelled the way the dialect supports
+// The zero Dialect gives the classic spelling, the same as Render
+//
+// RenderIn 返回按方言生成的条件树语句和参数
+// 谓词叶子节点的列名会加引号，IS TRUE/IS FALSE 会按方言支持的方式生成
+// 零值方言得到经典写法，与 Render 相同
+func (node *QxNode) RenderIn(dialect Dialect) (string, []interface{}) {
+	var sb strings.Builder
+	var merger = newArgumentsMerger()
+	node.render(&sb, merger, dialect)
+	return sb.String(), merger.args()
+}
+
+// render writes the statement of the node in the dialect and merges the arguments in order
+// render 按方言写入节点的语句并按顺序合并参数
+func (node *QxNode) render(sb *strings.Builder, args *argumentsMerger, dialect Dialect) {
+	switch node.Kind {
+	case QxKindNOT:
+		sb.WriteString("NOT (")
+		node.Children[0].render(sb, args, dialect)
+		sb.WriteString(")")
+	case QxKindAND, QxKindOR:
+		for idx, child := range node.Children {
+			if idx > 0 {
+				sb.WriteString(" " + string(node.Kind) + " ")
+			}
+			if child.needsParentheses(node.Kind) {
+				sb.WriteString("(")
+				child.render(sb, args, dialect)
+				sb.WriteString(")")
+			} else {
+				child.render(sb, args, dialect)
+			}
+		}
+	default:
+		sb.WriteString(args.merge(node.spell(dialect), node.Args))
+	}
+}
+
+// spell returns the leaf statement in the dialect, quoting the column of the predicate
+// Raw statements, lazily rendered ones and columns already quoted are kept as they are
+//
+// spell 返回按方言生成的叶子语句，给谓词的列名加引号
+// 原始语句、延迟渲染的语句以及已加引号的列名保持不变
+func (node *QxNode) spell(dialect Dialect) string {
+	if dialect == "" || node.Predicate == nil {
+		return node.Stmt
+	}
+	matches := regexpPredicateStmt.FindStringSubmatchIndex(node.Stmt)
+	if matches == nil {
+		return node.Stmt
+	}
+	var column = node.Stmt[matches[2]:matches[3]]
+	if column != node.Predicate.Column || !regexpOrderColumn.MatchString(column) {
+		return node.Stmt
+	}
+	var quoted = dialect.Quote(column)
+	switch node.Predicate.Op {
+	case "IS TRUE":
+		return dialect.IsTrue(quoted)
+	case "IS FALSE":
+		return dialect.IsFalse(quoted)
+	}
+	return node.Stmt[:matches[2]] + quoted + node.Stmt[matches[3]:]
+}
+
+// needsParentheses tells whether the node must be wrapped when it is a child of the AND/OR parent
+// Raw statements containing AND/OR and lazily rendered statements ("?") are wrapped, since their content is unknown
+//
+// needsParentheses 判断节点作为 AND/OR 父节点的子节点时是否需要加括号
+// 包含 AND/OR 的原始语句以及延迟渲染的语句（"?"）会加括号，因为无法得知其内容
+func (node *QxNode) needsParentheses(parent QxKind) bool {
+	switch node.Kind {
+	case QxKindNOT:
+		return false
+	case QxKindAND, QxKindOR:
+		return node.Kind != parent
+	default:
+		return strings.TrimSpace(node.Stmt) == "?" || regexpJunctionWord.MatchString(node.Stmt)
+	}
+}
+
+var (
+	// regexpJunctionWord matches the AND/OR words in a raw statement, e.g. "a = ? OR b = ?", "a BETWEEN ? AND ?"
+	// regexpJunctionWord 匹配原始语句中的 AND/OR 关键字，例如 "a = ? OR b = ?"、"a BETWEEN ? AND ?"
+	regexpJunctionWord = regexp.MustCompile(`(?i)\b(AND|OR)\b`)
+
+	// regexpPredicateStmt matches the simple statements comparing a single column, e.g. "name = ?", "rank IN (?)", "type IS NULL", "active IS TRUE"
+	// regexpPredicateStmt 匹配对单个列进行比较的简单语句，例如 "name = ?"、"rank IN (?)"、"type IS NULL"、"active IS TRUE"
+	regexpPredicateStmt = regexp.MustCompile("(?i)^\\s*([\\w.`\"]+)\\s*(=|!=|<>|>=|<=|>|<|(?:NOT\\s+)?IN\\b|(?:NOT\\s+)?LIKE\\b|IS\\s+(?:NOT\\s+)?NULL|IS\\s+(?:TRUE|FALSE)\\b|(?:NOT\\s+)?BETWEEN\\b)\\s*(\\(\\s*\\?\\s*\\)|\\?\\s+AND\\s+\\?|\\?)?\\s*$")
+)
+
+// inferPredicate describes the statement as a predicate when it compares a single column with the arguments
+// Returns nil when the statement is not a simple comparison, e.g. "a = ? OR b = ?", "LOWER(name) = ?"
+//
+// inferPredicate 当语句将单个列与参数比较时，将其描述为谓词
+// 当语句不是简单比较时返回 nil，例如 "a = ? OR b = ?"、"LOWER(name) = ?"
+func inferPredicate(stmt string, args []interface{}) *QxPredicate {
+	matches := regexpPredicateStmt.FindStringSubmatch(stmt)
+	if matches == nil {
+		return nil
+	}
+	var op = strings.ToUpper(strings.Join(strings.Fields(matches[2]), " "))
+	var placeholders = strings.Count(matches[3], "?")
+	switch {
+	case strings.HasPrefix(op, "IS "):
+		if placeholders != 0 {
+			return nil
+		}
+	case strings.HasSuffix(op, "BETWEEN"):
+		if placeholders != 2 {
+			return nil
+		}
+	default:
+		if placeholders != 1 {
+			return nil
+		}
+	}
+	if len(args) != placeholders {
+		return nil
+	}
+	return &QxPredicate{Column: strings.Trim(matches[1], "`\""), Op: op, Values: args}
+}
@@ -0,0 +1,142 @@
+// Package gormcnm tests validate the condition tree behind QxConjunction
+// Auto verifies flattening of AND/OR chains, minimal parentheses and walking of the tree
+// Tests examine the rendered statements, the predicates and SQLite execution
+//
+// gormcnm 测试包验证 QxConjunction 背后的条件树
+// 自动验证 AND/OR 链的展平、最少的括号以及条件树的遍历
+// 测试涵盖渲染出的语句、谓词以及 SQLite 执行
+package gormcnm
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/yyle88/gormcnm/internal/tests"
+	"gorm.io/gorm"
+)
+
+func TestQxConjunction_Node(t *testing.T) {
+	const (
+		columnName = ColumnName[string]("name")
+		columnType = ColumnName[string]("type")
+		columnRank = ColumnName[int]("rank")
+	)
+
+	t.Run("flatten", func(t *testing.T) {
+		qx := Qx(columnName.Eq("abc"))
+		for idx := 0; idx < 3; idx++ {
+			qx = qx.AND(Qx(columnRank.Gt(idx)))
+		}
+		require.Equal(t, "name=? AND rank>? AND rank>? AND rank>?", qx.Qs())
+		require.Equal(t, []interface{}{"abc", 0, 1, 2}, qx.Args())
+		require.Equal(t, QxKindAND, qx.Node().Kind)
+		require.Len(t, qx.Node().Children, 4)
+	})
+
+	t.Run("mixed", func(t *testing.T) {
+		qx := Qx(columnName.Eq("abc")).OR(Qx(columnType.Eq("xyz")).AND(Qx(columnRank.Lt(3))), Qx(columnRank.Eq(9)))
+		require.Equal(t, "name=? OR (type=? AND rank<?) OR rank=?", qx.Qs())
+		require.Equal(t, []interface{}{"abc", "xyz", 3, 9}, qx.Args())
+	})
+
+	t.Run("raw-statement", func(t *testing.T) {
+		qx := Qx(columnName.BetweenAND("aba", "abd")).AND(Qx("type = ? or type = ?", "x", "y"), Qx(columnRank.IsNotNULL()))
+		require.Equal(t, "(name BETWEEN ? AND ?) AND (type = ? or type = ?) AND rank IS NOT NULL", qx.Qs())
+		require.Equal(t, []interface{}{"aba", "abd", "x", "y"}, qx.Args())
+	})
+
+	t.Run("not", func(t *testing.T) {
+		qx := Qx(columnName.Eq("abc")).OR(Qx(columnType.Eq("xyz"))).NOT()
+		require.Equal(t, "NOT (name=? OR type=?)", qx.Qs())
+		require.Equal(t, "NOT (name=? OR type=?) AND rank=?", qx.AND(Qx(columnRank.Eq(1))).Qs())
+		require.Equal(t, "name=? OR type=?", qx.NOT().Qs())
+	})
+
+	t.Run("dialect", func(t *testing.T) {
+		qx := Qx(columnType.Eq("xyz")).AND(Qx(columnName.Eq("abc")))
+		require.Equal(t, "type=? AND name=?", qx.Qs())
+		stmt, args := qx.Node().RenderIn(DialectSQLServer)
+		require.Equal(t, "[type]=? AND [name]=?", stmt)
+		require.Equal(t, []interface{}{"xyz", "abc"}, args)
+
+		qx = NewDialectQx(func(dialect Dialect) (string, []interface{}) {
+			return dialect.Quote("type") + " = ?", []interface{}{"xyz"}
+		}).AND(Qx(columnName.Eq("abc")))
+		require.Equal(t, "(?) AND name=?", qx.Qs())
+	})
+}
+
+func TestQxConjunction_Columns(t *testing.T) {
+	const (
+		columnName = ColumnName[string]("name")
+		columnType = ColumnName[string]("type")
+		columnRank = ColumnName[int]("rank")
+	)
+
+	qx := Qx(columnName.Eq("abc")).
+		OR(Qx(columnType.Eq("xyz")).AND(NewStringColumn(columnName).Contains("b"))).
+		AND(Qx(columnRank.In([]int{1, 2})).NOT(), Qx("LOWER(type) = ?", "x"))
+	require.Equal(t, []string{"name", "type", "rank"}, qx.Columns())
+
+	var predicates []*QxPredicate
+	qx.Walk(func(node *QxNode) bool {
+		if node.Predicate != nil {
+			predicates = append(predicates, node.Predicate)
+		}
+		return node.Kind != QxKindNOT
+	})
+	require.Len(t, predicates, 3) // The predicate under NOT is skipped
+	require.Equal(t, &QxPredicate{Column: "name", Op: "=", Values: []interface{}{"abc"}}, predicates[0])
+	require.Equal(t, &QxPredicate{Column: "type", Op: "=", Values: []interface{}{"xyz"}}, predicates[1])
+	require.Equal(t, &QxPredicate{Column: "name", Op: "CONTAINS", Values: []interface{}{"b"}}, predicates[2])
+}
+
+func TestInferPredicate(t *testing.T) {
+	require.Equal(t, &QxPredicate{Column: "name", Op: "=", Values: []interface{}{"abc"}}, inferPredicate("name=?", []interface{}{"abc"}))
+	require.Equal(t, &QxPredicate{Column: "rank", Op: "NOT IN", Values: []interface{}{[]int{1}}}, inferPredicate("rank not  in (?)", []interface{}{[]int{1}}))
+	require.Equal(t, &QxPredicate{Column: "type", Op: "IS NULL"}, inferPredicate("`type` IS NULL", nil))
+	require.Equal(t, &QxPredicate{Column: "rank", Op: "BETWEEN", Values: []interface{}{1, 2}}, inferPredicate("rank BETWEEN ? AND ?", []interface{}{1, 2}))
+	require.Nil(t, inferPredicate("name=? OR type=?", []interface{}{"a", "b"}))
+	require.Nil(t, inferPredicate("LOWER(name)=?", []interface{}{"a"}))
+	require.Nil(t, inferPredicate("name=?", nil))
+}
+
+func TestQxConjunction_Node_Execute(t *testing.T) {
+	type Example struct {
+		Name string `gorm:"primary_key;type:varchar(100);"`
+		Type string `gorm:"column:type;"`
+		Rank int    `gorm:"column:rank;"`
+	}
+
+	const (
+		columnName = ColumnName[string]("name")
+		columnType = ColumnName[string]("type")
+		columnRank = ColumnName[int]("rank")
+	)
+
+	tests.NewDBRun(t, func(db *gorm.DB) {
+		require.NoError(t, db.AutoMigrate(&Example{}))
+		require.NoError(t, db.Create(&[]*Example{
+			{Name: "abc", Type: "xyz", Rank: 1},
+			{Name: "aaa", Type: "xxx", Rank: 2},
+			{Name: "bbb", Type: "xyz", Rank: 3},
+		}).Error)
+
+		t.Run("or-and", func(t *testing.T) {
+			var res []*Example
+			qx := Qx(columnName.Eq("aaa")).OR(Qx(columnType.Eq("xyz")).AND(Qx(columnRank.Gt(2))))
+			require.NoError(t, db.Where(qx).Where(Qx(columnRank.Lt(10))).Order(columnName.Name()).Find(&res).Error)
+			require.Len(t, res, 2)
+			require.Equal(t, "aaa", res[0].Name)
+			require.Equal(t, "bbb", res[1].Name)
+		})
+
+		t.Run("not-or", func(t *testing.T) {
+			var res []*Example
+			qx := Qx(columnName.Eq("aaa")).OR(Qx(columnName.Eq("bbb"))).NOT().AND(Qx(columnType.Eq("xyz")))
+			require.NoError(t, db.Scopes(qx.Scope()).Find(&res).Error)
+			require.Len(t, res, 1)
+			require.Equal(t, "abc", res[0].Name)
+		})
+	})
+}
@@ -11,6 +11,7 @@ import (
 	"strings"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 // SxType is an alias when using SelectStatement as a short type name
@@ -60,15 +61,26 @@ func NewSelectStatement(stmt string, args ...interface{}) *SelectStatement {
 // Combine 将当前的 SelectStatement 与其他 SelectStatement 合并，通过合并它们的查询字符串和参数。
 func (sx *SelectStatement) Combine(cs ...*SelectStatement) *SelectStatement {
 	var qsVs []string
-	qsVs = append(qsVs, sx.Qs())
-	var args []any
-	args = append(args, sx.Args()...)
+	var merger = newArgumentsMerger() // Renames the colliding named arguments // 重命名冲突的命名参数
+	qsVs = append(qsVs, merger.merge(sx.Qs(), sx.Args()))
 	for _, c := range cs {
-		qsVs = append(qsVs, c.Qs())
-		args = append(args, c.Args()...)
+		qsVs = append(qsVs, merger.merge(c.Qs(), c.Args()))
 	}
-	var stmt = strings.Join(qsVs, ", ")      //得到的就是gorm db.Select() 的要选中的列信息，因此使用逗号分隔
-	return NewSelectStatement(stmt, args...) //得到的就是 gorm db.Select() 的选中信息和附带的参数信息，比如 COUNT(CASE WHEN condition THEN 1 END) 里 condition 的参数信息
+	var stmt = strings.Join(qsVs, ", ")                  //得到的就是gorm db.Select() 的要选中的列信息，因此使用逗号分隔
+	var res = NewSelectStatement(stmt, merger.args()...) //得到的就是 gorm db.Select() 的选中信息和附带的参数信息，比如 COUNT(CASE WHEN condition THEN 1 END) 里 condition 的参数信息
+	res.err = checkTuples(sx.tuples(cs))
+	return res
+}
+
+// tuples returns the statement-arguments tuples of the current instance and the provided instances
+// tuples 返回当前实例与提供的实例的语句-参数元组
+func (sx *SelectStatement) tuples(cs []*SelectStatement) []*statementArgumentsTuple {
+	var tuples = make([]*statementArgumentsTuple, 0, 1+len(cs))
+	tuples = append(tuples, sx.statementArgumentsTuple)
+	for _, c := range cs {
+		tuples = append(tuples, c.statementArgumentsTuple)
+	}
+	return tuples
 }
 
 // Scope converts the SelectStatement to a GORM ScopeFunction used with db.Scopes().
@@ -77,6 +89,34 @@ func (sx *SelectStatement) Combine(cs ...*SelectStatement) *SelectStatement {
 // 它将 SelectStatement 定义的查询语句应用于 GORM 查询。
 func (sx *SelectStatement) Scope() ScopeFunction {
 	return func(db *gorm.DB) *gorm.DB {
+		if err := sx.check(validationModeOf(db.Statement)); err != nil {
+			_ = db.AddError(err)
+			return db
+		}
+		if sx.hasNamed() {
+			return db.Clauses(sx)
+		}
 		return db.Select(sx.Qs(), sx.args...)
 	}
 }
+
+// Build implements clause.Expression, writing the select columns with every argument bound.
+// Build 实现 clause.Expression 接口，写出选中的列并绑定全部参数。
+func (sx *SelectStatement) Build(builder clause.Builder) {
+	sx.checkTo(builder)
+	sx.expression().Build(builder)
+}
+
+// ModifyStatement implements gorm.StatementModifier, so db.Clauses(sx) sets the SELECT clause.
+// GORM's db.Select only accepts strings, thus use db.Clauses(sx) or db.Scopes(sx.Scope()) instead.
+// ModifyStatement 实现 gorm.StatementModifier 接口，使 db.Clauses(sx) 能设置 SELECT 子句。
+// GORM 的 db.Select 只接受字符串，因此请使用 db.Clauses(sx) 或 db.Scopes(sx.Scope()) 代替。
+func (sx *SelectStatement) ModifyStatement(stmt *gorm.Statement) {
+	if err := sx.check(validationModeOf(stmt)); err != nil {
+		_ = stmt.AddError(err)
+	}
+	stmt.AddClause(clause.Select{
+		Distinct:   stmt.Distinct,
+		Expression: sx.expression(),
+	})
+}
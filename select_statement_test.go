@@ -51,3 +51,34 @@ func TestSelectStatement_Combine(t *testing.T) {
 		require.Equal(t, results[1].Mark, 100)
 	})
 }
+
+func TestSelectStatement_Build(t *testing.T) {
+	type Example struct {
+		Name string `gorm:"primary_key;type:varchar(100);"`
+		Rank int    `gorm:"column:rank;"`
+	}
+
+	const (
+		columnName = ColumnName[string]("name")
+		columnRank = ColumnName[int]("rank")
+	)
+
+	tests.NewDBRun(t, func(db *gorm.DB) {
+		require.NoError(t, db.AutoMigrate(&Example{}))
+		require.NoError(t, db.Save(&Example{Name: "abc", Rank: 100}).Error)
+		require.NoError(t, db.Save(&Example{Name: "aaa", Rank: 101}).Error)
+		require.NoError(t, db.Save(&Example{Name: "bbb", Rank: 102}).Error)
+
+		type Result struct {
+			Cnt int64
+		}
+
+		operation := &ColumnOperationClass{}
+		sx := operation.CountCaseWhenQxSx(Qx(columnName.In([]string{"abc", "aaa"})).AND(Qx(columnRank.Gt(100))), "cnt")
+
+		var result Result
+		require.NoError(t, db.Model(&Example{}).Clauses(sx).Take(&result).Error)
+		t.Log(neatjsons.S(result))
+		require.Equal(t, int64(1), result.Cnt)
+	})
+}
@@ -0,0 +1,167 @@
+// Package gormcnm provides the registry of the columns allowed in sort parameters coming from outside, e.g. "?sort=-created_at,name"
+// Auto resolves the public field names into the typed columns, with the direction prefixes and the NULLS options
+// Supports limiting the count of the sort keys and appending the mandatory tiebreaker, giving a validated Ordering
+//
+// gormcnm 提供外部传入排序参数（例如 "?sort=-created_at,name"）所允许的列的注册表
+// 自动将公开的字段名解析为类型化的列，支持方向前缀和 NULLS 选项
+// 支持限制排序键的数量并追加必需的决胜列，得到经过校验的 Ordering
+package gormcnm
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/yyle88/must"
+)
+
+var (
+	// ErrSortField means the sort parameter uses a field missing in the registry, or the same field twice
+	// ErrSortField 表示排序参数使用了注册表中不存在的字段，或重复使用同一字段
+	ErrSortField = errors.New("invalid sort field")
+	// ErrSortSyntax means the sort parameter is malformed, e.g. an empty key or an unknown option
+	// ErrSortSyntax 表示排序参数格式错误，例如空的键或未知的选项
+	ErrSortSyntax = errors.New("invalid sort syntax")
+	// ErrSortTooMany means the sort parameter has more keys than allowed
+	// ErrSortTooMany 表示排序参数的键超过了允许的数量
+	ErrSortTooMany = errors.New("too many sort keys")
+)
+
+// defaultMaxSortKeys is the default limit of the sort keys of NewSortRegistry
+// defaultMaxSortKeys 是 NewSortRegistry 默认的排序键数量上限
+const defaultMaxSortKeys = 3
+
+// SortRegistry maps the public field names of the sort parameters to the columns
+// SortRegistry 将排序参数中公开的字段名映射到列
+type SortRegistry struct {
+	fields     map[string]string // Field name to column name // 字段名到列名的映射
+	maxKeys    int               // Limit of the sort keys, the tiebreaker excluded // 排序键数量上限，不含决胜列
+	tiebreaker *OrderItem        // Item appended when the tiebreaker is missing // 缺少决胜列时追加的项
+}
+
+// NewSortRegistry creates an empty SortRegistry allowing at most 3 sort keys
+// NewSortRegistry 创建一个空的 SortRegistry，最多允许 3 个排序键
+func NewSortRegistry() *SortRegistry {
+	return &SortRegistry{fields: map[string]string{}, maxKeys: defaultMaxSortKeys}
+}
+
+// RegisterSortColumn registers the column under its own name
+// RegisterSortColumn 以列自身的名称注册该列
+func RegisterSortColumn[TYPE any](registry *SortRegistry, column ColumnName[TYPE]) {
+	RegisterSortField(registry, column.Name(), column)
+}
+
+// RegisterSortField registers the column under the public field name, e.g. RegisterSortField(registry, "created", columnCreatedAt)
+// RegisterSortField 以公开的字段名注册该列，例如 RegisterSortField(registry, "created", columnCreatedAt)
+func RegisterSortField[TYPE any](registry *SortRegistry, field string, column ColumnName[TYPE]) {
+	must.Nice(field)
+	must.True(!strings.ContainsAny(field, ",:+- \t"))
+	registry.fields[field] = must.Nice(column.Name())
+}
+
+// SetMaxKeys sets the limit of the sort keys given in the parameter, the tiebreaker excluded
+// SetMaxKeys 设置参数中排序键的数量上限，不含决胜列
+func (registry *SortRegistry) SetMaxKeys(maxKeys int) *SortRegistry {
+	must.True(maxKeys > 0)
+	registry.maxKeys = maxKeys
+	return registry
+}
+
+// SetTiebreaker sets the registered field (e.g. the primary key) closing every parsed Ordering, appended with the direction when missing
+// SetTiebreaker 设置结束每个解析结果的已注册字段（例如主键），缺少时按给定方向追加
+func (registry *SortRegistry) SetTiebreaker(field string, direction OrderDirection) *SortRegistry {
+	column, ok := registry.fields[field]
+	must.True(ok)
+	must.In(direction, []OrderDirection{OrderAsc, OrderDesc})
+	registry.tiebreaker = &OrderItem{Column: column, Direction: direction}
+	return registry
+}
+
+// Fields returns the registered field names, sorted
+// Fields 返回已注册的字段名，已排序
+func (registry *SortRegistry) Fields() []string {
+	var names = make([]string, 0, len(registry.fields))
+	for name := range registry.fields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Parse parses the sort parameter into the Ordering, e.g. "-created_at,name:nulls_last".
+// Each key is a registered field, ascending by default or with the "+" prefix, descending with the "-" prefix,
+// optionally followed by ":nulls_first" or ":nulls_last". The blank parameter gives the Ordering of the tiebreaker alone.
+// The tiebreaker is appended when the parameter misses it, the keys after the tiebreaker never change the order.
+//
+// Parse 将排序参数解析为 Ordering，例如 "-created_at,name:nulls_last"。
+// 每个键都是已注册的字段，默认升序或使用 "+" 前缀表示升序，使用 "-" 前缀表示降序，
+// 可选地后跟 ":nulls_first" 或 ":nulls_last"。空白参数只得到决胜列的 Ordering。
+// 参数缺少决胜列时会追加它，决胜列之后的键不会改变顺序。
+func (registry *SortRegistry) Parse(text string) (*Ordering, error) {
+	var items []OrderItem
+	if strings.TrimSpace(text) != "" {
+		var keys = strings.Split(text, ",")
+		if len(keys) > registry.maxKeys {
+			return nil, errors.WithMessagef(ErrSortTooMany, "%d keys, at most %d", len(keys), registry.maxKeys)
+		}
+		var seen = make(map[string]bool, len(keys))
+		for idx, key := range keys {
+			item, field, err := registry.parseKey(strings.TrimSpace(key))
+			if err != nil {
+				return nil, errors.WithMessagef(err, "sort key %d", idx+1)
+			}
+			if seen[field] {
+				return nil, errors.WithMessagef(ErrSortField, "sort key %d: field %q repeated", idx+1, field)
+			}
+			seen[field] = true
+			items = append(items, item)
+		}
+	}
+	if registry.tiebreaker != nil {
+		var found bool
+		for _, item := range items {
+			if item.Column == registry.tiebreaker.Column {
+				found = true
+				break
+			}
+		}
+		if !found {
+			items = append(items, *registry.tiebreaker)
+		}
+	}
+	return NewOrdering(items...), nil
+}
+
+// parseKey parses one sort key, returning the item and the field name
+// parseKey 解析一个排序键，返回排序项和字段名
+func (registry *SortRegistry) parseKey(key string) (OrderItem, string, error) {
+	var field, option, _ = strings.Cut(key, ":")
+	var direction = OrderAsc
+	if strings.HasPrefix(field, "-") {
+		direction = OrderDesc
+		field = field[1:]
+	} else if strings.HasPrefix(field, "+") {
+		field = field[1:]
+	}
+	if field == "" {
+		return OrderItem{}, "", errors.WithMessagef(ErrSortSyntax, "key %q misses the field", key)
+	}
+	column, ok := registry.fields[field]
+	if !ok {
+		return OrderItem{}, "", errors.WithMessagef(ErrSortField, "field %q unknown, allowed fields: %s", field, strings.Join(registry.Fields(), ", "))
+	}
+	var item = OrderItem{Column: column, Direction: direction}
+	switch strings.ToLower(option) {
+	case "":
+		if strings.HasSuffix(key, ":") {
+			return OrderItem{}, "", errors.WithMessagef(ErrSortSyntax, "key %q misses the option", key)
+		}
+	case "nulls_first":
+		item.Nulls = NullsFirst
+	case "nulls_last":
+		item.Nulls = NullsLast
+	default:
+		return OrderItem{}, "", errors.WithMessagef(ErrSortSyntax, "option %q unknown, allowed options: nulls_first, nulls_last", option)
+	}
+	return item, field, nil
+}
@@ -0,0 +1,115 @@
+// Package gormcnm tests validate the sort parameters resolved through the registry
+// Auto verifies the direction prefixes, the NULLS options and the appended tiebreaker
+// Tests examine SQLite execution of the parsed orderings and the descriptive errors
+//
+// gormcnm 测试包验证通过注册表解析的排序参数
+// 自动验证方向前缀、NULLS 选项以及追加的决胜列
+// 测试涵盖解析后排序的 SQLite 执行以及描述性的错误
+package gormcnm
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/yyle88/gormcnm/internal/tests"
+	"gorm.io/gorm"
+)
+
+func TestSortRegistry_Parse(t *testing.T) {
+	type Example struct {
+		ID        uint       `gorm:"primaryKey"`
+		Name      string     `gorm:"column:name;"`
+		CreatedAt *time.Time `gorm:"column:created_at;"`
+	}
+
+	const (
+		columnID        = ColumnName[uint]("id")
+		columnName      = ColumnName[string]("name")
+		columnCreatedAt = ColumnName[*time.Time]("created_at")
+	)
+
+	registry := NewSortRegistry()
+	RegisterSortColumn(registry, columnID)
+	RegisterSortColumn(registry, columnName)
+	RegisterSortField(registry, "created", columnCreatedAt)
+	registry.SetTiebreaker("id", OrderAsc)
+
+	ordering, err := registry.Parse("-created,name")
+	require.NoError(t, err)
+	require.Equal(t, "created_at DESC, name ASC, id ASC", string(ordering.Ob()))
+
+	ordering, err = registry.Parse(" +name , -created:NULLS_LAST ")
+	require.NoError(t, err)
+	require.Equal(t, "name ASC, created_at DESC NULLS LAST, id ASC", ordering.Render(""))
+
+	ordering, err = registry.Parse("-id,name")
+	require.NoError(t, err)
+	require.Equal(t, "id DESC, name ASC", string(ordering.Ob()))
+
+	ordering, err = registry.Parse("")
+	require.NoError(t, err)
+	require.Equal(t, "id ASC", string(ordering.Ob()))
+
+	tests.NewDBRun(t, func(db *gorm.DB) {
+		require.NoError(t, db.AutoMigrate(&Example{}))
+		moment := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+		require.NoError(t, db.Create(&[]*Example{
+			{ID: 1, Name: "b", CreatedAt: &moment},
+			{ID: 2, Name: "a", CreatedAt: nil},
+			{ID: 3, Name: "a", CreatedAt: &moment},
+		}).Error)
+
+		selectIDs := func(text string) []uint {
+			ordering, err := registry.Parse(text)
+			require.NoError(t, err)
+			var ids []uint
+			require.NoError(t, db.Model(&Example{}).Scopes(ordering.Scope()).Pluck(columnID.Name(), &ids).Error)
+			return ids
+		}
+
+		require.Equal(t, []uint{2, 3, 1}, selectIDs("name"))
+		require.Equal(t, []uint{1, 3, 2}, selectIDs("-name,-id"))
+		require.Equal(t, []uint{1, 3, 2}, selectIDs("created:nulls_last"))
+		require.Equal(t, []uint{2, 1, 3}, selectIDs("-created:nulls_first"))
+	})
+}
+
+func TestSortRegistry_Parse_Errors(t *testing.T) {
+	const (
+		columnID   = ColumnName[uint]("id")
+		columnName = ColumnName[string]("name")
+		columnRank = ColumnName[int]("rank")
+	)
+
+	registry := NewSortRegistry().SetMaxKeys(2)
+	RegisterSortColumn(registry, columnID)
+	RegisterSortColumn(registry, columnName)
+	RegisterSortColumn(registry, columnRank)
+
+	for text, expected := range map[string]error{
+		"name,secret":        ErrSortField,
+		"name,-name":         ErrSortField,
+		"name;DROP TABLE":    ErrSortField,
+		"name,,rank":         ErrSortTooMany,
+		"name,rank,id":       ErrSortTooMany,
+		"name,":              ErrSortSyntax,
+		"-":                  ErrSortSyntax,
+		"name:":              ErrSortSyntax,
+		"name:nulls_between": ErrSortSyntax,
+	} {
+		_, err := registry.Parse(text)
+		require.ErrorIs(t, err, expected, text)
+	}
+
+	_, err := registry.Parse("name,secret")
+	require.EqualError(t, err, `sort key 2: field "secret" unknown, allowed fields: id, name, rank: invalid sort field`)
+
+	ordering, err := registry.Parse("-rank")
+	require.NoError(t, err)
+	require.Equal(t, "rank DESC", string(ordering.Ob())) // No tiebreaker set
+
+	require.Panics(t, func() {
+		registry.SetTiebreaker("secret", OrderAsc)
+	})
+}
@@ -0,0 +1,267 @@
+// Package gormcnm provides the consistency check between the placeholders and the arguments of a statement
+// Auto counts the "?" and "@name" placeholders, ignoring the ones inside quoted literals and identifiers
+// Supports a strict mode panicking on mismatch (e.g. in tests) and a lenient mode reporting the error to GORM
+// The check is opt-in, disabled unless the mode is set in the process or on the DB
+//
+// gormcnm 提供语句占位符与参数的一致性检查
+// 自动统计 "?" 和 "@name" 占位符，忽略引号内的字面量和标识符中的占位符
+// 支持在不一致时 panic 的严格模式（例如在测试中）以及将错误报告给 GORM 的宽松模式
+// 检查需要主动开启，除非在进程或 DB 上设置了模式，否则不会检查
+package gormcnm
+
+import (
+	"database/sql"
+	"sync/atomic"
+
+	"github.com/pkg/errors"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ErrPlaceholderMismatch means the placeholders of a statement do not match its arguments
+// ErrPlaceholderMismatch 表示语句的占位符与其参数不一致
+var ErrPlaceholderMismatch = errors.New("placeholders and arguments mismatch")
+
+// ValidationMode represents how the placeholders and arguments mismatch is handled
+// ValidationMode 表示如何处理占位符与参数不一致的情况
+type ValidationMode int32
+
+const (
+	// ValidationDisabled skips the check, the default mode
+	// ValidationDisabled 跳过检查，默认模式
+	ValidationDisabled ValidationMode = iota
+	// ValidationLenient reports the mismatch as the error of the GORM statement
+	// ValidationLenient 将不一致作为 GORM 语句的错误报告
+	ValidationLenient
+	// ValidationStrict panics on the mismatch, designed to catch the mistakes in tests
+	// ValidationStrict 在不一致时 panic，用于在测试中尽早发现错误
+	ValidationStrict
+)
+
+// validationMode holds the ValidationMode of the process, used on the DBs without their own mode
+// validationMode 保存进程的 ValidationMode，用于没有设置自身模式的 DB
+var validationMode atomic.Int32
+
+// validationModeKey is the setting key of the ValidationMode of a DB, see WithValidationMode
+// validationModeKey 是 DB 的 ValidationMode 的设置键，见 WithValidationMode
+const validationModeKey = "gormcnm:validation_mode"
+
+// SetValidationMode sets the ValidationMode of the process and returns the previous one, e.g. in tests:
+//
+//	defer gormcnm.SetValidationMode(gormcnm.SetValidationMode(gormcnm.ValidationStrict))
+//
+// SetValidationMode 设置 ValidationMode 并返回之前的模式，例如在测试中：
+//
+//	defer gormcnm.SetValidationMode(gormcnm.SetValidationMode(gormcnm.ValidationStrict))
+func SetValidationMode(mode ValidationMode) ValidationMode {
+	return ValidationMode(validationMode.Swap(int32(mode)))
+}
+
+// GetValidationMode returns the ValidationMode of the process
+// GetValidationMode 返回进程的 ValidationMode
+func GetValidationMode() ValidationMode {
+	return ValidationMode(validationMode.Load())
+}
+
+// WithValidationMode returns a new session of the DB checking the statements in the mode, overriding the mode of the process
+// e.g. db = gormcnm.WithValidationMode(db, gormcnm.ValidationLenient) reports the mismatches as the errors of the queries on db
+//
+// WithValidationMode 返回按该模式检查语句的 DB 新会话，覆盖进程的模式
+// 例如 db = gormcnm.WithValidationMode(db, gormcnm.ValidationLenient) 将不一致作为 db 上查询的错误报告
+func WithValidationMode(db *gorm.DB, mode ValidationMode) *gorm.DB {
+	return db.Set(validationModeKey, mode).Session(&gorm.Session{})
+}
+
+// validationModeOf returns the ValidationMode set on the GORM statement, or the one of the process when unset
+// validationModeOf 返回 GORM 语句上设置的 ValidationMode，未设置时返回进程的模式
+func validationModeOf(stmt *gorm.Statement) ValidationMode {
+	if stmt != nil {
+		if value, ok := stmt.Settings.Load(validationModeKey); ok {
+			if mode, ok := value.(ValidationMode); ok {
+				return mode
+			}
+		}
+	}
+	return GetValidationMode()
+}
+
+// CountPlaceholders returns the count of the "?" placeholders and the names of the "@name" placeholders in the statement.
+// Placeholders inside quoted literals and identifiers ('...', "...", `...`) and MySQL "@@" variables are ignored.
+//
+// CountPlaceholders 返回语句中 "?" 占位符的数量以及 "@name" 占位符的名称。
+// 引号内的字面量和标识符（'...'、"..."、`...`）以及 MySQL 的 "@@" 变量中的占位符会被忽略。
+func CountPlaceholders(stmt string) (int, []string) {
+	var count int
+	var names []string
+	walkPlaceholders(stmt, func(start, end int) {
+		if stmt[start] == '?' {
+			count++
+		} else {
+			names = append(names, stmt[start+1:end])
+		}
+	})
+	return count, names
+}
+
+// walkPlaceholders calls fn with the range of each placeholder, stmt[start:end] being "?" or "@name"
+// walkPlaceholders 使用每个占位符的范围调用 fn，stmt[start:end] 为 "?" 或 "@name"
+func walkPlaceholders(stmt string, fn func(start, end int)) {
+	for idx := 0; idx < len(stmt); idx++ {
+		switch c := stmt[idx]; c {
+		case '\'', '"', '`':
+			idx = skipQuoted(stmt, idx)
+		case '?':
+			fn(idx, idx+1)
+		case '@':
+			if idx+1 < len(stmt) && stmt[idx+1] == '@' {
+				idx = skipNameRunes(stmt, idx+2) - 1
+				continue
+			}
+			end := skipNameRunes(stmt, idx+1)
+			if end > idx+1 && (idx == 0 || !isNameRune(stmt[idx-1])) {
+				fn(idx, end)
+			}
+			idx = end - 1
+		}
+	}
+}
+
+// skipQuoted returns the index of the closing quote of the quoted part starting at idx, doubled quotes are escaped quotes
+// Inside the '...' and "..." literals a backslash escapes the next character too, e.g. 'it\'s ?' in MySQL
+//
+// skipQuoted 返回从 idx 开始的引号部分的闭合引号下标，连续两个引号表示转义的引号
+// 在 '...' 和 "..." 字面量中反斜杠也会转义下一个字符，例如 MySQL 中的 'it\'s ?'
+func skipQuoted(stmt string, idx int) int {
+	quote := stmt[idx]
+	for idx++; idx < len(stmt); idx++ {
+		if stmt[idx] == '\\' && quote != '`' {
+			idx++
+			continue
+		}
+		if stmt[idx] == quote {
+			if idx+1 < len(stmt) && stmt[idx+1] == quote {
+				idx++
+				continue
+			}
+			return idx
+		}
+	}
+	return idx
+}
+
+// skipNameRunes returns the index after the name characters starting at idx
+// skipNameRunes 返回从 idx 开始的名称字符之后的下标
+func skipNameRunes(stmt string, idx int) int {
+	for idx < len(stmt) && isNameRune(stmt[idx]) {
+		idx++
+	}
+	return idx
+}
+
+// isNameRune tells whether c can be part of a named placeholder
+// isNameRune 判断 c 是否可以作为命名占位符的一部分
+func isNameRune(c byte) bool {
+	return c == '_' || ('a' <= c && c <= 'z') || ('A' <= c && c <= 'Z') || ('0' <= c && c <= '9')
+}
+
+// ValidateStatement checks that the "?" placeholders match the positional arguments,
+// and that each "@name" placeholder is given by a sql.NamedArg or a map[string]interface{} argument.
+// Without named arguments the "@name" parts are not placeholders, GORM keeps them as is, e.g. the MySQL @var.
+//
+// ValidateStatement 检查 "?" 占位符与位置参数数量一致，
+// 并且每个 "@name" 占位符都由 sql.NamedArg 或 map[string]interface{} 参数提供。
+// 没有命名参数时 "@name" 部分不是占位符，GORM 会原样保留，例如 MySQL 的 @var。
+func ValidateStatement(stmt string, args []interface{}) error {
+	count, names := CountPlaceholders(stmt)
+	var positional int
+	var namedArgs = map[string]bool{}
+	for _, arg := range args {
+		switch value := arg.(type) {
+		case sql.NamedArg:
+			namedArgs[value.Name] = true
+		case map[string]interface{}:
+			for name := range value {
+				namedArgs[name] = true
+			}
+		default:
+			positional++
+		}
+	}
+	if count != positional {
+		return errors.WithMessagef(ErrPlaceholderMismatch, "%d placeholders but %d arguments in %q", count, positional, stmt)
+	}
+	if len(namedArgs) == 0 {
+		return nil
+	}
+	for _, name := range names {
+		if !namedArgs[name] {
+			return errors.WithMessagef(ErrPlaceholderMismatch, "missing argument @%s in %q", name, stmt)
+		}
+	}
+	return nil
+}
+
+// Validate checks the placeholders and the arguments, including the ones of the combined parts.
+// Returns nil when the ValidationMode of the process is ValidationDisabled.
+//
+// Validate 检查占位符与参数，包括被组合的各部分。
+// 当进程的 ValidationMode 为 ValidationDisabled 时返回 nil。
+func (qx *statementArgumentsTuple) Validate() error {
+	if GetValidationMode() == ValidationDisabled {
+		return nil
+	}
+	return qx.validate()
+}
+
+// validate checks the placeholders and the arguments whatever the ValidationMode
+// validate 检查占位符与参数，不考虑 ValidationMode
+func (qx *statementArgumentsTuple) validate() error {
+	if qx.err != nil {
+		return qx.err
+	}
+	return ValidateStatement(qx.Qs(), qx.Args())
+}
+
+// check validates the tuple in the mode, panicking in ValidationStrict mode and returning the error in ValidationLenient mode
+// check 按模式校验元组，在 ValidationStrict 模式下 panic，在 ValidationLenient 模式下返回错误
+func (qx *statementArgumentsTuple) check(mode ValidationMode) error {
+	if mode == ValidationDisabled {
+		return nil
+	}
+	err := qx.validate()
+	if err != nil && mode == ValidationStrict {
+		panic(err)
+	}
+	return err
+}
+
+// checkTo validates the tuple in the mode of the GORM statement behind the builder, reporting the error to it
+// checkTo 按构建器背后的 GORM 语句的模式校验元组，并将错误报告给该语句
+func (qx *statementArgumentsTuple) checkTo(builder clause.Builder) {
+	stmt, _ := builder.(*gorm.Statement)
+	if err := qx.check(validationModeOf(stmt)); err != nil && stmt != nil {
+		_ = stmt.AddError(err)
+	}
+}
+
+// checkTuples validates the tuples being combined, returning the first error to keep in the combined tuple
+// The error is kept in the enabled modes, since the DB running the combined tuple may set its own, see WithValidationMode
+// In ValidationStrict mode of the process it panics at once, in ValidationDisabled mode it checks nothing
+//
+// checkTuples 校验被组合的元组，返回第一个错误以保存在组合后的元组中
+// 在启用校验的模式下都会保存错误，因为执行组合元组的 DB 可能设置了自身的模式，见 WithValidationMode
+// 在进程的 ValidationStrict 模式下会立即 panic，在 ValidationDisabled 模式下不做任何校验
+func checkTuples(tuples []*statementArgumentsTuple) error {
+	if GetValidationMode() == ValidationDisabled {
+		return nil
+	}
+	for _, tuple := range tuples {
+		if err := tuple.validate(); err != nil {
+			if GetValidationMode() == ValidationStrict {
+				panic(err)
+			}
+			return err
+		}
+	}
+	return nil
+}
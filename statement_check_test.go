@@ -0,0 +1,136 @@
+// Package gormcnm tests validate the consistency check between placeholders and arguments
+// Auto verifies the counting of "?" and "@name" placeholders with quoted literals ignored
+// Tests examine the strict mode panicking and the lenient mode reporting the error to GORM
+//
+// gormcnm 测试包验证占位符与参数的一致性检查
+// 自动验证忽略引号字面量后 "?" 和 "@name" 占位符的统计
+// 测试涵盖 panic 的严格模式以及将错误报告给 GORM 的宽松模式
+package gormcnm
+
+import (
+	"database/sql"
+	"os"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+	"github.com/yyle88/gormcnm/internal/tests"
+	"gorm.io/gorm"
+)
+
+func TestMain(m *testing.M) {
+	SetValidationMode(ValidationStrict)
+	os.Exit(m.Run())
+}
+
+func TestCountPlaceholders(t *testing.T) {
+	count, names := CountPlaceholders("name = ? AND remark = 'why?' AND `a?b` = ? AND \"c?\" IS NULL")
+	require.Equal(t, 2, count)
+	require.Empty(t, names)
+
+	count, names = CountPlaceholders("name = @name AND type IN @types AND remark = 'it''s @me ?' AND @@session.sql_mode = ? AND email <> 'a@b'")
+	require.Equal(t, 1, count)
+	require.Equal(t, []string{"name", "types"}, names)
+
+	count, names = CountPlaceholders(`remark = 'it\'s @me ?' AND path = 'C:\\' AND name = ?`)
+	require.Equal(t, 1, count)
+	require.Empty(t, names)
+}
+
+func TestValidateStatement(t *testing.T) {
+	require.NoError(t, ValidateStatement("name = ? AND type = ?", []interface{}{"abc", "xyz"}))
+	require.NoError(t, ValidateStatement("name = @name", []interface{}{sql.Named("name", "abc")}))
+	require.NoError(t, ValidateStatement("name = @name AND rank > ?", []interface{}{map[string]interface{}{"name": "abc"}, 1}))
+	require.NoError(t, ValidateStatement("rank > @min_rank AND type = ?", []interface{}{"xyz"})) // MySQL @var
+
+	require.ErrorIs(t, ValidateStatement("name = ?", nil), ErrPlaceholderMismatch)
+	require.ErrorIs(t, ValidateStatement("name IS NULL", []interface{}{"abc"}), ErrPlaceholderMismatch)
+	require.ErrorIs(t, ValidateStatement("name = @name", []interface{}{sql.Named("type", "xyz")}), ErrPlaceholderMismatch)
+}
+
+func TestQxConjunction_Validate(t *testing.T) {
+	type Example struct {
+		Name string `gorm:"primary_key;type:varchar(100);"`
+		Type string `gorm:"column:type;"`
+	}
+
+	const (
+		columnName = ColumnName[string]("name")
+		columnType = ColumnName[string]("type")
+	)
+
+	t.Run("strict", func(t *testing.T) {
+		require.Panics(t, func() {
+			Qx(columnName.Eq("abc")).AND(Qx("type = ? OR type = ?", "xyz"))
+		})
+		require.Panics(t, func() {
+			NewSx("COUNT(*) as cnt").Combine(NewSx("SUM(rank) as total", 1))
+		})
+		require.NotPanics(t, func() {
+			Qx(columnName.Eq("abc")).AND(Qx("remark = 'why?'"), Qx(columnType.Eq("xyz")))
+		})
+	})
+
+	t.Run("lenient", func(t *testing.T) {
+		defer SetValidationMode(SetValidationMode(ValidationLenient))
+
+		// The mismatches cancel each other out in the combined statement, the error of the parts is kept
+		qx := Qx("name = ? AND type = ?", "abc").AND(Qx("rank = 1", 2))
+		require.Equal(t, 2, len(qx.Args()))
+		require.ErrorIs(t, qx.Validate(), ErrPlaceholderMismatch)
+
+		tests.NewDBRun(t, func(db *gorm.DB) {
+			require.NoError(t, db.AutoMigrate(&Example{}))
+
+			err := db.Scopes(Qx(columnName.Qs("= ?")).Scope()).Find(&[]*Example{}).Error
+			require.True(t, errors.Is(err, ErrPlaceholderMismatch))
+
+			err = db.Where(Qx(columnName.Qs("= ?"))).Find(&[]*Example{}).Error
+			require.True(t, errors.Is(err, ErrPlaceholderMismatch))
+
+			err = db.Model(&Example{}).Clauses(NewSx("COUNT(*) as cnt", 1)).Find(&[]map[string]interface{}{}).Error
+			require.True(t, errors.Is(err, ErrPlaceholderMismatch))
+
+			require.NoError(t, db.Where(Qx(columnName.Eq("abc")).OR(Qx(columnType.Eq("xyz")))).Find(&[]*Example{}).Error)
+		})
+	})
+
+	t.Run("per-db", func(t *testing.T) {
+		defer SetValidationMode(SetValidationMode(ValidationDisabled))
+
+		tests.NewDBRun(t, func(db *gorm.DB) {
+			require.NoError(t, db.AutoMigrate(&Example{}))
+
+			require.NoError(t, db.Where(Qx(columnName.Qs("= ?"), "abc", "xyz")).Find(&[]*Example{}).Error)
+
+			lenientDB := WithValidationMode(db, ValidationLenient)
+			err := lenientDB.Where(Qx(columnName.Qs("= ?"), "abc", "xyz")).Find(&[]*Example{}).Error
+			require.True(t, errors.Is(err, ErrPlaceholderMismatch))
+			err = lenientDB.Scopes(Qx("name = ? AND type = ?", "abc").AND(Qx("rank = 1", 2)).Scope()).Find(&[]*Example{}).Error
+			require.True(t, errors.Is(err, ErrPlaceholderMismatch))
+			require.NoError(t, lenientDB.Where(Qx(columnName.Eq("abc"))).Find(&[]*Example{}).Error)
+
+			require.Panics(t, func() {
+				WithValidationMode(db, ValidationStrict).Where(Qx(columnName.Qs("= ?"))).Find(&[]*Example{})
+			})
+		})
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		defer SetValidationMode(SetValidationMode(ValidationDisabled))
+
+		require.NoError(t, Qx(columnName.Qs("= ?")).AND(Qx(columnType.Eq("xyz"))).Validate())
+		require.NoError(t, checkTuples([]*statementArgumentsTuple{newStatementArgumentsTuple("name = ?", nil)}))
+
+		// The leaves are checked when applied in the mode of the DB, though the combinators checked nothing
+		// 虽然组合方法没有做任何校验，但在按 DB 的模式应用时叶子节点仍会被校验
+		qx := Qx("name = ? AND type = ?", "abc").AND(Qx("rank = 1", 2))
+		require.NoError(t, qx.err)
+		require.True(t, errors.Is(qx.renderIn("").check(ValidationLenient), ErrPlaceholderMismatch))
+	})
+
+	t.Run("default", func(t *testing.T) {
+		var mode ValidationMode
+		require.Equal(t, ValidationDisabled, mode)
+	})
+}
@@ -0,0 +1,132 @@
+// Package gormcnm provides named arguments support when merging statements with "@name" placeholders
+// Auto renames the colliding named arguments (same name with different values) in the merged parts, e.g. @name -> @name_2
+// Supports mixing with positional "?" arguments, ordering positional arguments first as GORM's NamedExpr expects
+//
+// gormcnm 提供合并带 "@name" 占位符语句时的命名参数支持
+// 自动重命名被合并各部分中冲突的命名参数（名称相同但值不同），例如 @name -> @name_2
+// 支持与 "?" 位置参数混用，按 GORM 的 NamedExpr 要求将位置参数排在前面
+package gormcnm
+
+import (
+	"database/sql"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gorm.io/gorm/clause"
+)
+
+// argumentsMerger merges the statements and arguments, renaming the colliding named arguments
+// argumentsMerger 合并语句和参数，并重命名冲突的命名参数
+type argumentsMerger struct {
+	positional []interface{}          // Positional arguments in order // 按顺序排列的位置参数
+	named      []sql.NamedArg         // Named arguments in order of appearance // 按出现顺序排列的命名参数
+	values     map[string]interface{} // Values of the taken names // 已占用名称对应的值
+}
+
+// newArgumentsMerger creates an empty argumentsMerger
+// newArgumentsMerger 创建空的 argumentsMerger
+func newArgumentsMerger() *argumentsMerger {
+	return &argumentsMerger{values: map[string]interface{}{}}
+}
+
+// merge adds the arguments of the statement, returning the statement with the colliding placeholders renamed
+// merge 添加语句的参数，返回重命名冲突占位符之后的语句
+func (m *argumentsMerger) merge(stmt string, args []interface{}) string {
+	var renames = map[string]string{}
+	for _, arg := range args {
+		switch value := arg.(type) {
+		case sql.NamedArg:
+			m.addNamed(value, renames)
+		case map[string]interface{}:
+			var names = make([]string, 0, len(value))
+			for name := range value {
+				names = append(names, name)
+			}
+			sort.Strings(names)
+			for _, name := range names {
+				m.addNamed(sql.Named(name, value[name]), renames)
+			}
+		default:
+			m.positional = append(m.positional, arg)
+		}
+	}
+	if len(renames) == 0 {
+		return stmt
+	}
+	return renamePlaceholders(stmt, renames)
+}
+
+// addNamed adds the named argument, renaming it when the name is taken by a different value
+// addNamed 添加命名参数，当名称已被不同的值占用时进行重命名
+func (m *argumentsMerger) addNamed(arg sql.NamedArg, renames map[string]string) {
+	if value, exists := m.values[arg.Name]; exists {
+		if reflect.DeepEqual(value, arg.Value) {
+			return
+		}
+		var name string
+		for idx := 2; ; idx++ {
+			if name = arg.Name + "_" + strconv.Itoa(idx); !m.hasName(name) {
+				break
+			}
+		}
+		renames[arg.Name] = name
+		arg = sql.Named(name, arg.Value)
+	}
+	m.values[arg.Name] = arg.Value
+	m.named = append(m.named, arg)
+}
+
+// hasName tells whether the name is taken
+// hasName 判断名称是否已被占用
+func (m *argumentsMerger) hasName(name string) bool {
+	_, exists := m.values[name]
+	return exists
+}
+
+// args returns the merged arguments, the positional ones first and then the named ones
+// args 返回合并后的参数，位置参数在前，命名参数在后
+func (m *argumentsMerger) args() []interface{} {
+	if len(m.named) == 0 {
+		return m.positional
+	}
+	var args = make([]interface{}, 0, len(m.positional)+len(m.named))
+	args = append(args, m.positional...)
+	for _, arg := range m.named {
+		args = append(args, arg)
+	}
+	return args
+}
+
+// renamePlaceholders renames the "@name" placeholders of the statement, leaving the quoted parts untouched
+// renamePlaceholders 重命名语句中的 "@name" 占位符，不修改引号内的部分
+func renamePlaceholders(stmt string, renames map[string]string) string {
+	var sb strings.Builder
+	var last int
+	walkPlaceholders(stmt, func(start, end int) {
+		if name, ok := renames[stmt[start+1:end]]; ok && stmt[start] == '@' {
+			sb.WriteString(stmt[last:start])
+			sb.WriteString("@" + name)
+			last = end
+		}
+	})
+	sb.WriteString(stmt[last:])
+	return sb.String()
+}
+
+// expression converts the tuple into a GORM clause.Expression, using clause.NamedExpr when there are "@name" placeholders
+// expression 将元组转换为 GORM 的 clause.Expression，存在 "@name" 占位符时使用 clause.NamedExpr
+func (qx *statementArgumentsTuple) expression() clause.Expression {
+	if qx.hasNamed() {
+		return clause.NamedExpr{SQL: qx.Qs(), Vars: qx.Args()}
+	}
+	return qx.expr()
+}
+
+// hasNamed tells whether the statement has "@name" placeholders
+// hasNamed 判断语句中是否存在 "@name" 占位符
+func (qx *statementArgumentsTuple) hasNamed() bool {
+	_, names := CountPlaceholders(qx.Qs())
+	return len(names) > 0
+}
@@ -0,0 +1,110 @@
+// Package gormcnm tests validate the named arguments support when merging statements
+// Auto verifies the renaming of colliding named arguments and the mixing with positional arguments
+// Tests examine SQLite execution and dry-run SQL with QxConjunction and SelectStatement
+//
+// gormcnm 测试包验证合并语句时的命名参数支持
+// 自动验证冲突命名参数的重命名以及与位置参数的混用
+// 测试涵盖 QxConjunction 和 SelectStatement 的 SQLite 执行和 dry-run SQL
+package gormcnm
+
+import (
+	"database/sql"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/yyle88/gormcnm/internal/tests"
+	"gorm.io/gorm"
+)
+
+func TestQxConjunction_NamedArgs(t *testing.T) {
+	type Example struct {
+		Name string `gorm:"primary_key;type:varchar(100);"`
+		Type string `gorm:"column:type;"`
+		Rank int    `gorm:"column:rank;"`
+	}
+
+	const (
+		columnType = ColumnName[string]("type")
+		columnRank = ColumnName[int]("rank")
+	)
+
+	t.Run("rename", func(t *testing.T) {
+		qx := Qx("name = @name", sql.Named("name", "abc")).
+			OR(Qx("name = @name", sql.Named("name", "aaa")), Qx("name = @name", map[string]interface{}{"name": "bbb"}))
+		require.Equal(t, "name = @name OR name = @name_2 OR name = @name_3", qx.Qs())
+		require.Equal(t, []interface{}{sql.Named("name", "abc"), sql.Named("name_2", "aaa"), sql.Named("name_3", "bbb")}, qx.Args())
+	})
+
+	t.Run("same-value", func(t *testing.T) {
+		qx := Qx("name = @name", sql.Named("name", "abc")).AND(Qx("remark <> @name", sql.Named("name", "abc")))
+		require.Equal(t, "name = @name AND remark <> @name", qx.Qs())
+		require.Equal(t, []interface{}{sql.Named("name", "abc")}, qx.Args())
+	})
+
+	t.Run("mixed", func(t *testing.T) {
+		qx := Qx("name = @name", sql.Named("name", "abc")).AND(Qx(columnRank.Gt(1)), Qx(columnType.Eq("xyz")))
+		require.Equal(t, "name = @name AND rank>? AND type=?", qx.Qs())
+		require.Equal(t, []interface{}{1, "xyz", sql.Named("name", "abc")}, qx.Args())
+	})
+
+	tests.NewDBRun(t, func(db *gorm.DB) {
+		require.NoError(t, db.AutoMigrate(&Example{}))
+		require.NoError(t, db.Create(&[]*Example{
+			{Name: "abc", Type: "xyz", Rank: 1},
+			{Name: "aaa", Type: "xxx", Rank: 2},
+			{Name: "bbb", Type: "xyz", Rank: 3},
+		}).Error)
+
+		selectNames := func(qx *QxConjunction) []string {
+			var names []string
+			require.NoError(t, db.Model(&Example{}).Where(qx).Order("name").Pluck("name", &names).Error)
+			return names
+		}
+
+		byName := func(name string) *QxConjunction {
+			return Qx("name = @name", sql.Named("name", name))
+		}
+		require.Equal(t, []string{"aaa", "abc"}, selectNames(byName("abc").OR(byName("aaa"))))
+		require.Equal(t, []string{"bbb"}, selectNames(byName("abc").OR(byName("bbb")).AND(Qx(columnRank.Gt(1)))))
+		require.Equal(t, []string{"abc"}, selectNames(Qx(columnType.Eq("xyz")).AND(byName("abc").OR(byName("aaa")))))
+
+		var names []string
+		require.NoError(t, db.Model(&Example{}).Scopes(byName("abc").OR(byName("bbb")).Scope()).Order("name").Pluck("name", &names).Error)
+		require.Equal(t, []string{"abc", "bbb"}, names)
+	})
+}
+
+func TestSelectStatement_NamedArgs(t *testing.T) {
+	type Example struct {
+		Name string `gorm:"primary_key;type:varchar(100);"`
+		Rank int    `gorm:"column:rank;"`
+	}
+
+	sx := NewSx("SUM(CASE WHEN rank > @v THEN 1 ELSE 0 END) as cnt_a", sql.Named("v", 1)).
+		Combine(NewSx("SUM(CASE WHEN rank > @v THEN 1 ELSE 0 END) as cnt_b", sql.Named("v", 2)), NewSx("COUNT(?) as cnt", 1))
+	require.Equal(t, "SUM(CASE WHEN rank > @v THEN 1 ELSE 0 END) as cnt_a, SUM(CASE WHEN rank > @v_2 THEN 1 ELSE 0 END) as cnt_b, COUNT(?) as cnt", sx.Qs())
+	require.Equal(t, []interface{}{1, sql.Named("v", 1), sql.Named("v_2", 2)}, sx.Args())
+
+	tests.NewDBRun(t, func(db *gorm.DB) {
+		require.NoError(t, db.AutoMigrate(&Example{}))
+		require.NoError(t, db.Create(&[]*Example{{Name: "abc", Rank: 1}, {Name: "aaa", Rank: 2}, {Name: "bbb", Rank: 3}}).Error)
+
+		type Result struct {
+			CntA int64
+			CntB int64
+			Cnt  int64
+		}
+		var res Result
+		require.NoError(t, db.Model(&Example{}).Clauses(sx).Take(&res).Error)
+		require.Equal(t, Result{CntA: 2, CntB: 1, Cnt: 3}, res)
+
+		var res2 Result
+		require.NoError(t, db.Model(&Example{}).Scopes(sx.Scope()).Take(&res2).Error)
+		require.Equal(t, res, res2)
+	})
+}
+
+func TestRenamePlaceholders(t *testing.T) {
+	stmt := renamePlaceholders("a = @name AND b = @name_2 AND c = '@name' AND d = @names", map[string]string{"name": "name_2", "name_2": "name_2_2"})
+	require.Equal(t, "a = @name_2 AND b = @name_2_2 AND c = '@name' AND d = @names", stmt)
+}
@@ -0,0 +1,103 @@
+// Package gormcnm provides typed SQL expressions carrying their own arguments
+// Auto keeps the statement and the bound arguments together, e.g. "COALESCE(MAX(name), ?)" with its default value
+// Supports comparing with typed values, selecting with an alias and ordering, merging the arguments in order
+//
+// gormcnm 提供携带自身参数的类型化 SQL 表达式
+// 自动将语句与绑定的参数放在一起，例如 "COALESCE(MAX(name), ?)" 及其默认值
+// 支持与类型化的值比较、带别名选择和排序，并按顺序合并参数
+package gormcnm
+
+import (
+	"github.com/yyle88/gormcnm/internal/utils"
+	"gorm.io/gorm/clause"
+)
+
+// TypedExpr represents a SQL expression giving a value of TYPE, with its arguments bound
+// Unlike ColumnName it carries arguments, thus it is used through the methods merging them in order
+//
+// TypedExpr 表示结果为 TYPE 类型值的 SQL 表达式，并绑定了其参数
+// 与 ColumnName 不同，它携带参数，因此需要通过按顺序合并参数的方法使用
+type TypedExpr[TYPE any] struct {
+	*statementArgumentsTuple // Embedded statement-arguments tuple // 嵌入的语句-参数元组
+}
+
+// NewTypedExpr creates a TypedExpr with the statement and arguments
+// NewTypedExpr 使用语句和参数创建 TypedExpr
+func NewTypedExpr[TYPE any](stmt string, args ...interface{}) *TypedExpr[TYPE] {
+	return &TypedExpr[TYPE]{
+		statementArgumentsTuple: newStatementArgumentsTuple(stmt, args),
+	}
+}
+
+// Expr returns the expression as clause.Expr, usable in UPDATE assignments, e.g. KeExp(tx.Expr())
+// Expr 返回 clause.Expr 形式的表达式，可用于 UPDATE 赋值，例如 KeExp(tx.Expr())
+func (tx *TypedExpr[TYPE]) Expr() clause.Expr {
+	return tx.expr()
+}
+
+// Build implements clause.Expression, writing the expression with its arguments bound
+// Build 实现 clause.Expression 接口，写入表达式并绑定其参数
+func (tx *TypedExpr[TYPE]) Build(builder clause.Builder) {
+	tx.expr().Build(builder)
+}
+
+// Sx returns a SelectStatement selecting the expression with the alias
+// Sx 返回以别名选择该表达式的 SelectStatement
+func (tx *TypedExpr[TYPE]) Sx(alias string) *SelectStatement {
+	return NewSelectStatement(utils.ApplyAliasToColumn(tx.stmt, alias), tx.args...)
+}
+
+// Qx creates a condition comparing the expression with the value using the op, e.g. Qx("> ?", 10)
+// Qx 使用运算符创建表达式与给定值比较的条件，例如 Qx("> ?", 10)
+func (tx *TypedExpr[TYPE]) Qx(op string, x TYPE) *QxConjunction {
+	var args = make([]interface{}, 0, len(tx.args)+1)
+	args = append(args, tx.args...)
+	args = append(args, x)
+	return NewQxConjunction(tx.stmt+" "+op, args...)
+}
+
+// Eq creates a condition checking the expression equals the value
+// Eq 创建判断表达式等于给定值的条件
+func (tx *TypedExpr[TYPE]) Eq(x TYPE) *QxConjunction {
+	return tx.Qx("= ?", x)
+}
+
+// Ne creates a condition checking the expression differs from the value
+// Ne 创建判断表达式不等于给定值的条件
+func (tx *TypedExpr[TYPE]) Ne(x TYPE) *QxConjunction {
+	return tx.Qx("!= ?", x)
+}
+
+// Gt creates a condition checking the expression is more than the value
+// Gt 创建判断表达式大于给定值的条件
+func (tx *TypedExpr[TYPE]) Gt(x TYPE) *QxConjunction {
+	return tx.Qx("> ?", x)
+}
+
+// Gte creates a condition checking the expression is at least the value
+// Gte 创建判断表达式大于等于给定值的条件
+func (tx *TypedExpr[TYPE]) Gte(x TYPE) *QxConjunction {
+	return tx.Qx(">= ?", x)
+}
+
+// Lt creates a condition checking the expression is less than the value
+// Lt 创建判断表达式小于给定值的条件
+func (tx *TypedExpr[TYPE]) Lt(x TYPE) *QxConjunction {
+	return tx.Qx("< ?", x)
+}
+
+// Lte creates a condition checking the expression is at most the value
+// Lte 创建判断表达式小于等于给定值的条件
+func (tx *TypedExpr[TYPE]) Lte(x TYPE) *QxConjunction {
+	return tx.Qx("<= ?", x)
+}
+
+// OrderBy returns the clause.OrderBy sorting by the expression, usable with db.Order
+// OrderBy 返回按表达式排序的 clause.OrderBy，可用于 db.Order
+func (tx *TypedExpr[TYPE]) OrderBy(desc bool) clause.OrderBy {
+	var stmt = tx.stmt
+	if desc {
+		stmt += " DESC"
+	}
+	return clause.OrderBy{Expression: clause.Expr{SQL: stmt, Vars: tx.args, WithoutParentheses: true}}
+}
@@ -0,0 +1,62 @@
+// Package gormcnm tests validate typed SQL expressions carrying their own arguments
+// Auto verifies comparisons, selecting with aliases and ordering merge the arguments in order
+// Tests examine SQLite execution with a scores table
+//
+// gormcnm 测试包验证携带自身参数的类型化 SQL 表达式
+// 自动验证比较、带别名选择和排序时按顺序合并参数
+// 测试涵盖使用 scores 表的 SQLite 执行
+package gormcnm
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/yyle88/gormcnm/internal/tests"
+	"gorm.io/gorm"
+)
+
+func TestTypedExpr(t *testing.T) {
+	type Score struct {
+		Name  string `gorm:"primary_key;type:varchar(100);"`
+		Bonus *int   `gorm:"column:bonus;"`
+	}
+
+	const (
+		columnName  = ColumnName[string]("name")
+		columnBonus = ColumnName[*int]("bonus")
+	)
+
+	var ten = 10
+	bonus := NewTypedExpr[int]("IFNULL(bonus, ?) + ?", 0, 5)
+
+	qx := bonus.Gt(7)
+	require.Equal(t, "IFNULL(bonus, ?) + ? > ?", qx.Qs())
+	require.Equal(t, []interface{}{0, 5, 7}, qx.Args())
+
+	tests.NewDBRun(t, func(db *gorm.DB) {
+		require.NoError(t, db.AutoMigrate(&Score{}))
+		require.NoError(t, db.Save(&Score{Name: "a", Bonus: &ten}).Error)
+		require.NoError(t, db.Save(&Score{Name: "b"}).Error)
+
+		var names []string
+		require.NoError(t, db.Model(&Score{}).Where(bonus.Gt(7)).Pluck(columnName.Name(), &names).Error)
+		require.Equal(t, []string{"a"}, names)
+
+		require.NoError(t, db.Model(&Score{}).Where(bonus.Lte(5).OR(bonus.Eq(15))).Order(bonus.OrderBy(true)).Pluck(columnName.Name(), &names).Error)
+		require.Equal(t, []string{"a", "b"}, names)
+
+		type Result struct {
+			Name  string
+			Total int
+		}
+		var results []*Result
+		require.NoError(t, db.Model(&Score{}).Clauses(NewSx(columnName.Name()).Combine(bonus.Sx("total"))).Order(columnName.Name()).Find(&results).Error)
+		require.Equal(t, 15, results[0].Total)
+		require.Equal(t, 5, results[1].Total)
+
+		require.NoError(t, db.Model(&Score{}).Where(columnName.Eq("b")).UpdateColumns(Kw(columnBonus.KeExp(bonus.Expr())).Map()).Error)
+		var one Score
+		require.NoError(t, db.Where(columnName.Eq("b")).First(&one).Error)
+		require.Equal(t, 5, *one.Bonus)
+	})
+}
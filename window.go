@@ -0,0 +1,170 @@
+// Package gormcnm provides window function building with PARTITION BY, ORDER BY and frame clauses
+// Auto spells "FUNC(...) OVER (PARTITION BY ... ORDER BY ... ROWS BETWEEN ...)" as a typed column
+// Supports ROW_NUMBER, RANK, DENSE_RANK, LAG/LEAD and running aggregates, usable in SELECT with an alias
+//
+// gormcnm 提供窗口函数构建，支持 PARTITION BY、ORDER BY 和窗口帧子句
+// 自动生成 "FUNC(...) OVER (PARTITION BY ... ORDER BY ... ROWS BETWEEN ...)" 形式的类型化列
+// 支持 ROW_NUMBER、RANK、DENSE_RANK、LAG/LEAD 和累计聚合，可带别名用于 SELECT
+package gormcnm
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/yyle88/gormcnm/internal/utils"
+)
+
+// FrameBound represents a bound of the window frame, e.g. "UNBOUNDED PRECEDING"
+// FrameBound 表示窗口帧的边界，例如 "UNBOUNDED PRECEDING"
+type FrameBound string
+
+const (
+	UnboundedPreceding FrameBound = "UNBOUNDED PRECEDING" // Frame starts at the first row of the partition // 窗口帧从分区第一行开始
+	CurrentRow         FrameBound = "CURRENT ROW"         // Frame bound at the current row // 窗口帧边界为当前行
+	UnboundedFollowing FrameBound = "UNBOUNDED FOLLOWING" // Frame ends at the last row of the partition // 窗口帧到分区最后一行结束
+)
+
+// Preceding returns the frame bound of n rows before the current row
+// Preceding 返回当前行之前 n 行的窗口帧边界
+func Preceding(n int) FrameBound {
+	return FrameBound(strconv.Itoa(n) + " PRECEDING")
+}
+
+// Following returns the frame bound of n rows after the current row
+// Following 返回当前行之后 n 行的窗口帧边界
+func Following(n int) FrameBound {
+	return FrameBound(strconv.Itoa(n) + " FOLLOWING")
+}
+
+// Window represents the window definition inside OVER (...)
+// It is immutable, each method returns a new Window
+//
+// Window 表示 OVER (...) 中的窗口定义
+// 它是不可变的，每个方法都返回新的 Window
+type Window struct {
+	partitions []string      // PARTITION BY columns // PARTITION BY 的列
+	orders     OrderByBottle // ORDER BY of the window // 窗口的 ORDER BY
+	frame      string        // Frame clause, e.g. "ROWS BETWEEN ..." // 窗口帧子句，例如 "ROWS BETWEEN ..."
+}
+
+// NewWindow creates an empty Window, rendering as OVER ()
+// NewWindow 创建一个空的 Window，渲染为 OVER ()
+func NewWindow() *Window {
+	return &Window{}
+}
+
+// PartitionBy returns a new Window partitioned by the columns
+// PartitionBy 返回按给定列分区的新 Window
+func (w *Window) PartitionBy(columns ...utils.ColumnNameInterface) *Window {
+	var partitions = make([]string, 0, len(columns))
+	for _, column := range columns {
+		partitions = append(partitions, column.Name())
+	}
+	return &Window{partitions: partitions, orders: w.orders, frame: w.frame}
+}
+
+// OrderBy returns a new Window ordered by the OrderByBottle, e.g. columnCreatedAt.Ob("DESC")
+// OrderBy 返回按 OrderByBottle 排序的新 Window，例如 columnCreatedAt.Ob("DESC")
+func (w *Window) OrderBy(orders OrderByBottle) *Window {
+	return &Window{partitions: w.partitions, orders: orders, frame: w.frame}
+}
+
+// Rows returns a new Window with the frame "ROWS BETWEEN start AND end"
+// Rows 返回窗口帧为 "ROWS BETWEEN start AND end" 的新 Window
+func (w *Window) Rows(start FrameBound, end FrameBound) *Window {
+	return &Window{partitions: w.partitions, orders: w.orders, frame: "ROWS BETWEEN " + string(start) + " AND " + string(end)}
+}
+
+// Range returns a new Window with the frame "RANGE BETWEEN start AND end"
+// Range 返回窗口帧为 "RANGE BETWEEN start AND end" 的新 Window
+func (w *Window) Range(start FrameBound, end FrameBound) *Window {
+	return &Window{partitions: w.partitions, orders: w.orders, frame: "RANGE BETWEEN " + string(start) + " AND " + string(end)}
+}
+
+// String returns the window definition without the surrounding OVER ()
+// String 返回不含外层 OVER () 的窗口定义
+func (w *Window) String() string {
+	var parts []string
+	if len(w.partitions) > 0 {
+		parts = append(parts, "PARTITION BY "+strings.Join(w.partitions, ", "))
+	}
+	if w.orders != "" {
+		parts = append(parts, "ORDER BY "+string(w.orders))
+	}
+	if w.frame != "" {
+		parts = append(parts, w.frame)
+	}
+	return strings.Join(parts, " ")
+}
+
+// WindowFunction represents a function call evaluated over a window, giving a result of RES
+// WindowFunction 表示在窗口上计算的函数调用，结果类型为 RES
+type WindowFunction[RES any] struct {
+	call string // Function call, e.g. "ROW_NUMBER()" // 函数调用，例如 "ROW_NUMBER()"
+}
+
+// NewWindowFunction creates a WindowFunction with the function call, e.g. NewWindowFunction[int64]("NTILE(4)")
+// NewWindowFunction 使用函数调用创建 WindowFunction，例如 NewWindowFunction[int64]("NTILE(4)")
+func NewWindowFunction[RES any](call string) *WindowFunction[RES] {
+	return &WindowFunction[RES]{call: call}
+}
+
+// Over returns the typed column "FUNC(...) OVER (window)", use AsAlias to select it with an alias
+// Over 返回类型化的列 "FUNC(...) OVER (window)"，使用 AsAlias 带别名选择
+func (wf *WindowFunction[RES]) Over(window *Window) ColumnName[RES] {
+	return ColumnName[RES](wf.call + " OVER (" + window.String() + ")")
+}
+
+// Sx returns a SelectStatement selecting the function over the window with the alias
+// Sx 返回以别名选择窗口函数的 SelectStatement
+func (wf *WindowFunction[RES]) Sx(window *Window, alias string) *SelectStatement {
+	return NewSelectStatement(wf.Over(window).AsAlias(alias))
+}
+
+// RowNumber returns ROW_NUMBER(), numbering the rows of each partition from 1
+// RowNumber 返回 ROW_NUMBER()，在每个分区内从 1 开始为行编号
+func RowNumber() *WindowFunction[int64] {
+	return NewWindowFunction[int64]("ROW_NUMBER()")
+}
+
+// Rank returns RANK(), the rank with gaps of the rows in each partition
+// Rank 返回 RANK()，每个分区内带间隔的排名
+func Rank() *WindowFunction[int64] {
+	return NewWindowFunction[int64]("RANK()")
+}
+
+// DenseRank returns DENSE_RANK(), the rank without gaps of the rows in each partition
+// DenseRank 返回 DENSE_RANK()，每个分区内无间隔的排名
+func DenseRank() *WindowFunction[int64] {
+	return NewWindowFunction[int64]("DENSE_RANK()")
+}
+
+// Lag returns LAG(column, offset), the column value of the row offset rows before
+// Lag 返回 LAG(column, offset)，即前 offset 行的列值
+func Lag[TYPE any](column ColumnName[TYPE], offset int) *WindowFunction[TYPE] {
+	return NewWindowFunction[TYPE]("LAG(" + column.Name() + ", " + strconv.Itoa(offset) + ")")
+}
+
+// Lead returns LEAD(column, offset), the column value of the row offset rows after
+// Lead 返回 LEAD(column, offset)，即后 offset 行的列值
+func Lead[TYPE any](column ColumnName[TYPE], offset int) *WindowFunction[TYPE] {
+	return NewWindowFunction[TYPE]("LEAD(" + column.Name() + ", " + strconv.Itoa(offset) + ")")
+}
+
+// WindowSum returns SUM(column) evaluated over a window, giving the running sum when the window is ordered
+// WindowSum 返回在窗口上计算的 SUM(column)，窗口有序时即为累计求和
+func WindowSum[TYPE any](column ColumnName[TYPE]) *WindowFunction[float64] {
+	return NewWindowFunction[float64]("SUM(" + column.Name() + ")")
+}
+
+// WindowAvg returns AVG(column) evaluated over a window, e.g. a moving average with Rows(Preceding(2), CurrentRow)
+// WindowAvg 返回在窗口上计算的 AVG(column)，例如配合 Rows(Preceding(2), CurrentRow) 计算移动平均
+func WindowAvg[TYPE any](column ColumnName[TYPE]) *WindowFunction[float64] {
+	return NewWindowFunction[float64]("AVG(" + column.Name() + ")")
+}
+
+// WindowCount returns COUNT(column) evaluated over a window
+// WindowCount 返回在窗口上计算的 COUNT(column)
+func WindowCount[TYPE any](column ColumnName[TYPE]) *WindowFunction[int64] {
+	return NewWindowFunction[int64]("COUNT(" + column.Name() + ")")
+}
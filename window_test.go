@@ -0,0 +1,82 @@
+// Package gormcnm tests validate window functions with PARTITION BY, ORDER BY and frame clauses
+// Auto verifies ROW_NUMBER, RANK, LAG/LEAD and running sums computed per partition
+// Tests examine SQLite execution with an orders table
+//
+// gormcnm 测试包验证带 PARTITION BY、ORDER BY 和窗口帧子句的窗口函数
+// 自动验证按分区计算的 ROW_NUMBER、RANK、LAG/LEAD 和累计求和
+// 测试涵盖使用 orders 表的 SQLite 执行
+package gormcnm
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/yyle88/gormcnm/internal/tests"
+	"github.com/yyle88/neatjson/neatjsons"
+	"gorm.io/gorm"
+)
+
+func TestWindowFunction_Over(t *testing.T) {
+	type Order struct {
+		ID     uint    `gorm:"primaryKey"`
+		UserID uint    `gorm:"column:user_id;"`
+		Amount float64 `gorm:"column:amount;"`
+	}
+
+	const (
+		columnID     = ColumnName[uint]("id")
+		columnUserID = ColumnName[uint]("user_id")
+		columnAmount = ColumnName[float64]("amount")
+	)
+
+	window := NewWindow().PartitionBy(columnUserID).OrderBy(columnID.Ob("ASC"))
+	require.Equal(t, "ROW_NUMBER() OVER (PARTITION BY user_id ORDER BY id ASC) as rn", RowNumber().Over(window).AsAlias("rn"))
+	require.Equal(t, "SUM(amount) OVER (PARTITION BY user_id ORDER BY id ASC ROWS BETWEEN UNBOUNDED PRECEDING AND CURRENT ROW)", WindowSum(columnAmount).Over(window.Rows(UnboundedPreceding, CurrentRow)).Name())
+	require.Equal(t, "RANK() OVER ()", Rank().Over(NewWindow()).Name())
+
+	tests.NewDBRun(t, func(db *gorm.DB) {
+		require.NoError(t, db.AutoMigrate(&Order{}))
+		require.NoError(t, db.Create(&[]*Order{
+			{ID: 1, UserID: 1, Amount: 10},
+			{ID: 2, UserID: 1, Amount: 20},
+			{ID: 3, UserID: 2, Amount: 30},
+			{ID: 4, UserID: 1, Amount: 40},
+			{ID: 5, UserID: 2, Amount: 30},
+		}).Error)
+
+		type Result struct {
+			ID        uint
+			Rn        int64
+			Rk        int64
+			PrevAmt   *float64
+			NextAmt   *float64
+			RunSum    float64
+			MovingAvg float64
+		}
+		sx := NewSx(columnID.Name()).Combine(
+			RowNumber().Sx(window, "rn"),
+			DenseRank().Sx(NewWindow().PartitionBy(columnUserID).OrderBy(columnAmount.Ob("DESC")), "rk"),
+			Lag(columnAmount, 1).Sx(window, "prev_amt"),
+			Lead(columnAmount, 1).Sx(window, "next_amt"),
+			WindowSum(columnAmount).Sx(window.Rows(UnboundedPreceding, CurrentRow), "run_sum"),
+			WindowAvg(columnAmount).Sx(window.Rows(Preceding(1), CurrentRow), "moving_avg"),
+		)
+		var results []*Result
+		require.NoError(t, db.Model(&Order{}).Clauses(sx).Order(columnID.Name()).Find(&results).Error)
+		t.Log(neatjsons.S(results))
+		require.Len(t, results, 5)
+
+		require.Equal(t, []int64{1, 2, 1, 3, 2}, []int64{results[0].Rn, results[1].Rn, results[2].Rn, results[3].Rn, results[4].Rn})
+		require.Equal(t, []int64{3, 2, 1, 1, 1}, []int64{results[0].Rk, results[1].Rk, results[2].Rk, results[3].Rk, results[4].Rk})
+		require.Nil(t, results[0].PrevAmt)
+		require.Equal(t, 10.0, *results[1].PrevAmt)
+		require.Equal(t, 40.0, *results[1].NextAmt)
+		require.Nil(t, results[3].NextAmt)
+		require.Equal(t, []float64{10, 30, 30, 70, 60}, []float64{results[0].RunSum, results[1].RunSum, results[2].RunSum, results[3].RunSum, results[4].RunSum})
+		require.Equal(t, 30.0, results[3].MovingAvg)
+
+		var ids []uint
+		require.NoError(t, db.Model(&Order{}).Order(RowNumber().Over(NewWindow().OrderBy(columnAmount.Ob("DESC"))).Ob("ASC").Ox()).Limit(1).Pluck(columnID.Name(), &ids).Error)
+		require.Equal(t, []uint{4}, ids)
+	})
+}